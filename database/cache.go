@@ -0,0 +1,71 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/dsa-uts/dsa-project/database/model"
+	"github.com/uptrace/bun"
+)
+
+type ResultCacheStore struct {
+	db *bun.DB
+}
+
+func NewResultCacheStore(db *bun.DB) *ResultCacheStore {
+	return &ResultCacheStore{
+		db: db,
+	}
+}
+
+// GetResultCache retrieves the cached result of the job with the given content hash,
+// if it was produced by the current sandbox images.
+func (rc *ResultCacheStore) GetResultCache(ctx context.Context, contentHash string) (*model.ResultCache, error) {
+	current := rc.db.NewSelect().Model((*model.SandboxImage)(nil)).
+		Column("digest").
+		Order("registered_at DESC").
+		Limit(1)
+
+	var result model.ResultCache
+	err := rc.db.NewSelect().Model(&result).
+		Where("content_hash = ?", contentHash).
+		Where("image_digest = (?)", current).
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// PutResultCache stores the result of a job, replacing the old one of the same content hash.
+func (rc *ResultCacheStore) PutResultCache(ctx context.Context, entry *model.ResultCache) error {
+	_, err := rc.db.NewInsert().Model(entry).
+		On("CONFLICT (content_hash) DO UPDATE").
+		Set("image_digest = EXCLUDED.image_digest").
+		Set("created_at = EXCLUDED.created_at").
+		Set("result = EXCLUDED.result").
+		Set("log = EXCLUDED.log").
+		Exec(ctx)
+	return err
+}
+
+// DeleteResultCacheOfProblem invalidates the cached results of the problem, e.g., when it is updated.
+func (rc *ResultCacheStore) DeleteResultCacheOfProblem(ctx context.Context, lectureID, problemID int64) error {
+	_, err := rc.db.NewDelete().Model(&model.ResultCache{}).
+		Where("lecture_id = ? AND problem_id = ?", lectureID, problemID).
+		Exec(ctx)
+	return err
+}
+
+// RegisterSandboxImage registers the digest of the sandbox images of a judge server.
+// A digest registered before keeps its time, so that restarting a judge server with old images
+// does not make them current again.
+func (rc *ResultCacheStore) RegisterSandboxImage(ctx context.Context, digest string) error {
+	_, err := rc.db.NewInsert().Model(&model.SandboxImage{
+		Digest:       digest,
+		RegisteredAt: time.Now(),
+	}).
+		On("CONFLICT (digest) DO NOTHING").
+		Exec(ctx)
+	return err
+}
@@ -2,6 +2,8 @@ package database
 
 import (
 	"context"
+	"database/sql"
+	"fmt"
 
 	"github.com/dsa-uts/dsa-project/database/model"
 	"github.com/uptrace/bun"
@@ -31,3 +33,46 @@ func (fs *FileStore) GetFileLocation(ctx context.Context, id int64) (*model.File
 	}
 	return &fileLocation, nil
 }
+
+// RegisterFileReference registers a file referenced from the description of a problem, e.g., an image.
+// fileLocation is registered together, and ref.LocationID is set to its ID.
+func (fs *FileStore) RegisterFileReference(ctx context.Context, ref *model.FileReference, fileLocation *model.FileLocation) error {
+	return fs.db.RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
+		if _, err := tx.NewInsert().Model(fileLocation).Returning("id").Exec(ctx); err != nil {
+			return fmt.Errorf("failed to insert file location: %w", err)
+		}
+		ref.LocationID = fileLocation.ID
+		if _, err := tx.NewInsert().Model(ref).Returning("id").Exec(ctx); err != nil {
+			return fmt.Errorf("failed to insert file reference: %w", err)
+		}
+		return nil
+	})
+}
+
+// GetFileReference retrieves a file reference with its location and problem.
+func (fs *FileStore) GetFileReference(ctx context.Context, id int64) (model.FileReference, error) {
+	var ref model.FileReference
+	err := fs.db.NewSelect().Model(&ref).
+		Relation("FileLocation").
+		Relation("Problem").
+		Where("file_reference.id = ?", id).
+		Scan(ctx)
+	if err != nil {
+		return model.FileReference{}, err
+	}
+	return ref, nil
+}
+
+// GetFileReferencesOfProblem retrieves all files referenced from the descriptions of any version of the problem.
+func (fs *FileStore) GetFileReferencesOfProblem(ctx context.Context, lectureID, problemID int64) ([]model.FileReference, error) {
+	var refs []model.FileReference
+	err := fs.db.NewSelect().Model(&refs).
+		Relation("FileLocation").
+		Where("file_reference.lecture_id = ? AND file_reference.problem_id = ?", lectureID, problemID).
+		Order("file_reference.id").
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return refs, nil
+}
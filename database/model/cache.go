@@ -0,0 +1,44 @@
+package model
+
+import (
+	"context"
+	"time"
+
+	"github.com/dsa-uts/dsa-project/database/model/requeststatus"
+	"github.com/uptrace/bun"
+)
+
+// ResultCache is the result of a finished job, reused for identical jobs.
+// ContentHash covers the submitted files, the resource files and the task definitions of the job,
+// and ImageDigest covers the sandbox images that ran the job.
+type ResultCache struct {
+	bun.BaseModel `bun:"table:resultcache"`
+
+	ContentHash string              `bun:"content_hash,pk" json:"content_hash"`
+	ImageDigest string              `bun:"image_digest,notnull" json:"image_digest"`
+	LectureID   int64               `bun:"lecture_id,notnull" json:"lecture_id"`
+	ProblemID   int64               `bun:"problem_id,notnull" json:"problem_id"`
+	CreatedAt   time.Time           `bun:"created_at,notnull" json:"created_at"`
+	ResultID    requeststatus.State `bun:"result,notnull" json:"result_id"`
+	Log         RequestLog          `bun:"log,notnull,type:jsonb" json:"log"`
+}
+
+// SandboxImage is a digest of the sandbox images of judge servers.
+// The one registered last is current, and only results cached with it are reused.
+type SandboxImage struct {
+	bun.BaseModel `bun:"table:sandboximage"`
+
+	Digest       string    `bun:"digest,pk" json:"digest"`
+	RegisteredAt time.Time `bun:"registered_at,notnull" json:"registered_at"` // when a judge server first ran with the images
+}
+
+var _ bun.BeforeAppendModelHook = (*ResultCache)(nil)
+
+func (rc *ResultCache) BeforeAppendModel(ctx context.Context, query bun.Query) error {
+	switch query.(type) {
+	case *bun.InsertQuery, *bun.UpdateQuery:
+		// remove fraction less than seconds (milliseconds, microseconds, ...)
+		rc.CreatedAt = rc.CreatedAt.Truncate(time.Second)
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package complexity
+
+import "slices"
+
+// Class is a time complexity class estimated by benchmark tasks.
+type Class string
+
+const (
+	Constant     Class = "1"
+	Logarithmic  Class = "logn"
+	Linear       Class = "n"
+	Linearithmic Class = "nlogn"
+	Quadratic    Class = "n2"
+	Cubic        Class = "n3"
+)
+
+// Classes lists all complexity classes in increasing order of growth.
+var Classes = []Class{Constant, Logarithmic, Linear, Linearithmic, Quadratic, Cubic}
+
+var classNames = map[Class]string{
+	Constant:     "O(1)",
+	Logarithmic:  "O(log n)",
+	Linear:       "O(n)",
+	Linearithmic: "O(n log n)",
+	Quadratic:    "O(n^2)",
+	Cubic:        "O(n^3)",
+}
+
+func (c Class) IsValid() bool {
+	return slices.Contains(Classes, c)
+}
+
+// String returns the class in big-O notation, e.g. "O(n log n)".
+func (c Class) String() string {
+	if name, ok := classNames[c]; ok {
+		return name
+	}
+	return string(c)
+}
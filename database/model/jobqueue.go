@@ -4,6 +4,7 @@ import (
 	"context"
 	"time"
 
+	"github.com/dsa-uts/dsa-project/database/model/leakpolicy"
 	"github.com/dsa-uts/dsa-project/database/model/queuestatus"
 	"github.com/dsa-uts/dsa-project/database/model/queuetype"
 	"github.com/dsa-uts/dsa-project/database/model/requeststatus"
@@ -22,7 +23,7 @@ type JobQueue struct {
 }
 
 type JobDetail struct {
-	TimeMS      int64      `json:"time_ms"`
+	TimeMS      int64      `json:"time_ms"` // limit of wall-clock time
 	MemoryMB    int64      `json:"memory_mb"`
 	TestFiles   []string   `json:"test_files"`
 	ResourceDir string     `json:"resource_dir"` // directory that contains resource files (e.g., stdin input for judge tasks)
@@ -30,6 +31,40 @@ type JobDetail struct {
 	ResultDir   string     `json:"result_dir"`   // directory that outputs will be stored
 	BuildTasks  []TestCase `json:"build"`
 	JudgeTasks  []TestCase `json:"judge"`
+
+	CPUTimeMS     int64             `json:"cpu_time_ms,omitempty"`     // limit of CPU time summed over threads, 0 means not limited
+	CPUCores      int64             `json:"cpu_cores,omitempty"`       // number of cores allocated exclusively to the job, 0 means 1
+	OutputLimitKB int64             `json:"output_limit_kb,omitempty"` // max size of stdout/stderr kept for each task, 0 means default
+	LeakPolicy    leakpolicy.Policy `json:"leak_policy,omitempty"`     // how memory leaks found in memcheck tasks affect the verdict
+	Repeat        int64             `json:"repeat,omitempty"`          // if positive, overrides the repeat count of every judge task
+
+	BenchmarkTasks []BenchmarkTask `json:"benchmark,omitempty"`
+	MutationTasks  []MutationTask  `json:"mutation,omitempty"`
+
+	// If true, missing expected outputs of judge tasks are generated into ResourceDir
+	// from the outputs of the submitted program, which should be the reference solution.
+	GenerateOutputs bool `json:"generate_outputs,omitempty"`
+
+	// Hash of the contents of the job, used to cache its result. Empty if the result is not cached.
+	CacheKey string `json:"cache_key,omitempty"`
+}
+
+// MakeJobDetail creates a job running the given tasks of the problem.
+func MakeJobDetail(detail Detail, resourceDir, fileDir, resultDir string, buildTasks, judgeTasks []TestCase) JobDetail {
+	return JobDetail{
+		TimeMS:        detail.TimeMS,
+		MemoryMB:      detail.MemoryMB,
+		TestFiles:     detail.TestFiles,
+		ResourceDir:   resourceDir,
+		FileDir:       fileDir,
+		ResultDir:     resultDir,
+		BuildTasks:    buildTasks,
+		JudgeTasks:    judgeTasks,
+		CPUTimeMS:     detail.CPUTimeMS,
+		CPUCores:      detail.CPUCores,
+		OutputLimitKB: detail.OutputLimitKB,
+		LeakPolicy:    detail.LeakPolicy,
+	}
 }
 
 type ResultQueue struct {
@@ -0,0 +1,60 @@
+package latepolicy
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Kind is how the score of a submission after the deadline is reduced.
+type Kind string
+
+const (
+	Hard    Kind = "hard"    // submissions after the deadline get no score
+	Linear  Kind = "linear"  // the penalty grows in proportion to the time after the deadline
+	Stepped Kind = "stepped" // the penalty grows by a step for each started day after the deadline
+)
+
+// Policy is the late-submission policy of a lecture or a problem.
+// Submissions within the grace period after the deadline are not penalized with any kind,
+// and the time after the deadline is counted from the end of the grace period.
+type Policy struct {
+	Kind          Kind    `json:"kind"`
+	GraceMinutes  int64   `json:"grace_minutes,omitempty"`
+	PenaltyPerDay float64 `json:"penalty_per_day,omitempty"` // fraction of the score deducted per day, e.g., 0.1 (linear and stepped)
+}
+
+// Validate reports the first invalid setting of the policy, if any.
+func (p Policy) Validate() error {
+	switch p.Kind {
+	case Hard:
+	case Linear, Stepped:
+		if p.PenaltyPerDay <= 0 || p.PenaltyPerDay > 1 {
+			return fmt.Errorf("penalty_per_day must be greater than 0 and at most 1")
+		}
+	default:
+		return fmt.Errorf("kind must be %q, %q or %q: %s", Hard, Linear, Stepped, p.Kind)
+	}
+	if p.GraceMinutes < 0 {
+		return fmt.Errorf("grace_minutes must not be negative")
+	}
+	return nil
+}
+
+// Penalty returns the fraction of the score deducted from a submission at submittedAt, between 0 and 1.
+func (p Policy) Penalty(deadline, submittedAt time.Time) float64 {
+	late := submittedAt.Sub(deadline) - time.Duration(p.GraceMinutes)*time.Minute
+	if late <= 0 {
+		return 0
+	}
+	days := late.Hours() / 24
+	switch p.Kind {
+	case Hard:
+		return 1
+	case Linear:
+		return min(p.PenaltyPerDay*days, 1)
+	case Stepped:
+		return min(p.PenaltyPerDay*math.Ceil(days), 1)
+	}
+	return 0
+}
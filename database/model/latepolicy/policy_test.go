@@ -0,0 +1,84 @@
+package latepolicy
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestPenalty(t *testing.T) {
+	deadline := time.Date(2025, 4, 1, 23, 59, 0, 0, time.UTC)
+	day := 24 * time.Hour
+
+	hard := Policy{Kind: Hard}
+	hardWithGrace := Policy{Kind: Hard, GraceMinutes: 30}
+	linear := Policy{Kind: Linear, PenaltyPerDay: 0.1}
+	linearWithGrace := Policy{Kind: Linear, GraceMinutes: 60, PenaltyPerDay: 0.1}
+	stepped := Policy{Kind: Stepped, PenaltyPerDay: 0.1}
+	steppedWithGrace := Policy{Kind: Stepped, GraceMinutes: 60, PenaltyPerDay: 0.25}
+
+	tests := []struct {
+		name   string
+		policy Policy
+		late   time.Duration // time of the submission after the deadline
+		want   float64
+	}{
+		{"hard before the deadline", hard, -time.Minute, 0},
+		{"hard at the deadline", hard, 0, 0},
+		{"hard after the deadline", hard, time.Second, 1},
+		{"hard at the end of the grace period", hardWithGrace, 30 * time.Minute, 0},
+		{"hard after the grace period", hardWithGrace, 30*time.Minute + time.Second, 1},
+		{"linear at the deadline", linear, 0, 0},
+		{"linear half a day", linear, day / 2, 0.05},
+		{"linear a day", linear, day, 0.1},
+		{"linear three days", linear, 3 * day, 0.3},
+		{"linear capped", linear, 15 * day, 1},
+		{"linear at the end of the grace period", linearWithGrace, time.Hour, 0},
+		{"linear counted from the end of the grace period", linearWithGrace, time.Hour + day, 0.1},
+		{"stepped right after the deadline", stepped, time.Second, 0.1},
+		{"stepped a day", stepped, day, 0.1},
+		{"stepped just over a day", stepped, day + time.Second, 0.2},
+		{"stepped capped", stepped, 20 * day, 1},
+		{"stepped at the end of the grace period", steppedWithGrace, time.Hour, 0},
+		{"stepped after the grace period", steppedWithGrace, time.Hour + time.Second, 0.25},
+		{"stepped capped exactly", steppedWithGrace, time.Hour + 4*day, 1},
+		{"stepped capped after the grace period", steppedWithGrace, time.Hour + 4*day + time.Second, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.policy.Penalty(deadline, deadline.Add(tt.late))
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("Penalty() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  Policy
+		wantErr bool
+	}{
+		{"hard", Policy{Kind: Hard}, false},
+		{"hard with grace", Policy{Kind: Hard, GraceMinutes: 10}, false},
+		{"linear", Policy{Kind: Linear, PenaltyPerDay: 0.1}, false},
+		{"stepped with full penalty", Policy{Kind: Stepped, PenaltyPerDay: 1}, false},
+		{"linear without penalty", Policy{Kind: Linear}, true},
+		{"stepped with negative penalty", Policy{Kind: Stepped, PenaltyPerDay: -0.1}, true},
+		{"linear with penalty over 1", Policy{Kind: Linear, PenaltyPerDay: 1.5}, true},
+		{"negative grace", Policy{Kind: Hard, GraceMinutes: -1}, true},
+		{"unknown kind", Policy{Kind: "exponential", PenaltyPerDay: 0.1}, true},
+		{"empty kind", Policy{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
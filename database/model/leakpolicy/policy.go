@@ -0,0 +1,20 @@
+package leakpolicy
+
+// Policy is how memory leaks found by Valgrind memcheck affect the verdict of a task.
+// Invalid memory accesses always fail the task regardless of the policy.
+type Policy string
+
+const (
+	Fail Policy = "fail" // memory leaks fail the task (default)
+	Warn Policy = "warn" // memory leaks are reported, but do not affect the verdict
+)
+
+func (p Policy) IsValid() bool {
+	return p == Fail || p == Warn
+}
+
+// FailsOnLeak reports whether memory leaks fail the task.
+// An empty policy is treated as Fail.
+func (p Policy) FailsOnLeak() bool {
+	return p != Warn
+}
@@ -4,6 +4,10 @@ import (
 	"context"
 	"time"
 
+	"github.com/dsa-uts/dsa-project/database/model/complexity"
+	"github.com/dsa-uts/dsa-project/database/model/latepolicy"
+	"github.com/dsa-uts/dsa-project/database/model/leakpolicy"
+	"github.com/dsa-uts/dsa-project/database/model/problemstatus"
 	"github.com/uptrace/bun"
 )
 
@@ -15,6 +19,9 @@ type Lecture struct {
 	StartDate time.Time `bun:"start_date,notnull" json:"start_date"`
 	Deadline  time.Time `bun:"deadline,notnull" json:"deadline"`
 
+	// Late-submission policy of the problems, nil if late submissions are not penalized
+	LatePolicy *latepolicy.Policy `bun:"late_policy,type:jsonb" json:"late_policy,omitempty"`
+
 	Problems []*Problem `bun:"rel:has-many,join:id=lecture_id" json:"problems,omitempty"`
 }
 
@@ -27,16 +34,130 @@ type Problem struct {
 	Title              string    `bun:"title,notnull" json:"title"`
 	ResourceLocationID int64     `bun:"resource_location_id,notnull" json:"resource_location_id"`
 	Detail             Detail    `bun:"detail,notnull,type:jsonb" json:"detail"`
+
+	Status  problemstatus.Status `bun:"status,notnull,default:'unverified'" json:"status"`
+	Version int64                `bun:"version,notnull,default:1" json:"version"` // current version, copied from ProblemVersion
+
+	// Deadline and late-submission policy of the problem, nil for the ones of the lecture.
+	// They are not versioned, and kept when a new version is uploaded.
+	Deadline   *time.Time         `bun:"deadline" json:"deadline,omitempty"`
+	LatePolicy *latepolicy.Policy `bun:"late_policy,type:jsonb" json:"late_policy,omitempty"`
+}
+
+// IsVisible reports whether students can see the problem.
+// Problems with a reference solution are hidden until it passes all tasks.
+// New versions of a visible problem do not replace it until they pass, see ProblemStore.AddProblemVersion.
+func (p *Problem) IsVisible() bool {
+	return p.Status.IsVisible()
+}
+
+// EffectiveDeadline returns the deadline of the problem, which defaults to the one of the lecture.
+func (p *Problem) EffectiveDeadline(lecture *Lecture) time.Time {
+	if p.Deadline != nil {
+		return *p.Deadline
+	}
+	return lecture.Deadline
+}
+
+// EffectiveLatePolicy returns the late-submission policy of the problem, which defaults to the one of the lecture.
+// nil means late submissions are not penalized.
+func (p *Problem) EffectiveLatePolicy(lecture *Lecture) *latepolicy.Policy {
+	if p.LatePolicy != nil {
+		return p.LatePolicy
+	}
+	return lecture.LatePolicy
+}
+
+// DeadlineExtension is an individual extension of deadlines for a student,
+// e.g., for approved accommodations or medical leave.
+type DeadlineExtension struct {
+	bun.BaseModel `bun:"table:deadlineextension"`
+
+	ID              int64     `bun:"id,pk,autoincrement" json:"id"`
+	UserCode        int64     `bun:"usercode,notnull" json:"usercode"`
+	LectureID       int64     `bun:"lecture_id,notnull" json:"lecture_id"`
+	ProblemID       *int64    `bun:"problem_id" json:"problem_id"` // nil for all problems of the lecture
+	Deadline        time.Time `bun:"deadline,notnull" json:"deadline"`
+	Reason          string    `bun:"reason,notnull" json:"reason"`
+	GrantedUserCode int64     `bun:"granted_usercode,notnull" json:"granted_usercode"`
+	GrantedAt       time.Time `bun:"granted_at,notnull" json:"granted_at"`
+
+	User        *UserList `bun:"rel:belongs-to,join:usercode=id"`
+	GrantedUser *UserList `bun:"rel:belongs-to,join:granted_usercode=id"`
+}
+
+// Covers reports whether the extension applies to the problem.
+func (e *DeadlineExtension) Covers(lectureID, problemID int64) bool {
+	return e.LectureID == lectureID && (e.ProblemID == nil || *e.ProblemID == problemID)
+}
+
+// ExtendDeadline returns the deadline of the problem for a student with the given extensions.
+// Extensions never bring the deadline forward, and the latest one applies if several cover the problem.
+func ExtendDeadline(deadline time.Time, lectureID, problemID int64, extensions []DeadlineExtension) time.Time {
+	for _, extension := range extensions {
+		if extension.Covers(lectureID, problemID) && extension.Deadline.After(deadline) {
+			deadline = extension.Deadline
+		}
+	}
+	return deadline
+}
+
+// ProblemVersion is an uploaded revision of a problem.
+// Requests record the version they were judged with, so that their results are shown
+// with the tasks and the resource files they ran against.
+type ProblemVersion struct {
+	bun.BaseModel `bun:"table:problemversion"`
+
+	LectureID          int64                `bun:"lecture_id,pk,notnull" json:"lecture_id"`
+	ProblemID          int64                `bun:"problem_id,pk,notnull" json:"problem_id"`
+	Version            int64                `bun:"version,pk,notnull" json:"version"`
+	RegisteredAt       time.Time            `bun:"registered_at,notnull" json:"registered_at"`
+	Title              string               `bun:"title,notnull" json:"title"`
+	ResourceLocationID int64                `bun:"resource_location_id,notnull" json:"resource_location_id"`
+	Detail             Detail               `bun:"detail,notnull,type:jsonb" json:"detail"`
+	Status             problemstatus.Status `bun:"status,notnull,default:'unverified'" json:"status"`
+
+	// Hash of the resource files for cache keys of jobs, computed once they no longer change.
+	// Empty until then, e.g., while expected outputs may still be generated by the verification.
+	ResourceDigest string `bun:"resource_digest,nullzero" json:"-"`
+
+	ResourceLocation *FileLocation `bun:"rel:belongs-to,join:resource_location_id=id" json:"-"`
+}
+
+// MakeVersion returns the version holding the current contents of the problem.
+func (p *Problem) MakeVersion() ProblemVersion {
+	return ProblemVersion{
+		LectureID:          p.LectureID,
+		ProblemID:          p.ProblemID,
+		Version:            p.Version,
+		RegisteredAt:       p.RegisteredAt,
+		Title:              p.Title,
+		ResourceLocationID: p.ResourceLocationID,
+		Detail:             p.Detail,
+		Status:             p.Status,
+	}
 }
 
 type Detail struct {
 	DescriptionPath string     `json:"description_path"`
-	TimeMS          int64      `json:"time_ms"`
+	TimeMS          int64      `json:"time_ms"` // limit of wall-clock time
 	MemoryMB        int64      `json:"memory_mb"`
 	TestFiles       []string   `json:"test_files"`
 	RequiredFiles   []string   `json:"required_files"`
 	BuildTasks      []TestCase `json:"build"`
 	JudgeTasks      []TestCase `json:"judge"`
+
+	CPUTimeMS      int64 `json:"cpu_time_ms,omitempty"`      // limit of CPU time summed over threads, 0 means not limited
+	CPUCores       int64 `json:"cpu_cores,omitempty"`        // number of cores allocated exclusively to the job, 0 means 1
+	OutputLimitKB  int64 `json:"output_limit_kb,omitempty"`  // max size of stdout/stderr kept for each task, 0 means default
+	DisplayLimitKB int64 `json:"display_limit_kb,omitempty"` // max size of stdout/stderr shown in results, 0 means default
+
+	BenchmarkTasks []BenchmarkTask `json:"benchmark,omitempty"` // run only for grading requests
+	MutationTasks  []MutationTask  `json:"mutation,omitempty"`  // run only for grading requests
+
+	ReferenceSolutionPath string `json:"reference_solution,omitempty"` // directory of the reference solution in resource files
+
+	LeakPolicy leakpolicy.Policy `json:"leak_policy,omitempty"` // how memory leaks found in memcheck tasks affect the verdict
 }
 
 type TestCase struct {
@@ -50,6 +171,28 @@ type TestCase struct {
 	StderrPath  string `json:"stderr"`
 	ExitCode    int64  `json:"exit"`
 	IgnoreExit  bool   `json:"ignore_exit"`
+
+	// Hint shown with a failed result, which may contain placeholders such as {exit_code}
+	MessageOnFail string `json:"message_on_fail,omitempty"`
+
+	Args         []string          `json:"args,omitempty"`     // arguments appended to Command
+	Env          map[string]string `json:"env,omitempty"`      // environment variables of the command
+	FixturesPath string            `json:"fixtures,omitempty"` // directory copied into a fresh working directory (judge tasks only)
+	Repeat       int64             `json:"repeat,omitempty"`   // number of times the judge task is run, 0 means once
+	Memcheck     bool              `json:"memcheck,omitempty"` // run under Valgrind memcheck (judge tasks only)
+
+	// Processes started in order before Command, which then checks their results (judge tasks only)
+	Processes []Process `json:"processes,omitempty"`
+}
+
+// Process is one of the processes of a multi-process judge task, e.g., a server and its clients.
+// The processes run at the same time in the judge container, where only loopback networking is available.
+type Process struct {
+	Name       string `json:"name"` // unique in the task, names the result files of the process
+	Command    string `json:"command"`
+	StdinPath  string `json:"stdin,omitempty"`
+	Ready      string `json:"ready,omitempty"`      // polled until it exits with 0 before the next process is started
+	Background bool   `json:"background,omitempty"` // killed once all other processes have exited, instead of being waited for
 }
 
 func MakeTestCase(id int64, title, description, command string, evalOnly bool, stdinPath, stdoutPath, stderrPath string, exitCode int64, ignoreExit bool) TestCase {
@@ -89,3 +232,29 @@ func (p *Problem) BeforeAppendModel(ctx context.Context, query bun.Query) error
 	}
 	return nil
 }
+
+// BenchmarkTask runs the program on generated inputs of increasing size,
+// and estimates its time complexity from the execution times.
+type BenchmarkTask struct {
+	ID          int64              `json:"id"`
+	Title       string             `json:"title"`
+	Description string             `json:"description"`
+	Command     string             `json:"command"`            // program to be measured, reads the generated input from stdin
+	Generator   string             `json:"generator"`          // writes an input of size N to stdout, N is appended as the last argument
+	Sizes       []int64            `json:"sizes"`              // input sizes in increasing order
+	TimeMS      int64              `json:"time_ms"`            // time limit of each run
+	Expected    []complexity.Class `json:"expected,omitempty"` // accepted complexity classes, any class is accepted if empty
+}
+
+// MutationTask runs the test suite written by the student against the reference implementation
+// and its mutants supplied by the problem author. The test suite should fail on every mutant.
+type MutationTask struct {
+	ID            int64    `json:"id"`
+	Title         string   `json:"title"`
+	Description   string   `json:"description"`
+	Command       string   `json:"command"`             // builds and runs the test suite, exit code 0 means all tests passed
+	ReferencePath string   `json:"reference"`           // directory of the reference implementation in resource files
+	MutantPaths   []string `json:"mutants"`             // directories of the mutants in resource files
+	TimeMS        int64    `json:"time_ms"`             // time limit of each run
+	MinScore      float64  `json:"min_score,omitempty"` // fraction of mutants to be killed, any score is accepted if 0
+}
@@ -0,0 +1,18 @@
+package problemstatus
+
+type Status string
+
+const (
+	Unverified Status = "unverified" // no reference solution is provided
+	Verifying  Status = "verifying"  // the reference solution is being judged
+	Verified   Status = "verified"   // the reference solution passed all tasks
+	Broken     Status = "broken"     // the reference solution failed some tasks
+	Generating Status = "generating" // expected outputs are being generated from the reference solution
+	Review     Status = "review"     // expected outputs were generated, and wait for review before publishing
+)
+
+// IsVisible reports whether students can see the problem.
+// Problems with a reference solution are hidden until it passes all tasks.
+func (s Status) IsVisible() bool {
+	return s == Unverified || s == Verified
+}
@@ -5,4 +5,7 @@ type Type string
 const (
 	Validation Type = "validation"
 	Grading    Type = "grading"
+
+	// Verification runs the reference solution of a problem when it is registered
+	Verification Type = "verification"
 )
@@ -2,8 +2,10 @@ package model
 
 import (
 	"context"
+	"slices"
 	"time"
 
+	"github.com/dsa-uts/dsa-project/database/model/complexity"
 	"github.com/dsa-uts/dsa-project/database/model/requeststatus"
 	"github.com/uptrace/bun"
 )
@@ -18,14 +20,15 @@ type ResultValues struct {
 type ValidationRequest struct {
 	bun.BaseModel `bun:"table:validationrequest"`
 
-	ID          int64               `bun:"id,pk,autoincrement" json:"id"`
-	TS          time.Time           `bun:"ts,notnull" json:"ts"`
-	UserCode    int64               `bun:"usercode,notnull" json:"usercode"`
-	LectureID   int64               `bun:"lecture_id,notnull" json:"lecture_id"`
-	ProblemID   int64               `bun:"problem_id,notnull" json:"problem"`
-	UploadDirID int64               `bun:"upload_dir_id,notnull" json:"upload_dir_id"`
-	ResultID    requeststatus.State `bun:"result,notnull" json:"result_id"`
-	Log         RequestLog          `bun:"log,notnull,type:jsonb" json:"log"`
+	ID             int64               `bun:"id,pk,autoincrement" json:"id"`
+	TS             time.Time           `bun:"ts,notnull" json:"ts"`
+	UserCode       int64               `bun:"usercode,notnull" json:"usercode"`
+	LectureID      int64               `bun:"lecture_id,notnull" json:"lecture_id"`
+	ProblemID      int64               `bun:"problem_id,notnull" json:"problem"`
+	ProblemVersion int64               `bun:"problem_version,notnull,default:1" json:"problem_version"` // version of the problem judged with
+	UploadDirID    int64               `bun:"upload_dir_id,notnull" json:"upload_dir_id"`
+	ResultID       requeststatus.State `bun:"result,notnull" json:"result_id"`
+	Log            RequestLog          `bun:"log,notnull,type:jsonb" json:"log"`
 
 	Problem      *Problem      `bun:"rel:belongs-to,join:lecture_id=lecture_id,join:problem_id=problem_id"`
 	Result       *ResultValues `bun:"rel:has-one,join:result=value"`
@@ -43,6 +46,7 @@ type GradingRequest struct {
 	ID              int64               `bun:"id,unique,autoincrement,notnull" json:"id"`
 	TS              time.Time           `bun:"ts,notnull" json:"ts"`
 	RequestUserCode int64               `bun:"request_usercode,notnull" json:"request_usercode"`
+	ProblemVersion  int64               `bun:"problem_version,notnull,default:1" json:"problem_version"` // version of the problem judged with
 	UploadDirID     int64               `bun:"upload_dir_id,notnull" json:"upload_dir_id"`
 	ResultID        requeststatus.State `bun:"result,notnull" json:"result_id"`
 	Log             RequestLog          `bun:"log,notnull,type:jsonb" json:"log"`
@@ -55,22 +59,317 @@ type GradingRequest struct {
 	RequestUser *UserList `bun:"rel:belongs-to,join:request_usercode=id"`
 }
 
+// ProblemVerification is a run of the reference solution of a problem through all its tasks.
+type ProblemVerification struct {
+	bun.BaseModel `bun:"table:problemverification"`
+
+	ID             int64               `bun:"id,pk,autoincrement" json:"id"`
+	LectureID      int64               `bun:"lecture_id,notnull" json:"lecture_id"`
+	ProblemID      int64               `bun:"problem_id,notnull" json:"problem_id"`
+	ProblemVersion int64               `bun:"problem_version,notnull,default:1" json:"problem_version"` // version of the problem verified
+	TS             time.Time           `bun:"ts,notnull" json:"ts"`
+	ResultID       requeststatus.State `bun:"result,notnull" json:"result_id"`
+	Log            RequestLog          `bun:"log,notnull,type:jsonb" json:"log"`
+
+	Problem *Problem      `bun:"rel:belongs-to,join:lecture_id=lecture_id,join:problem_id=problem_id"`
+	Result  *ResultValues `bun:"rel:has-one,join:result=value"`
+}
+
+// Rejudge is a rerun of existing grading requests, e.g., after test data of a problem are fixed.
+type Rejudge struct {
+	bun.BaseModel `bun:"table:rejudge"`
+
+	ID              int64                 `bun:"id,pk,autoincrement" json:"id"`
+	TS              time.Time             `bun:"ts,notnull" json:"ts"`
+	RequestUserCode int64                 `bun:"request_usercode,notnull" json:"request_usercode"`
+	LectureID       int64                 `bun:"lecture_id,notnull" json:"lecture_id"`
+	ProblemID       *int64                `bun:"problem_id" json:"problem_id"`              // nil for all problems of the lecture
+	RequestID       *int64                `bun:"request_id" json:"request_id"`              // nil for all grading requests of the problems
+	Results         []requeststatus.State `bun:"results,notnull,type:jsonb" json:"results"` // verdicts to rejudge, empty for all
+	Total           int64                 `bun:"total,notnull" json:"total"`                // number of grading requests queued to be rejudged
+
+	RequestUser *UserList `bun:"rel:belongs-to,join:request_usercode=id"`
+}
+
+// GradingRequestHistory is a result of a grading request, replaced by a rejudge.
+type GradingRequestHistory struct {
+	bun.BaseModel `bun:"table:gradingrequesthistory"`
+
+	ID             int64               `bun:"id,pk,autoincrement" json:"id"`
+	RequestID      int64               `bun:"request_id,notnull" json:"request_id"`
+	RejudgeID      int64               `bun:"rejudge_id,notnull" json:"rejudge_id"`
+	ArchivedAt     time.Time           `bun:"archived_at,notnull" json:"archived_at"`
+	ProblemVersion int64               `bun:"problem_version,notnull" json:"problem_version"`
+	ResultID       requeststatus.State `bun:"result,notnull" json:"result_id"`
+	Log            RequestLog          `bun:"log,notnull,type:jsonb" json:"log"`
+
+	Request *GradingRequest `bun:"rel:belongs-to,join:request_id=id"`
+}
+
 type RequestLog struct {
 	ResultID     requeststatus.State `json:"result_id"`
 	TimeMS       int64               `json:"time_ms"`
+	CPUTimeMS    int64               `json:"cpu_time_ms"`
 	MemoryKB     int64               `json:"memory_kb"`
 	BuildResults []TaskLog           `json:"build_results"`
 	JudgeResults []TaskLog           `json:"judge_results"`
+	Flaky        bool                `json:"flaky"`                  // some judge task got different verdicts across repeated runs
+	Cached       bool                `json:"cached,omitempty"`       // reused from the result of an identical job
+	ImageDigest  string              `json:"image_digest,omitempty"` // digest of the sandbox images that ran the job
+
+	BenchmarkResults []BenchmarkLog `json:"benchmark_results,omitempty"`
+	MutationResults  []MutationLog  `json:"mutation_results,omitempty"`
+}
+
+// BenchmarkLog is the result of a benchmark task.
+type BenchmarkLog struct {
+	TaskID         int64               `json:"task_id"`
+	ResultID       requeststatus.State `json:"result_id"` // worst verdict of the runs, or WA if the estimated class is not accepted
+	Points         []BenchmarkPoint    `json:"points"`
+	EstimatedClass complexity.Class    `json:"estimated_class"` // empty if there are not enough successful runs
+	Fits           []ComplexityFit     `json:"fits"`
+	Error          string              `json:"error,omitempty"` // why the benchmark is IE, e.g., the generator failed
+}
+
+// BenchmarkPoint is a single run of a benchmark task on an input of size N.
+type BenchmarkPoint struct {
+	N        int64               `json:"n"`
+	ResultID requeststatus.State `json:"result_id"`
+	TimeMS   int64               `json:"timeMS"`
+	MemoryKB int64               `json:"memoryKB"`
+}
+
+// ComplexityFit is how well the execution times fit a complexity class,
+// by least squares of time = a * f(N) + b.
+type ComplexityFit struct {
+	Class    complexity.Class `json:"class"`
+	Residual float64          `json:"residual"` // root mean square error divided by the mean time, smaller is better
+}
+
+// SetBenchmarkResults stores the results of benchmark tasks,
+// and updates the overall result with their verdicts.
+// Benchmarks are IE only if their generators fail, which are faults of the problem, not of the submission,
+// so they do not affect the overall result. See BenchmarkFailed.
+// Time and memory of benchmark runs are not included in those of the request,
+// because they are measured on inputs much larger than those of judge tasks.
+func (rl *RequestLog) SetBenchmarkResults(benchmarkLogs []BenchmarkLog) {
+	rl.BenchmarkResults = benchmarkLogs
+
+	for _, log := range benchmarkLogs {
+		if log.ResultID == requeststatus.IE {
+			continue
+		}
+		rl.ResultID = rl.ResultID.Max(log.ResultID)
+	}
+}
+
+// BenchmarkFailed reports whether some benchmark could not be run, e.g., its generator failed.
+func (rl *RequestLog) BenchmarkFailed() bool {
+	for _, log := range rl.BenchmarkResults {
+		if log.ResultID == requeststatus.IE {
+			return true
+		}
+	}
+	return false
+}
+
+// TimingDependent reports whether the result may differ if the job is run again,
+// because some task exceeded a time limit, got different verdicts across runs,
+// or some benchmark did not estimate the accepted complexity class, which all depend on the load of the machine.
+// Such results are not cached.
+func (rl *RequestLog) TimingDependent() bool {
+	if rl.Flaky {
+		return true
+	}
+	for _, log := range slices.Concat(rl.BuildResults, rl.JudgeResults) {
+		if log.ResultID == requeststatus.TLE || log.Flaky {
+			return true
+		}
+		for _, process := range log.Processes {
+			if process.ResultID == requeststatus.TLE {
+				return true
+			}
+		}
+	}
+	for _, log := range rl.BenchmarkResults {
+		if log.ResultID != requeststatus.AC {
+			return true
+		}
+	}
+	return false
+}
+
+// OutputPaths returns pointers to the paths of all output files in the log, e.g., stdout of tasks and their runs,
+// so that they can be rewritten when the files are copied.
+func (rl *RequestLog) OutputPaths() []*string {
+	paths := []*string{}
+	for _, logs := range [][]TaskLog{rl.BuildResults, rl.JudgeResults} {
+		for i := range logs {
+			task := &logs[i]
+			paths = append(paths, &task.StdoutPath, &task.StderrPath)
+			for j := range task.Runs {
+				run := &task.Runs[j]
+				paths = append(paths, &run.StdoutPath, &run.StderrPath)
+				for k := range run.Processes {
+					paths = append(paths, &run.Processes[k].StdoutPath, &run.Processes[k].StderrPath)
+				}
+			}
+			for j := range task.Processes {
+				paths = append(paths, &task.Processes[j].StdoutPath, &task.Processes[j].StderrPath)
+			}
+		}
+	}
+	for i := range rl.MutationResults {
+		mutation := &rl.MutationResults[i]
+		paths = append(paths, &mutation.Reference.StdoutPath, &mutation.Reference.StderrPath)
+		for j := range mutation.Mutants {
+			paths = append(paths, &mutation.Mutants[j].StdoutPath, &mutation.Mutants[j].StderrPath)
+		}
+	}
+	return paths
+}
+
+// MutationLog is the result of a mutation task.
+type MutationLog struct {
+	TaskID    int64               `json:"task_id"`
+	ResultID  requeststatus.State `json:"result_id"` // verdict on the reference implementation, or WA if the score is below the minimum
+	Reference MutationRun         `json:"reference"`
+	Mutants   []MutationRun       `json:"mutants"`  // empty if the test suite does not pass on the reference implementation
+	Score     float64             `json:"score"`    // fraction of mutants killed
+	Survived  []string            `json:"survived"` // mutants on which the test suite passed
+}
+
+// MutationRun is a run of the test suite against the reference implementation or a mutant.
+type MutationRun struct {
+	Name       string              `json:"name"`      // directory of the implementation in resource files
+	ResultID   requeststatus.State `json:"result_id"` // AC if the test suite passed, WA if it failed
+	Killed     bool                `json:"killed"`    // the test suite did not pass, only for mutants
+	TimeMS     int64               `json:"timeMS"`
+	MemoryKB   int64               `json:"memoryKB"`
+	ExitCode   int64               `json:"exitCode"`
+	StdoutPath string              `json:"stdoutPath"`
+	StderrPath string              `json:"stderrPath"`
+}
+
+// SetMutationResults stores the results of mutation tasks,
+// and updates the overall result with their verdicts.
+func (rl *RequestLog) SetMutationResults(mutationLogs []MutationLog) {
+	rl.MutationResults = mutationLogs
+
+	for _, log := range mutationLogs {
+		rl.ResultID = rl.ResultID.Max(log.ResultID)
+	}
 }
 
 type TaskLog struct {
 	TestCaseID int64               `json:"test_case_id"`
 	ResultID   requeststatus.State `json:"result_id"`
+	TimeMS     int64               `json:"timeMS"`    // wall-clock time
+	CPUTimeMS  int64               `json:"cpuTimeMS"` // CPU time summed over threads and processes
+	MemoryKB   int64               `json:"memoryKB"`
+	ExitCode   int64               `json:"exitCode"`
+	StdoutPath string              `json:"stdoutPath"`
+	StderrPath string              `json:"stderrPath"`
+	Truncated  bool                `json:"truncated"`           // stdout or stderr was cut at the output limit
+	Runs       []RunLog            `json:"runs,omitempty"`      // every run of the task, only if it was run more than once
+	Flaky      bool                `json:"flaky"`               // runs got different verdicts
+	Generated  []string            `json:"generated,omitempty"` // expected outputs generated by this task, relative to the resource directory
+	Memcheck   []MemcheckFinding   `json:"memcheck,omitempty"`  // errors found by Valgrind memcheck, only for memcheck tasks
+	Processes  []ProcessLog        `json:"processes,omitempty"` // results of the processes, only for multi-process tasks
+	Threads    []ThreadLog         `json:"threads,omitempty"`   // CPU time of each thread that used the CPU
+}
+
+// RunLog is the result of a single run of a repeated judge task.
+type RunLog struct {
+	ResultID   requeststatus.State `json:"result_id"`
+	TimeMS     int64               `json:"timeMS"`
+	CPUTimeMS  int64               `json:"cpuTimeMS"`
+	MemoryKB   int64               `json:"memoryKB"`
+	ExitCode   int64               `json:"exitCode"`
+	StdoutPath string              `json:"stdoutPath"`
+	StderrPath string              `json:"stderrPath"`
+	Truncated  bool                `json:"truncated"`
+	Memcheck   []MemcheckFinding   `json:"memcheck,omitempty"`
+	Processes  []ProcessLog        `json:"processes,omitempty"`
+	Threads    []ThreadLog         `json:"threads,omitempty"`
+}
+
+// ProcessLog is the result of a process of a multi-process judge task.
+// Memory is measured for the whole container, so it includes the other processes running at the same time.
+type ProcessLog struct {
+	Name       string              `json:"name"`
+	ResultID   requeststatus.State `json:"result_id"`
+	Ready      bool                `json:"ready"` // the readiness check passed, or there is none
 	TimeMS     int64               `json:"timeMS"`
+	CPUTimeMS  int64               `json:"cpuTimeMS"`
 	MemoryKB   int64               `json:"memoryKB"`
 	ExitCode   int64               `json:"exitCode"`
 	StdoutPath string              `json:"stdoutPath"`
 	StderrPath string              `json:"stderrPath"`
+	Truncated  bool                `json:"truncated"`
+	Threads    []ThreadLog         `json:"threads,omitempty"`
+}
+
+// ThreadLog is the CPU time of a thread of a task, sampled periodically by the watchdog.
+// Threads that run only for a very short time may not be recorded.
+type ThreadLog struct {
+	TID       int64  `json:"tid"`
+	Name      string `json:"name"` // name of the program running the thread
+	CPUTimeMS int64  `json:"cpuTimeMS"`
+}
+
+// MemcheckFinding is an error reported by Valgrind memcheck.
+type MemcheckFinding struct {
+	Kind        string `json:"kind"`                   // kind of the error in Valgrind, e.g., "InvalidRead", "Leak_DefinitelyLost"
+	Message     string `json:"message"`                // description of the error by Valgrind
+	Leak        bool   `json:"leak"`                   // the error is a memory leak
+	LeakedBytes int64  `json:"leaked_bytes,omitempty"` // only for memory leaks
+	Location    string `json:"location,omitempty"`     // innermost source location of the error, e.g., "list.c:12 (push)"
+}
+
+// ConstructFromRuns sets the result of the task from its runs.
+// The verdict, exit code and outputs are taken from the first run with the worst verdict,
+// and time and memory are the maximum over all runs.
+func (tl *TaskLog) ConstructFromRuns(runs []RunLog) {
+	if len(runs) == 0 {
+		return
+	}
+
+	worst := runs[0]
+	var maxTimeMS int64 = 0
+	var maxCPUTimeMS int64 = 0
+	var maxMemoryKB int64 = 0
+	flaky := false
+
+	for _, run := range runs {
+		if run.ResultID > worst.ResultID {
+			worst = run
+		}
+		if run.ResultID != runs[0].ResultID {
+			flaky = true
+		}
+		maxTimeMS = max(maxTimeMS, run.TimeMS)
+		maxCPUTimeMS = max(maxCPUTimeMS, run.CPUTimeMS)
+		maxMemoryKB = max(maxMemoryKB, run.MemoryKB)
+	}
+
+	tl.ResultID = worst.ResultID
+	tl.TimeMS = maxTimeMS
+	tl.CPUTimeMS = maxCPUTimeMS
+	tl.MemoryKB = maxMemoryKB
+	tl.ExitCode = worst.ExitCode
+	tl.StdoutPath = worst.StdoutPath
+	tl.StderrPath = worst.StderrPath
+	tl.Truncated = worst.Truncated
+	tl.Memcheck = worst.Memcheck
+	tl.Processes = worst.Processes
+	tl.Threads = worst.Threads
+	tl.Flaky = flaky
+	if len(runs) > 1 {
+		tl.Runs = runs
+	} else {
+		tl.Runs = nil
+	}
 }
 
 func (rl *RequestLog) ConstructFromTaskLogs(buildLogs []TaskLog, judgeLogs []TaskLog) {
@@ -79,13 +378,18 @@ func (rl *RequestLog) ConstructFromTaskLogs(buildLogs []TaskLog, judgeLogs []Tas
 
 	// calculate total time and memory
 	var maxTimeMS int64 = 0
+	var maxCPUTimeMS int64 = 0
 	var maxMemoryKB int64 = 0
 	var maxResultState requeststatus.State = requeststatus.AC
+	flaky := false
 
 	for _, log := range buildLogs {
 		if log.TimeMS > maxTimeMS {
 			maxTimeMS = log.TimeMS
 		}
+		if log.CPUTimeMS > maxCPUTimeMS {
+			maxCPUTimeMS = log.CPUTimeMS
+		}
 
 		if log.MemoryKB > maxMemoryKB {
 			maxMemoryKB = log.MemoryKB
@@ -97,16 +401,22 @@ func (rl *RequestLog) ConstructFromTaskLogs(buildLogs []TaskLog, judgeLogs []Tas
 		if log.TimeMS > maxTimeMS {
 			maxTimeMS = log.TimeMS
 		}
+		if log.CPUTimeMS > maxCPUTimeMS {
+			maxCPUTimeMS = log.CPUTimeMS
+		}
 
 		if log.MemoryKB > maxMemoryKB {
 			maxMemoryKB = log.MemoryKB
 		}
 		maxResultState = maxResultState.Max(log.ResultID)
+		flaky = flaky || log.Flaky
 	}
 
 	rl.TimeMS = maxTimeMS
+	rl.CPUTimeMS = maxCPUTimeMS
 	rl.MemoryKB = maxMemoryKB
 	rl.ResultID = maxResultState
+	rl.Flaky = flaky
 }
 
 var _ bun.BeforeAppendModelHook = (*ValidationRequest)(nil)
@@ -131,3 +441,14 @@ func (r *GradingRequest) BeforeAppendModel(ctx context.Context, query bun.Query)
 	}
 	return nil
 }
+
+var _ bun.BeforeAppendModelHook = (*ProblemVerification)(nil)
+
+func (r *ProblemVerification) BeforeAppendModel(ctx context.Context, query bun.Query) error {
+	switch query.(type) {
+	case *bun.InsertQuery, *bun.UpdateQuery:
+		// remove fraction less than seconds (milliseconds, microeconds, ...)
+		r.TS = r.TS.Truncate(time.Second)
+	}
+	return nil
+}
@@ -23,3 +23,35 @@ func (s State) Max(other State) State {
 	}
 	return other
 }
+
+var stateNames = map[State]string{
+	AC:      "AC",
+	WA:      "WA",
+	RE:      "RE",
+	TLE:     "TLE",
+	MLE:     "MLE",
+	OLE:     "OLE",
+	CE:      "CE",
+	IE:      "IE",
+	FN:      "FN",
+	Judging: "Judging",
+	WJ:      "WJ",
+}
+
+// String returns the name of the state, same as ResultValues.name in the database.
+func (s State) String() string {
+	if name, ok := stateNames[s]; ok {
+		return name
+	}
+	return "Unknown"
+}
+
+// Parse returns the state of the given name, e.g., "WA".
+func Parse(name string) (State, bool) {
+	for state, stateName := range stateNames {
+		if stateName == name {
+			return state, true
+		}
+	}
+	return 0, false
+}
@@ -2,9 +2,14 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"errors"
+	"fmt"
+	"time"
 
 	"github.com/dsa-uts/dsa-project/database/model"
+	"github.com/dsa-uts/dsa-project/database/model/latepolicy"
+	"github.com/dsa-uts/dsa-project/database/model/problemstatus"
 	"github.com/uptrace/bun"
 )
 
@@ -103,12 +108,272 @@ func (ps *ProblemStore) CreateLectureEntry(ctx context.Context, lec *model.Lectu
 	return nil
 }
 
+// CreateLectureWithProblems creates a lecture together with its problems, e.g., when a lecture is cloned into a new term.
+// The resource files of problems[i] are at locations[i], and each problem is registered as its first version.
+func (ps *ProblemStore) CreateLectureWithProblems(ctx context.Context, lec *model.Lecture, problems []*model.Problem, locations []*model.FileLocation) error {
+	if len(problems) != len(locations) {
+		return fmt.Errorf("number of problems and resource locations differ: %d != %d", len(problems), len(locations))
+	}
+	return ps.db.RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
+		if _, err := tx.NewInsert().Model(lec).Exec(ctx); err != nil {
+			return fmt.Errorf("failed to insert lecture: %w", err)
+		}
+
+		for i, problem := range problems {
+			if _, err := tx.NewInsert().Model(locations[i]).Returning("id").Exec(ctx); err != nil {
+				return fmt.Errorf("failed to insert file location: %w", err)
+			}
+			problem.LectureID = lec.ID
+			problem.ResourceLocationID = locations[i].ID
+			problem.Version = 1
+			if _, err := tx.NewInsert().Model(problem).Exec(ctx); err != nil {
+				return fmt.Errorf("failed to insert problem: %w", err)
+			}
+
+			version := problem.MakeVersion()
+			if _, err := tx.NewInsert().Model(&version).Exec(ctx); err != nil {
+				return fmt.Errorf("failed to insert problem version: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// RegisterProblem registers a new problem as its first version.
 func (ps *ProblemStore) RegisterProblem(ctx context.Context, problem *model.Problem) error {
-	_, err := ps.db.NewInsert().Model(problem).Exec(ctx)
+	problem.Version = 1
+	return ps.db.RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
+		if _, err := tx.NewInsert().Model(problem).Exec(ctx); err != nil {
+			return fmt.Errorf("failed to insert problem: %w", err)
+		}
+
+		version := problem.MakeVersion()
+		if _, err := tx.NewInsert().Model(&version).Exec(ctx); err != nil {
+			return fmt.Errorf("failed to insert problem version: %w", err)
+		}
+		return nil
+	})
+}
+
+// AddProblemVersion registers the problem as a new version of the existing one, and sets problem.Version to its number.
+// The version becomes current if students can see it, or cannot see the current one.
+// Otherwise students keep being judged against the current version until the new one is verified, see UpdateProblemStatus.
+func (ps *ProblemStore) AddProblemVersion(ctx context.Context, problem *model.Problem) error {
+	return ps.db.RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
+		// Lock the problem, so that concurrent uploads get different version numbers
+		var current model.Problem
+		err := tx.NewSelect().Model(&current).
+			Where("lecture_id = ? AND problem_id = ?", problem.LectureID, problem.ProblemID).
+			For("UPDATE").
+			Scan(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to lock problem: %w", err)
+		}
+
+		var latest int64
+		err = tx.NewSelect().Model((*model.ProblemVersion)(nil)).
+			ColumnExpr("COALESCE(MAX(version), 0)").
+			Where("lecture_id = ? AND problem_id = ?", problem.LectureID, problem.ProblemID).
+			Scan(ctx, &latest)
+		if err != nil {
+			return fmt.Errorf("failed to get latest version: %w", err)
+		}
+		problem.Version = max(latest, current.Version) + 1
+
+		version := problem.MakeVersion()
+		if _, err := tx.NewInsert().Model(&version).Exec(ctx); err != nil {
+			return fmt.Errorf("failed to insert problem version: %w", err)
+		}
+
+		if current.IsVisible() && !problem.IsVisible() {
+			return nil
+		}
+
+		_, err = tx.NewUpdate().Model(problem).
+			Column("registered_at", "title", "resource_location_id", "detail", "status", "version").
+			WherePK().
+			Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to update problem: %w", err)
+		}
+		return nil
+	})
+}
+
+// GetProblemVersion retrieves a version of the problem, with the location of its resource files.
+func (ps *ProblemStore) GetProblemVersion(ctx context.Context, lectureID, problemID, version int64) (model.ProblemVersion, error) {
+	var problemVersion model.ProblemVersion
+	err := ps.db.NewSelect().Model(&problemVersion).
+		Relation("ResourceLocation").
+		Where("problem_version.lecture_id = ? AND problem_version.problem_id = ? AND problem_version.version = ?", lectureID, problemID, version).
+		Scan(ctx)
+	if err != nil {
+		return model.ProblemVersion{}, err
+	}
+	return problemVersion, nil
+}
+
+// GetProblemVersions retrieves all versions of the problem, the latest first.
+func (ps *ProblemStore) GetProblemVersions(ctx context.Context, lectureID, problemID int64) ([]model.ProblemVersion, error) {
+	var versions []model.ProblemVersion
+	err := ps.db.NewSelect().Model(&versions).
+		Relation("ResourceLocation").
+		Where("problem_version.lecture_id = ? AND problem_version.problem_id = ?", lectureID, problemID).
+		Order("problem_version.version DESC").
+		Scan(ctx)
 	if err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+// SetResourceDigest records the hash of the resource files of a version of the problem, unless it is already recorded.
+func (ps *ProblemStore) SetResourceDigest(ctx context.Context, lectureID, problemID, version int64, digest string) error {
+	_, err := ps.db.NewUpdate().Model(&model.ProblemVersion{}).
+		Set("resource_digest = ?", digest).
+		Where("lecture_id = ? AND problem_id = ? AND version = ?", lectureID, problemID, version).
+		Where("resource_digest IS NULL").
+		Exec(ctx)
+	return err
+}
+
+// UpdateProblemStatus updates the status of a version of the problem,
+// and the status of the problem as well if the version is the current one.
+// A version newer than the current one becomes current once the status makes it visible.
+func (ps *ProblemStore) UpdateProblemStatus(ctx context.Context, lectureID, problemID, version int64, status problemstatus.Status) error {
+	return ps.db.RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
+		var problemVersion model.ProblemVersion
+		_, err := tx.NewUpdate().Model(&problemVersion).
+			Set("status = ?", status).
+			Where("lecture_id = ? AND problem_id = ? AND version = ?", lectureID, problemID, version).
+			Returning("*").
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+
+		// Lock the problem, so that versions verified concurrently are promoted in order
+		var current model.Problem
+		err = tx.NewSelect().Model(&current).
+			Where("lecture_id = ? AND problem_id = ?", lectureID, problemID).
+			For("UPDATE").
+			Scan(ctx)
+		if err != nil {
+			return err
+		}
+
+		if current.Version == version {
+			_, err = tx.NewUpdate().Model(&current).
+				Set("status = ?", status).
+				WherePK().
+				Exec(ctx)
+			return err
+		}
+
+		// Older versions never replace newer ones
+		if version < current.Version || !status.IsVisible() {
+			return nil
+		}
+
+		current.RegisteredAt = problemVersion.RegisteredAt
+		current.Title = problemVersion.Title
+		current.ResourceLocationID = problemVersion.ResourceLocationID
+		current.Detail = problemVersion.Detail
+		current.Status = status
+		current.Version = version
+		_, err = tx.NewUpdate().Model(&current).
+			Column("registered_at", "title", "resource_location_id", "detail", "status", "version").
+			WherePK().
+			Exec(ctx)
 		return err
+	})
+}
+
+// GetLatestProblemVersion retrieves the latest version of the problem, which may not be current yet.
+func (ps *ProblemStore) GetLatestProblemVersion(ctx context.Context, lectureID, problemID int64) (model.ProblemVersion, error) {
+	var problemVersion model.ProblemVersion
+	err := ps.db.NewSelect().Model(&problemVersion).
+		Relation("ResourceLocation").
+		Where("problem_version.lecture_id = ? AND problem_version.problem_id = ?", lectureID, problemID).
+		Order("problem_version.version DESC").
+		Limit(1).
+		Scan(ctx)
+	if err != nil {
+		return model.ProblemVersion{}, err
 	}
-	return nil
+	return problemVersion, nil
+}
+
+// UpdateProblemDeadline sets the deadline and the late-submission policy of the problem.
+// nil restores the ones of the lecture.
+func (ps *ProblemStore) UpdateProblemDeadline(ctx context.Context, lectureID, problemID int64, deadline *time.Time, policy *latepolicy.Policy) error {
+	problem := &model.Problem{
+		LectureID:  lectureID,
+		ProblemID:  problemID,
+		Deadline:   deadline,
+		LatePolicy: policy,
+	}
+	_, err := ps.db.NewUpdate().Model(problem).
+		Column("deadline", "late_policy").
+		WherePK().
+		Exec(ctx)
+	return err
+}
+
+// GetDeadlineExtensions retrieves the deadline extensions of a lecture with their students,
+// ordered by student and problem.
+func (ps *ProblemStore) GetDeadlineExtensions(ctx context.Context, lectureID int64) ([]model.DeadlineExtension, error) {
+	var extensions []model.DeadlineExtension
+	err := ps.db.NewSelect().Model(&extensions).
+		Relation("User").
+		Relation("GrantedUser").
+		Where("deadline_extension.lecture_id = ?", lectureID).
+		Order("deadline_extension.usercode", "deadline_extension.problem_id").
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return extensions, nil
+}
+
+// GetDeadlineExtensionsOfUser retrieves the deadline extensions of a student in all lectures.
+func (ps *ProblemStore) GetDeadlineExtensionsOfUser(ctx context.Context, userCode int64) ([]model.DeadlineExtension, error) {
+	var extensions []model.DeadlineExtension
+	err := ps.db.NewSelect().Model(&extensions).
+		Where("usercode = ?", userCode).
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return extensions, nil
+}
+
+// PutDeadlineExtension grants a deadline extension, replacing the one of the same student and lecture or problem.
+func (ps *ProblemStore) PutDeadlineExtension(ctx context.Context, extension *model.DeadlineExtension) error {
+	_, err := ps.db.NewInsert().Model(extension).
+		On("CONFLICT (usercode, lecture_id, problem_id) DO UPDATE").
+		Set("deadline = EXCLUDED.deadline").
+		Set("reason = EXCLUDED.reason").
+		Set("granted_usercode = EXCLUDED.granted_usercode").
+		Set("granted_at = EXCLUDED.granted_at").
+		Returning("id").
+		Exec(ctx)
+	return err
+}
+
+// DeleteDeadlineExtension revokes a deadline extension of a lecture, and reports whether it existed.
+func (ps *ProblemStore) DeleteDeadlineExtension(ctx context.Context, lectureID, id int64) (bool, error) {
+	result, err := ps.db.NewDelete().Model((*model.DeadlineExtension)(nil)).
+		Where("id = ? AND lecture_id = ?", id, lectureID).
+		Exec(ctx)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
 }
 
 func (ps *ProblemStore) CheckProblemExists(ctx context.Context, lectureID, problemID int64) (bool, error) {
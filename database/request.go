@@ -2,7 +2,10 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"errors"
+	"fmt"
+	"time"
 
 	"github.com/dsa-uts/dsa-project/database/model"
 	"github.com/dsa-uts/dsa-project/database/model/requeststatus"
@@ -54,6 +57,15 @@ func (r *RequestStore) UpdateResultOfGradingRequest(ctx context.Context, id int6
 	return err
 }
 
+// UpdateProblemVersionOfGradingRequest records the version of the problem the grading request is judged with.
+func (r *RequestStore) UpdateProblemVersionOfGradingRequest(ctx context.Context, id int64, version int64) error {
+	_, err := r.db.NewUpdate().Model(&model.GradingRequest{}).
+		Set("problem_version = ?", version).
+		Where("id = ?", id).
+		Exec(ctx)
+	return err
+}
+
 type Direction string
 
 const (
@@ -137,6 +149,176 @@ func (r *RequestStore) GetGradingResultByID(ctx context.Context, id int64) (*mod
 	return &result, nil
 }
 
+func (r *RequestStore) RegisterProblemVerification(ctx context.Context, verification *model.ProblemVerification) error {
+	_, err := r.db.NewInsert().Model(verification).Returning("id"). // Return auto-incremented ID
+									Exec(ctx)
+	return err
+}
+
+func (r *RequestStore) UpdateProblemVerificationStatus(ctx context.Context, id int64, status_id requeststatus.State) error {
+	_, err := r.db.NewUpdate().Model(&model.ProblemVerification{}).Set("result = ?", int64(status_id)).Where("id = ?", id).Exec(ctx)
+	return err
+}
+
+func (r *RequestStore) UpdateResultOfProblemVerification(ctx context.Context, id int64, result_id requeststatus.State, Log model.RequestLog) error {
+	_, err := r.db.NewUpdate().Model(&model.ProblemVerification{}).
+		Set("result = ?", int64(result_id)).
+		Set("log = ?", Log).
+		Where("id = ?", id).
+		Exec(ctx)
+	return err
+}
+
+func (r *RequestStore) GetProblemVerificationByID(ctx context.Context, id int64) (*model.ProblemVerification, error) {
+	var result model.ProblemVerification
+	err := r.db.NewSelect().Model(&result).Where("problem_verification.id = ?", id).Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetLatestProblemVerification retrieves the latest verification of the problem.
+func (r *RequestStore) GetLatestProblemVerification(ctx context.Context, lectureID, problemID int64) (*model.ProblemVerification, error) {
+	var result model.ProblemVerification
+	err := r.db.NewSelect().Model(&result).
+		Where("lecture_id = ? AND problem_id = ?", lectureID, problemID).
+		Order("id DESC").
+		Limit(1).
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// RegisterRejudge registers a rejudge, before its grading requests are reset.
+func (r *RequestStore) RegisterRejudge(ctx context.Context, rejudge *model.Rejudge) error {
+	_, err := r.db.NewInsert().Model(rejudge).Returning("id"). // Return auto-incremented ID
+									Exec(ctx)
+	return err
+}
+
+// UpdateRejudgeTotal sets the number of grading requests queued by the rejudge.
+func (r *RequestStore) UpdateRejudgeTotal(ctx context.Context, rejudgeID int64, total int64) error {
+	_, err := r.db.NewUpdate().Model(&model.Rejudge{}).
+		Set("total = ?", total).
+		Where("id = ?", rejudgeID).
+		Exec(ctx)
+	return err
+}
+
+// ResetGradingRequestForRejudge archives the current result of the grading request into its history,
+// and resets it to be judged again with the given version of the problem.
+// It reports false and changes nothing if the request is being judged,
+// e.g., by a concurrent rejudge that reset it after request was read.
+func (r *RequestStore) ResetGradingRequestForRejudge(ctx context.Context, request *model.GradingRequest, rejudgeID int64, version int64) (bool, error) {
+	reset := false
+	err := r.db.RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
+		// The status is checked by the update itself, so that only one of concurrent rejudges resets the request
+		result, err := tx.NewUpdate().Model(&model.GradingRequest{}).
+			Set("result = ?", int64(requeststatus.WJ)).
+			Set("log = ?", model.RequestLog{ResultID: requeststatus.WJ}).
+			Set("problem_version = ?", version).
+			Where("id = ?", request.ID).
+			Where("result NOT IN (?)", bun.In([]int64{int64(requeststatus.WJ), int64(requeststatus.Judging)})).
+			Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to reset grading request: %w", err)
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to reset grading request: %w", err)
+		}
+		if affected == 0 {
+			return nil
+		}
+
+		history := model.GradingRequestHistory{
+			RequestID:      request.ID,
+			RejudgeID:      rejudgeID,
+			ArchivedAt:     time.Now(),
+			ProblemVersion: request.ProblemVersion,
+			ResultID:       request.ResultID,
+			Log:            request.Log,
+		}
+		if _, err := tx.NewInsert().Model(&history).Exec(ctx); err != nil {
+			return fmt.Errorf("failed to archive result: %w", err)
+		}
+		reset = true
+		return nil
+	})
+	return reset, err
+}
+
+// RestoreGradingRequestFromRejudge undoes ResetGradingRequestForRejudge,
+// e.g., when the job of the grading request could not be queued.
+// request holds the result before the reset.
+func (r *RequestStore) RestoreGradingRequestFromRejudge(ctx context.Context, request *model.GradingRequest, rejudgeID int64) error {
+	return r.db.RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
+		_, err := tx.NewDelete().Model((*model.GradingRequestHistory)(nil)).
+			Where("request_id = ? AND rejudge_id = ?", request.ID, rejudgeID).
+			Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to delete archived result: %w", err)
+		}
+
+		_, err = tx.NewUpdate().Model(&model.GradingRequest{}).
+			Set("result = ?", int64(request.ResultID)).
+			Set("log = ?", request.Log).
+			Set("problem_version = ?", request.ProblemVersion).
+			Where("id = ?", request.ID).
+			Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to restore grading request: %w", err)
+		}
+		return nil
+	})
+}
+
+func (r *RequestStore) GetRejudgeByID(ctx context.Context, id int64) (*model.Rejudge, error) {
+	var result model.Rejudge
+	err := r.db.NewSelect().Model(&result).Relation("RequestUser").Where("rejudge.id = ?", id).Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetRejudges retrieves the rejudges of a lecture, the latest first.
+func (r *RequestStore) GetRejudges(ctx context.Context, lectureID int64) ([]model.Rejudge, error) {
+	var results []model.Rejudge
+	err := r.db.NewSelect().Model(&results).
+		Relation("RequestUser").
+		Where("rejudge.lecture_id = ?", lectureID).
+		Order("rejudge.id DESC").
+		Scan(ctx)
+	return results, err
+}
+
+// GetHistoriesOfRejudge retrieves the results replaced by a rejudge,
+// with the grading requests holding their current results.
+func (r *RequestStore) GetHistoriesOfRejudge(ctx context.Context, rejudgeID int64) ([]model.GradingRequestHistory, error) {
+	var results []model.GradingRequestHistory
+	err := r.db.NewSelect().Model(&results).
+		Relation("Request").
+		Relation("Request.SubjectUser").
+		Where("grading_request_history.rejudge_id = ?", rejudgeID).
+		Order("grading_request_history.id ASC").
+		Scan(ctx)
+	return results, err
+}
+
+// GetHistoriesOfGradingRequest retrieves the previous results of a grading request, the latest first.
+func (r *RequestStore) GetHistoriesOfGradingRequest(ctx context.Context, requestID int64) ([]model.GradingRequestHistory, error) {
+	var results []model.GradingRequestHistory
+	err := r.db.NewSelect().Model(&results).
+		Where("request_id = ?", requestID).
+		Order("id DESC").
+		Scan(ctx)
+	return results, err
+}
+
 func NewRequestStore(db *bun.DB) *RequestStore {
 	return &RequestStore{
 		db: db,
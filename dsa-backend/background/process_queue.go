@@ -5,6 +5,8 @@ import (
 	"time"
 
 	"github.com/dsa-uts/dsa-project/database"
+	"github.com/dsa-uts/dsa-project/database/model"
+	"github.com/dsa-uts/dsa-project/database/model/problemstatus"
 	"github.com/dsa-uts/dsa-project/database/model/queuestatus"
 	"github.com/dsa-uts/dsa-project/database/model/queuetype"
 	"github.com/dsa-uts/dsa-project/database/model/requeststatus"
@@ -15,6 +17,8 @@ import (
 func ProcessJobQueue(ctx context.Context, db *bun.DB, logger *echo.Logger) {
 	jobQueueStore := database.NewJobQueueStore(db)
 	requestStore := database.NewRequestStore(db)
+	problemStore := database.NewProblemStore(db)
+	resultCacheStore := database.NewResultCacheStore(db)
 
 	for {
 		triggered := false
@@ -38,6 +42,8 @@ func ProcessJobQueue(ctx context.Context, db *bun.DB, logger *echo.Logger) {
 				err = requestStore.UpdateValidationRequestStatus(ctx, job.RequestID, requeststatus.Judging)
 			case queuetype.Grading:
 				err = requestStore.UpdateGradingRequestStatus(ctx, job.RequestID, requeststatus.Judging)
+			case queuetype.Verification:
+				err = requestStore.UpdateProblemVerificationStatus(ctx, job.RequestID, requeststatus.Judging)
 			default:
 				(*logger).Errorf("Unknown request type: %s", job.RequestType)
 				continue
@@ -68,6 +74,11 @@ func ProcessJobQueue(ctx context.Context, db *bun.DB, logger *echo.Logger) {
 				err = requestStore.UpdateValidationRequestStatus(ctx, job.RequestID, requeststatus.IE)
 			case queuetype.Grading:
 				err = requestStore.UpdateGradingRequestStatus(ctx, job.RequestID, requeststatus.IE)
+			case queuetype.Verification:
+				err = requestStore.UpdateProblemVerificationStatus(ctx, job.RequestID, requeststatus.IE)
+				if err == nil {
+					err = updateProblemStatusByVerification(ctx, requestStore, problemStore, job.RequestID, requeststatus.IE)
+				}
 			default:
 				(*logger).Errorf("Unknown request type: %s", job.RequestType)
 				continue
@@ -113,6 +124,16 @@ func ProcessJobQueue(ctx context.Context, db *bun.DB, logger *echo.Logger) {
 				err = requestStore.UpdateResultOfValidationRequest(ctx, job.RequestID, result.ResultID, result.Log)
 			case queuetype.Grading:
 				err = requestStore.UpdateResultOfGradingRequest(ctx, job.RequestID, result.ResultID, result.Log)
+			case queuetype.Verification:
+				// Benchmarks do not affect the overall result, but a failed one means the problem is broken
+				resultID := result.ResultID
+				if result.Log.BenchmarkFailed() {
+					resultID = requeststatus.IE
+				}
+				err = requestStore.UpdateResultOfProblemVerification(ctx, job.RequestID, resultID, result.Log)
+				if err == nil {
+					err = updateProblemStatusByVerification(ctx, requestStore, problemStore, job.RequestID, resultID)
+				}
 			default:
 				(*logger).Errorf("Unknown request type: %s", job.RequestType)
 				continue
@@ -122,6 +143,17 @@ func ProcessJobQueue(ctx context.Context, db *bun.DB, logger *echo.Logger) {
 				continue
 			}
 
+			// Cache the result so that identical jobs reuse it.
+			// Results depending on the load of the machine, e.g., TLE, are not cached, so that resubmitting runs the job again.
+			// Failures are only logged, since the result itself is already saved.
+			cacheable := result.ResultID != requeststatus.IE && !result.Log.BenchmarkFailed() && !result.Log.TimingDependent()
+			if job.Detail.CacheKey != "" && cacheable && result.Log.ImageDigest != "" {
+				err = cacheResult(ctx, requestStore, resultCacheStore, job.RequestType, job.RequestID, job.Detail.CacheKey, result.ResultID, result.Log)
+				if err != nil {
+					(*logger).Errorf("Failed to cache result of request ID %d: %v", job.RequestID, err)
+				}
+			}
+
 			// Delete the processed result entry from job queue and result queue
 			err = jobQueueStore.DeleteResultEntry(ctx, result.ID)
 			if err != nil {
@@ -152,3 +184,60 @@ func ProcessJobQueue(ctx context.Context, db *bun.DB, logger *echo.Logger) {
 		}
 	}
 }
+
+// Marks the verified problem as "verified" if its reference solution passed all tasks, "broken" otherwise.
+// Problems whose expected outputs were generated are marked as "review" instead of "verified",
+// so that the generated files are reviewed before students can see the problem.
+func updateProblemStatusByVerification(ctx context.Context, requestStore *database.RequestStore, problemStore *database.ProblemStore, verificationID int64, result requeststatus.State) error {
+	verification, err := requestStore.GetProblemVerificationByID(ctx, verificationID)
+	if err != nil {
+		return err
+	}
+
+	// The problem may have been updated since, so only the verified version is marked
+	version, err := problemStore.GetProblemVersion(ctx, verification.LectureID, verification.ProblemID, verification.ProblemVersion)
+	if err != nil {
+		return err
+	}
+
+	status := problemstatus.Broken
+	if result == requeststatus.AC {
+		status = problemstatus.Verified
+		if version.Status == problemstatus.Generating {
+			status = problemstatus.Review
+		}
+	}
+
+	return problemStore.UpdateProblemStatus(ctx, verification.LectureID, verification.ProblemID, verification.ProblemVersion, status)
+}
+
+// Stores the result of the request in the result cache, keyed by the content hash of its job.
+func cacheResult(ctx context.Context, requestStore *database.RequestStore, resultCacheStore *database.ResultCacheStore, requestType queuetype.Type, requestID int64, cacheKey string, resultID requeststatus.State, log model.RequestLog) error {
+	var lectureID, problemID int64
+	switch requestType {
+	case queuetype.Validation:
+		request, err := requestStore.GetValidationResultByID(ctx, requestID)
+		if err != nil {
+			return err
+		}
+		lectureID, problemID = request.LectureID, request.ProblemID
+	case queuetype.Grading:
+		request, err := requestStore.GetGradingResultByID(ctx, requestID)
+		if err != nil {
+			return err
+		}
+		lectureID, problemID = request.LectureID, request.ProblemID
+	default:
+		return nil
+	}
+
+	return resultCacheStore.PutResultCache(ctx, &model.ResultCache{
+		ContentHash: cacheKey,
+		ImageDigest: log.ImageDigest,
+		LectureID:   lectureID,
+		ProblemID:   problemID,
+		CreatedAt:   time.Now(),
+		ResultID:    resultID,
+		Log:         log,
+	})
+}
@@ -356,6 +356,78 @@ const docTemplate = `{
                 }
             }
         },
+        "/problem/crud/clone/{lectureid}": {
+            "post": {
+                "security": [
+                    {
+                        "OAuth2Password": [
+                            "grading"
+                        ]
+                    }
+                ],
+                "description": "Create a new lecture, e.g., for a new term, with copies of all problems of an existing lecture.\nThe resource files and the settings of the current version of each problem are copied, and registered as its first version. Submissions are not copied.\nLate policies are copied, and deadlines of problems are moved by as much as the deadline of the lecture.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Update"
+                ],
+                "summary": "Clone a lecture with all its problems",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Lecture ID to clone",
+                        "name": "lectureid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New lecture entry details",
+                        "name": "lectureEntry",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/problem.CloneLectureRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Lecture cloned successfully",
+                        "schema": {
+                            "$ref": "#/definitions/response.Success"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Lecture not found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "409": {
+                        "description": "Lecture entry already exists, or a problem is being verified",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    }
+                }
+            }
+        },
         "/problem/crud/create": {
             "put": {
                 "security": [
@@ -418,7 +490,7 @@ const docTemplate = `{
                         ]
                     }
                 ],
-                "description": "Register a new problem associated with a lecture",
+                "description": "Register a new problem associated with a lecture.\nIf the problem already exists, the upload is registered as its new version, and previous submissions keep referring to the versions they were judged with.\nThe settings are read from init.json, or from init.yaml with the same keys, at the root of the package.",
                 "consumes": [
                     "multipart/form-data"
                 ],
@@ -428,7 +500,7 @@ const docTemplate = `{
                 "tags": [
                     "Update"
                 ],
-                "summary": "Register a new problem",
+                "summary": "Register a new problem or a new version of a problem",
                 "parameters": [
                     {
                         "type": "integer",
@@ -465,6 +537,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/response.Error"
                         }
                     },
+                    "409": {
+                        "description": "Another version is being uploaded",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
@@ -474,8 +552,8 @@ const docTemplate = `{
                 }
             }
         },
-        "/problem/crud/delete/{lectureid}": {
-            "delete": {
+        "/problem/crud/deadline/{lectureid}/{problemid}": {
+            "patch": {
                 "security": [
                     {
                         "OAuth2Password": [
@@ -483,7 +561,7 @@ const docTemplate = `{
                         ]
                     }
                 ],
-                "description": "Delete an existing lecture entry, accessible by manager and admin.",
+                "description": "Set the deadline and the late-submission policy of a problem, which override the ones of the lecture. null restores the ones of the lecture.\nLate policies are \"hard\" (no score after the deadline), \"linear\" (penalty_per_day in proportion to the time after the deadline) and \"stepped\" (penalty_per_day for each started day after the deadline).\nSubmissions within grace_minutes after the deadline are not penalized with any policy.",
                 "consumes": [
                     "application/json"
                 ],
@@ -493,7 +571,7 @@ const docTemplate = `{
                 "tags": [
                     "Update"
                 ],
-                "summary": "Delete an existing lecture entry",
+                "summary": "Set the deadline and the late policy of a problem",
                 "parameters": [
                     {
                         "type": "integer",
@@ -501,11 +579,27 @@ const docTemplate = `{
                         "name": "lectureid",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Problem ID",
+                        "name": "problemid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Deadline and late policy",
+                        "name": "deadline",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/problem.ProblemDeadlineRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Lecture entry deleted successfully",
+                        "description": "OK",
                         "schema": {
                             "$ref": "#/definitions/response.Success"
                         }
@@ -516,6 +610,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/response.Error"
                         }
                     },
+                    "404": {
+                        "description": "Problem not found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
@@ -525,7 +625,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/problem/crud/delete/{lectureid}/{problemid}": {
+        "/problem/crud/delete/{lectureid}": {
             "delete": {
                 "security": [
                     {
@@ -534,7 +634,7 @@ const docTemplate = `{
                         ]
                     }
                 ],
-                "description": "delete a problem entry by lecture ID and problem ID",
+                "description": "Delete an existing lecture entry, accessible by manager and admin.",
                 "consumes": [
                     "application/json"
                 ],
@@ -544,7 +644,7 @@ const docTemplate = `{
                 "tags": [
                     "Update"
                 ],
-                "summary": "delete problem entry",
+                "summary": "Delete an existing lecture entry",
                 "parameters": [
                     {
                         "type": "integer",
@@ -552,18 +652,11 @@ const docTemplate = `{
                         "name": "lectureid",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Problem ID",
-                        "name": "problemid",
-                        "in": "path",
-                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Problem deleted successfully",
+                        "description": "Lecture entry deleted successfully",
                         "schema": {
                             "$ref": "#/definitions/response.Success"
                         }
@@ -574,12 +667,6 @@ const docTemplate = `{
                             "$ref": "#/definitions/response.Error"
                         }
                     },
-                    "404": {
-                        "description": "Problem not found",
-                        "schema": {
-                            "$ref": "#/definitions/response.Error"
-                        }
-                    },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
@@ -589,8 +676,8 @@ const docTemplate = `{
                 }
             }
         },
-        "/problem/crud/update/{lectureid}": {
-            "patch": {
+        "/problem/crud/delete/{lectureid}/{problemid}": {
+            "delete": {
                 "security": [
                     {
                         "OAuth2Password": [
@@ -598,7 +685,7 @@ const docTemplate = `{
                         ]
                     }
                 ],
-                "description": "Update an existing lecture entry, accessible by manager and admin.",
+                "description": "delete a problem entry by lecture ID and problem ID",
                 "consumes": [
                     "application/json"
                 ],
@@ -608,7 +695,7 @@ const docTemplate = `{
                 "tags": [
                     "Update"
                 ],
-                "summary": "Update an existing lecture entry",
+                "summary": "delete problem entry",
                 "parameters": [
                     {
                         "type": "integer",
@@ -618,18 +705,16 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "description": "Lecture entry details",
-                        "name": "lectureEntry",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/problem.LectureEntryRequest"
-                        }
+                        "type": "integer",
+                        "description": "Problem ID",
+                        "name": "problemid",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Lecture entry updated successfully",
+                        "description": "Problem deleted successfully",
                         "schema": {
                             "$ref": "#/definitions/response.Success"
                         }
@@ -640,6 +725,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/response.Error"
                         }
                     },
+                    "404": {
+                        "description": "Problem not found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
@@ -649,27 +740,26 @@ const docTemplate = `{
                 }
             }
         },
-        "/problem/fetch/detail/{lectureid}/{problemid}": {
+        "/problem/crud/diff/{lectureid}/{problemid}": {
             "get": {
                 "security": [
                     {
                         "OAuth2Password": [
-                            "me"
+                            "grading"
                         ]
                     }
                 ],
-                "description": "Get detailed information about a specific problem within a lecture.",
+                "description": "List resource files added, removed or modified between two versions of a problem, with their contents.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Fetch"
+                    "Update"
                 ],
-                "summary": "Get problem detail",
+                "summary": "Compare two versions of a problem",
                 "parameters": [
                     {
                         "type": "integer",
-                        "format": "int64",
                         "description": "Lecture ID",
                         "name": "lectureid",
                         "in": "path",
@@ -677,34 +767,47 @@ const docTemplate = `{
                     },
                     {
                         "type": "integer",
-                        "format": "int64",
                         "description": "Problem ID",
                         "name": "problemid",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Older version",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Newer version",
+                        "name": "to",
+                        "in": "query",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/util.ProblemDetail"
+                            "$ref": "#/definitions/problem.VersionDiffOutput"
                         }
                     },
                     "400": {
-                        "description": "invalid request",
+                        "description": "Invalid request",
                         "schema": {
                             "$ref": "#/definitions/response.Error"
                         }
                     },
                     "404": {
-                        "description": "problem not found",
+                        "description": "Version not found",
                         "schema": {
                             "$ref": "#/definitions/response.Error"
                         }
                     },
                     "500": {
-                        "description": "failed to get problem",
+                        "description": "Internal server error",
                         "schema": {
                             "$ref": "#/definitions/response.Error"
                         }
@@ -712,35 +815,77 @@ const docTemplate = `{
                 }
             }
         },
-        "/problem/fetch/list": {
+        "/problem/crud/export/{lectureid}/{problemid}": {
             "get": {
                 "security": [
                     {
                         "OAuth2Password": [
-                            "me"
+                            "grading"
                         ]
                     }
                 ],
-                "description": "get all lecture entries, each containing its problem entries. When you don't have scopes \"grading\" or \"admin\", you will only see lecture entries that are published.",
+                "description": "Rebuild a problem package from the resource files of a version of a problem. init.json (or init.yaml) is regenerated from the registered settings with all default values filled in, so the package can be registered again as it is.\nExpected outputs generated from the reference solution are included as resource files.",
                 "produces": [
-                    "application/json"
+                    "application/zip"
                 ],
                 "tags": [
-                    "Fetch"
+                    "Update"
+                ],
+                "summary": "Export a problem as a zip package",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Lecture ID",
+                        "name": "lectureid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Problem ID",
+                        "name": "problemid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Version to export, the current one if omitted",
+                        "name": "version",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "json",
+                            "yaml"
+                        ],
+                        "type": "string",
+                        "default": "json",
+                        "description": "Format of the config, init.json or init.yaml",
+                        "name": "format",
+                        "in": "query"
+                    }
                 ],
-                "summary": "list all problem entry, nested in lecture entry.",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/util.LectureEntry"
-                            }
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Problem not found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
                         }
                     },
                     "500": {
-                        "description": "failed to get lecture entries",
+                        "description": "Internal server error",
                         "schema": {
                             "$ref": "#/definitions/response.Error"
                         }
@@ -748,41 +893,57 @@ const docTemplate = `{
                 }
             }
         },
-        "/problem/fetch/requiredfiles": {
+        "/problem/crud/extension/{lectureid}": {
             "get": {
                 "security": [
                     {
                         "OAuth2Password": [
-                            "me"
+                            "grading"
                         ]
                     }
                 ],
-                "description": "Get a list of required files for each lecture, including problem-specific files and a report template.",
+                "description": "List the individual deadline extensions of students in a lecture, ordered by student and problem.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Fetch"
+                    "Update"
+                ],
+                "summary": "List deadline extensions of a lecture",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Lecture ID",
+                        "name": "lectureid",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "List required files for each lecture",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/problem.ListRequiredFilesResponse"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/problem.DeadlineExtensionEntry"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
                         }
                     },
                     "500": {
-                        "description": "failed to get lecture list",
+                        "description": "Failed to get deadline extensions",
                         "schema": {
                             "$ref": "#/definitions/response.Error"
                         }
                     }
                 }
-            }
-        },
-        "/problem/judge/batch/{lectureid}": {
-            "post": {
+            },
+            "put": {
                 "security": [
                     {
                         "OAuth2Password": [
@@ -790,17 +951,17 @@ const docTemplate = `{
                         ]
                     }
                 ],
-                "description": "This endpoint allows instructors to request grading for all problems in a specific lecture entry in a single request.",
+                "description": "Extend the deadline of a problem, or of all problems of a lecture, for a student, e.g., for approved accommodations or medical leave.\nAn extension replaces the one of the same student and problem (or lecture). It never brings a deadline forward, and the latest one applies if several cover a problem.",
                 "consumes": [
-                    "multipart/form-data"
+                    "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Submit"
+                    "Update"
                 ],
-                "summary": "Request batched grading requests for all problems in a specific lecture entry.",
+                "summary": "Grant a deadline extension to a student",
                 "parameters": [
                     {
                         "type": "integer",
@@ -810,49 +971,42 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "type": "string",
-                        "description": "User ID",
-                        "name": "userid",
-                        "in": "formData",
-                        "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "format": "int64",
-                        "description": "Submission Timestamp, epoch seconds (e.g., 1764464361)",
-                        "name": "ts",
-                        "in": "formData",
-                        "required": true
-                    },
-                    {
-                        "type": "file",
-                        "description": "Zip file containing all files user submitted",
-                        "name": "zipfile",
-                        "in": "formData",
-                        "required": true
+                        "description": "Deadline extension",
+                        "name": "extension",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/problem.DeadlineExtensionRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Batched grading requests registered successfully",
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/response.Success"
+                            "$ref": "#/definitions/problem.DeadlineExtensionEntry"
                         }
                     },
                     "400": {
-                        "description": "Invalid request payload",
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Failed to get user info",
                         "schema": {
                             "$ref": "#/definitions/response.Error"
                         }
                     },
                     "404": {
-                        "description": "No problems found for the given lecture ID",
+                        "description": "User not found\"\t\"Lecture not found\"\t\"Problem not found",
                         "schema": {
                             "$ref": "#/definitions/response.Error"
                         }
                     },
                     "500": {
-                        "description": "Failed to register grading request",
+                        "description": "Failed to grant deadline extension",
                         "schema": {
                             "$ref": "#/definitions/response.Error"
                         }
@@ -860,8 +1014,8 @@ const docTemplate = `{
                 }
             }
         },
-        "/problem/judge/{lectureid}/{problemid}": {
-            "post": {
+        "/problem/crud/extension/{lectureid}/{id}": {
+            "delete": {
                 "security": [
                     {
                         "OAuth2Password": [
@@ -869,17 +1023,14 @@ const docTemplate = `{
                         ]
                     }
                 ],
-                "description": "request a grading request, which is compiling program codes, and executes all test cases. note that the submission timestamp is specified by the user (manager or admin), and the target user ID (e.g., student) is also specified by the user.",
-                "consumes": [
-                    "multipart/form-data"
-                ],
+                "description": "Revoke an individual deadline extension, so that the student is judged against the usual deadline again.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Submit"
+                    "Update"
                 ],
-                "summary": "Request grading",
+                "summary": "Revoke a deadline extension",
                 "parameters": [
                     {
                         "type": "integer",
@@ -890,58 +1041,33 @@ const docTemplate = `{
                     },
                     {
                         "type": "integer",
-                        "description": "Problem ID",
-                        "name": "problemid",
+                        "description": "Deadline extension ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "User ID targeted for grading",
-                        "name": "userid",
-                        "in": "formData",
-                        "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Submission Timestamp, epoch seconds (e.g., 1764464361)",
-                        "name": "ts",
-                        "in": "formData",
-                        "required": true
-                    },
-                    {
-                        "type": "array",
-                        "items": {
-                            "type": "file"
-                        },
-                        "collectionFormat": "csv",
-                        "description": "Files to be graded",
-                        "name": "files",
-                        "in": "formData",
-                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Grading request registered successfully",
+                        "description": "OK",
                         "schema": {
                             "$ref": "#/definitions/response.Success"
                         }
                     },
                     "400": {
-                        "description": "Invalid request payload",
+                        "description": "Invalid request",
                         "schema": {
                             "$ref": "#/definitions/response.Error"
                         }
                     },
                     "404": {
-                        "description": "Problem not found",
+                        "description": "Deadline extension not found",
                         "schema": {
                             "$ref": "#/definitions/response.Error"
                         }
                     },
                     "500": {
-                        "description": "Internal server error",
+                        "description": "Failed to revoke deadline extension",
                         "schema": {
                             "$ref": "#/definitions/response.Error"
                         }
@@ -949,8 +1075,8 @@ const docTemplate = `{
                 }
             }
         },
-        "/problem/result/grading/list/{lectureid}": {
-            "get": {
+        "/problem/crud/lint": {
+            "post": {
                 "security": [
                     {
                         "OAuth2Password": [
@@ -958,21 +1084,23 @@ const docTemplate = `{
                         ]
                     }
                 ],
-                "description": "List grading results for a specific lecture.",
+                "description": "Check a problem package as RegisterProblem does, without registering anything, and report all problems found at once:\nviolations of the schema of init.json or init.yaml (unknown keys, wrong types, ...), missing files, duplicate titles and paths escaping the package.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Result"
+                    "Update"
                 ],
-                "summary": "List Grading Results for a Specific Lecture",
+                "summary": "Lint a problem package",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "format": "int64",
-                        "description": "The ID of the lecture to retrieve grading results for.",
-                        "name": "lectureid",
-                        "in": "path",
+                        "type": "file",
+                        "description": "Zip file contains problem resources",
+                        "name": "file",
+                        "in": "formData",
                         "required": true
                     }
                 ],
@@ -980,23 +1108,17 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/problem.GradingListOutput"
+                            "$ref": "#/definitions/problem.LintOutput"
                         }
                     },
                     "400": {
-                        "description": "Invalid request\"\t\"No users found",
-                        "schema": {
-                            "$ref": "#/definitions/response.Error"
-                        }
-                    },
-                    "401": {
-                        "description": "Failed to get user info",
+                        "description": "Invalid request",
                         "schema": {
                             "$ref": "#/definitions/response.Error"
                         }
                     },
                     "500": {
-                        "description": "Failed to get user list\"\t\"Failed to get grading results\"\t\"Failed to get lecture info\"\t\"Inconsistent data: user not found\"\t\"Inconsistent data: user not found in detail assembly",
+                        "description": "Internal server error",
                         "schema": {
                             "$ref": "#/definitions/response.Error"
                         }
@@ -1004,8 +1126,8 @@ const docTemplate = `{
                 }
             }
         },
-        "/problem/result/grading/summary/{lectureid}/{userid}": {
-            "get": {
+        "/problem/crud/publish/{lectureid}/{problemid}": {
+            "post": {
                 "security": [
                     {
                         "OAuth2Password": [
@@ -1013,27 +1135,26 @@ const docTemplate = `{
                         ]
                     }
                 ],
-                "description": "Get detailed information about a specific grading result.",
+                "description": "Make the latest version of a problem current and visible to students, after its expected outputs generated from the reference solution are reviewed.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Result"
+                    "Update"
                 ],
-                "summary": "Get Grading Result Detail",
+                "summary": "Publish a problem with generated outputs",
                 "parameters": [
                     {
                         "type": "integer",
-                        "format": "int64",
                         "description": "Lecture ID",
                         "name": "lectureid",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "type": "string",
-                        "description": "User ID",
-                        "name": "userid",
+                        "type": "integer",
+                        "description": "Problem ID",
+                        "name": "problemid",
                         "in": "path",
                         "required": true
                     }
@@ -1042,7 +1163,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/problem.GradingDetailOutput"
+                            "$ref": "#/definitions/response.Success"
                         }
                     },
                     "400": {
@@ -1051,20 +1172,20 @@ const docTemplate = `{
                             "$ref": "#/definitions/response.Error"
                         }
                     },
-                    "401": {
-                        "description": "Failed to get user info",
+                    "404": {
+                        "description": "Problem not found",
                         "schema": {
                             "$ref": "#/definitions/response.Error"
                         }
                     },
-                    "404": {
-                        "description": "Grading result not found",
+                    "409": {
+                        "description": "Problem is not waiting for review",
                         "schema": {
                             "$ref": "#/definitions/response.Error"
                         }
                     },
                     "500": {
-                        "description": "Failed to get grading result\"\t\"Failed to get lecture info\"\t\"Failed to get problem info\"\t\"Inconsistent data: user info missing\"\t\"File location not found\"\t\"Failed to fetch uploaded files\"\t\"Failed to read build stdout\"\t\"Failed to read build stderr\"\t\"Failed to read judge stdout\"\t\"Failed to read judge stderr",
+                        "description": "Internal server error",
                         "schema": {
                             "$ref": "#/definitions/response.Error"
                         }
@@ -1072,29 +1193,42 @@ const docTemplate = `{
                 }
             }
         },
-        "/problem/result/validation/detail/{id}": {
-            "get": {
+        "/problem/crud/rollback/{lectureid}/{problemid}/{version}": {
+            "post": {
                 "security": [
                     {
                         "OAuth2Password": [
-                            "me"
+                            "grading"
                         ]
                     }
                 ],
-                "description": "Get detailed information about a specific validation result.",
+                "description": "Register a copy of a previous version of a problem as its new version. The reference solution is verified again, if any.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Result"
+                    "Update"
                 ],
-                "summary": "Get Validation Result Detail",
+                "summary": "Roll back a problem to a previous version",
                 "parameters": [
                     {
                         "type": "integer",
-                        "format": "int64",
-                        "description": "Validation Result ID",
-                        "name": "id",
+                        "description": "Lecture ID",
+                        "name": "lectureid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Problem ID",
+                        "name": "problemid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Version to roll back to",
+                        "name": "version",
                         "in": "path",
                         "required": true
                     }
@@ -1103,7 +1237,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/problem.DetailOutput"
+                            "$ref": "#/definitions/response.Success"
                         }
                     },
                     "400": {
@@ -1112,20 +1246,20 @@ const docTemplate = `{
                             "$ref": "#/definitions/response.Error"
                         }
                     },
-                    "401": {
-                        "description": "Failed to get user info",
+                    "404": {
+                        "description": "Version not found",
                         "schema": {
                             "$ref": "#/definitions/response.Error"
                         }
                     },
-                    "404": {
-                        "description": "Validation result not found",
+                    "409": {
+                        "description": "Version is already current",
                         "schema": {
                             "$ref": "#/definitions/response.Error"
                         }
                     },
                     "500": {
-                        "description": "Failed to get validation result\"\t\"File location not found\"\t\"Failed to fetch uploaded files\"\t\"Failed to read build stdout\"\t\"Failed to read build stderr\"\t\"Failed to read judge stdout\"\t\"Failed to read judge stderr",
+                        "description": "Internal server error",
                         "schema": {
                             "$ref": "#/definitions/response.Error"
                         }
@@ -1133,8 +1267,8 @@ const docTemplate = `{
                 }
             }
         },
-        "/problem/result/validation/list": {
-            "get": {
+        "/problem/crud/update/{lectureid}": {
+            "patch": {
                 "security": [
                     {
                         "OAuth2Password": [
@@ -1142,41 +1276,40 @@ const docTemplate = `{
                         ]
                     }
                 ],
-                "description": "List validation results (not detailed, just summary) for the current user.",
+                "description": "Update an existing lecture entry, accessible by manager and admin.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Result"
+                    "Update"
                 ],
-                "summary": "List Validation Results for Current User",
+                "summary": "Update an existing lecture entry",
                 "parameters": [
                     {
-                        "minimum": 0,
                         "type": "integer",
-                        "format": "int64",
-                        "default": 15000000,
-                        "description": "The anchor ID received in the previous request.",
-                        "name": "anchor",
-                        "in": "query"
+                        "description": "Lecture ID",
+                        "name": "lectureid",
+                        "in": "path",
+                        "required": true
                     },
                     {
-                        "enum": [
-                            "next",
-                            "prev"
-                        ],
-                        "type": "string",
-                        "default": "next",
-                        "description": "The direction to fetch results. Use 'next' to get older results and 'prev' to get newer results.",
-                        "name": "direction",
-                        "in": "query"
+                        "description": "Lecture entry details",
+                        "name": "lectureEntry",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/problem.LectureEntryRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "Lecture entry updated successfully",
                         "schema": {
-                            "$ref": "#/definitions/problem.ListingOutput"
+                            "$ref": "#/definitions/response.Success"
                         }
                     },
                     "400": {
@@ -1185,14 +1318,8 @@ const docTemplate = `{
                             "$ref": "#/definitions/response.Error"
                         }
                     },
-                    "401": {
-                        "description": "Failed to get user info",
-                        "schema": {
-                            "$ref": "#/definitions/response.Error"
-                        }
-                    },
                     "500": {
-                        "description": "Failed to get lecture entries\"\t\"Failed to get validation results",
+                        "description": "Internal server error",
                         "schema": {
                             "$ref": "#/definitions/response.Error"
                         }
@@ -1200,29 +1327,35 @@ const docTemplate = `{
                 }
             }
         },
-        "/problem/result/validation/{id}": {
+        "/problem/crud/verification/{lectureid}/{problemid}": {
             "get": {
                 "security": [
                     {
                         "OAuth2Password": [
-                            "me"
+                            "grading"
                         ]
                     }
                 ],
-                "description": "Get summary information about a specific validation result.",
+                "description": "Get the status of the reference solution verification of a problem, the tasks failed by the reference solution, and the expected outputs generated from it.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Result"
+                    "Update"
                 ],
-                "summary": "Get Validation Result Summary",
+                "summary": "Get the verification result of a problem",
                 "parameters": [
                     {
                         "type": "integer",
-                        "format": "int64",
-                        "description": "Validation Result ID",
-                        "name": "id",
+                        "description": "Lecture ID",
+                        "name": "lectureid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Problem ID",
+                        "name": "problemid",
                         "in": "path",
                         "required": true
                     }
@@ -1231,7 +1364,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/problem.ValidationResult"
+                            "$ref": "#/definitions/problem.VerificationOutput"
                         }
                     },
                     "400": {
@@ -1240,20 +1373,14 @@ const docTemplate = `{
                             "$ref": "#/definitions/response.Error"
                         }
                     },
-                    "401": {
-                        "description": "Failed to get user info",
-                        "schema": {
-                            "$ref": "#/definitions/response.Error"
-                        }
-                    },
                     "404": {
-                        "description": "Validation result not found",
+                        "description": "Problem not found",
                         "schema": {
                             "$ref": "#/definitions/response.Error"
                         }
                     },
                     "500": {
-                        "description": "Failed to get validation result",
+                        "description": "Internal server error",
                         "schema": {
                             "$ref": "#/definitions/response.Error"
                         }
@@ -1261,26 +1388,23 @@ const docTemplate = `{
                 }
             }
         },
-        "/problem/validate/batch/{lectureid}": {
-            "post": {
+        "/problem/crud/versions/{lectureid}/{problemid}": {
+            "get": {
                 "security": [
                     {
                         "OAuth2Password": [
-                            "me"
+                            "grading"
                         ]
                     }
                 ],
-                "description": "This endpoint allows users to request validation for all problems within a specific lecture.",
-                "consumes": [
-                    "multipart/form-data"
-                ],
+                "description": "List all uploaded versions of a problem, the latest first.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Submit"
+                    "Update"
                 ],
-                "summary": "Request validation for all problems in a specific lecture entry.",
+                "summary": "List versions of a problem",
                 "parameters": [
                     {
                         "type": "integer",
@@ -1290,34 +1414,37 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "type": "file",
-                        "description": "Zip file containing all program codes you're submitting",
-                        "name": "zipfile",
-                        "in": "formData",
+                        "type": "integer",
+                        "description": "Problem ID",
+                        "name": "problemid",
+                        "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Batch validation requests registered successfully",
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/response.Success"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/problem.ProblemVersionOutput"
+                            }
                         }
                     },
                     "400": {
-                        "description": "Invalid request payload",
+                        "description": "Invalid request",
                         "schema": {
                             "$ref": "#/definitions/response.Error"
                         }
                     },
                     "404": {
-                        "description": "No problems found for the given lecture ID",
+                        "description": "Problem not found",
                         "schema": {
                             "$ref": "#/definitions/response.Error"
                         }
                     },
                     "500": {
-                        "description": "Failed to register batch validation requests",
+                        "description": "Internal server error",
                         "schema": {
                             "$ref": "#/definitions/response.Error"
                         }
@@ -1325,8 +1452,8 @@ const docTemplate = `{
                 }
             }
         },
-        "/problem/validate/{lectureid}/{problemid}": {
-            "post": {
+        "/problem/fetch/detail/{lectureid}/{problemid}": {
+            "get": {
                 "security": [
                     {
                         "OAuth2Password": [
@@ -1334,20 +1461,18 @@ const docTemplate = `{
                         ]
                     }
                 ],
-                "description": "request a validation request, which is just compiling program codes, and executes some simple test cases.",
-                "consumes": [
-                    "multipart/form-data"
-                ],
+                "description": "Get detailed information about a specific problem within a lecture.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Submit"
+                    "Fetch"
                 ],
-                "summary": "Request validation",
+                "summary": "Get problem detail",
                 "parameters": [
                     {
                         "type": "integer",
+                        "format": "int64",
                         "description": "Lecture ID",
                         "name": "lectureid",
                         "in": "path",
@@ -1355,38 +1480,82 @@ const docTemplate = `{
                     },
                     {
                         "type": "integer",
+                        "format": "int64",
                         "description": "Problem ID",
                         "name": "problemid",
                         "in": "path",
                         "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.ProblemDetail"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
                     },
+                    "404": {
+                        "description": "problem not found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "failed to get problem",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/problem/fetch/fileref/{id}": {
+            "get": {
+                "security": [
                     {
-                        "type": "array",
-                        "items": {
-                            "type": "file"
-                        },
-                        "collectionFormat": "csv",
-                        "description": "Files to validate",
-                        "name": "files",
-                        "in": "formData",
+                        "OAuth2Password": [
+                            "me"
+                        ]
+                    }
+                ],
+                "description": "Get an image or an attachment linked from the markdown of a problem. Links in the markdown returned by /problem/fetch/detail are rewritten to this endpoint.\nWhen you don't have scopes \"grading\" or \"admin\", you can only get files of published problems.",
+                "produces": [
+                    "application/octet-stream"
+                ],
+                "tags": [
+                    "Fetch"
+                ],
+                "summary": "Get a file referenced from a problem description",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "File reference ID",
+                        "name": "id",
+                        "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Validation request registered successfully",
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/response.Success"
+                            "type": "file"
                         }
                     },
                     "400": {
-                        "description": "Invalid request",
+                        "description": "invalid request",
                         "schema": {
                             "$ref": "#/definitions/response.Error"
                         }
                     },
-                    "500": {
-                        "description": "Internal server error",
+                    "404": {
+                        "description": "file not found",
                         "schema": {
                             "$ref": "#/definitions/response.Error"
                         }
@@ -1394,32 +1563,35 @@ const docTemplate = `{
                 }
             }
         },
-        "/user/grading/list": {
+        "/problem/fetch/list": {
             "get": {
                 "security": [
                     {
                         "OAuth2Password": [
-                            "grading"
+                            "me"
                         ]
                     }
                 ],
-                "description": "List all users with their IDs and names",
+                "description": "get all lecture entries, each containing its problem entries. When you don't have scopes \"grading\" or \"admin\", you will only see lecture entries that are published.\nDeadlines of the problems are extended for you if you have deadline extensions, where you don't have scopes \"grading\" or \"admin\".",
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "User"
+                    "Fetch"
                 ],
-                "summary": "List all users",
+                "summary": "list all problem entry, nested in lecture entry.",
                 "responses": {
                     "200": {
-                        "description": "List of users",
+                        "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/user.userInfoEntity"
+                                "$ref": "#/definitions/util.LectureEntry"
                             }
                         }
                     },
                     "500": {
-                        "description": "Internal server error",
+                        "description": "failed to get lecture entries",
                         "schema": {
                             "$ref": "#/definitions/response.Error"
                         }
@@ -1427,77 +1599,168 @@ const docTemplate = `{
                 }
             }
         },
-        "/user/login": {
+        "/problem/fetch/requiredfiles": {
+            "get": {
+                "security": [
+                    {
+                        "OAuth2Password": [
+                            "me"
+                        ]
+                    }
+                ],
+                "description": "Get a list of required files for each lecture, including problem-specific files and a report template.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Fetch"
+                ],
+                "summary": "List required files for each lecture",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/problem.ListRequiredFilesResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "failed to get lecture list",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/problem/judge/batch/{lectureid}": {
             "post": {
-                "description": "User login with user ID and password. Returns a JWT token if successful.",
+                "security": [
+                    {
+                        "OAuth2Password": [
+                            "grading"
+                        ]
+                    }
+                ],
+                "description": "This endpoint allows instructors to request grading for all problems in a specific lecture entry in a single request.",
                 "consumes": [
-                    "application/x-www-form-urlencoded"
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
                 ],
                 "tags": [
-                    "User"
+                    "Submit"
                 ],
-                "summary": "User Login",
+                "summary": "Request batched grading requests for all problems in a specific lecture entry.",
                 "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Lecture ID",
+                        "name": "lectureid",
+                        "in": "path",
+                        "required": true
+                    },
                     {
                         "type": "string",
                         "description": "User ID",
-                        "name": "username",
+                        "name": "userid",
                         "in": "formData",
                         "required": true
                     },
                     {
-                        "type": "string",
-                        "description": "Password",
-                        "name": "password",
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "Submission Timestamp, epoch seconds (e.g., 1764464361)",
+                        "name": "ts",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "Zip file containing all files user submitted",
+                        "name": "zipfile",
                         "in": "formData",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Login successful. Returns a JWT token.",
+                        "description": "Batched grading requests registered successfully",
                         "schema": {
-                            "$ref": "#/definitions/user.userLoginResponse"
+                            "$ref": "#/definitions/response.Success"
                         }
                     },
                     "400": {
-                        "description": "Bad request. This error occurs if the user ID or password is missing or incorrect.",
+                        "description": "Invalid request payload",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "No problems found for the given lecture ID",
                         "schema": {
                             "$ref": "#/definitions/response.Error"
                         }
                     },
                     "500": {
-                        "description": "Internal server error. This error occurs if there is an issue with the database or password hashing.",
+                        "description": "Failed to register grading request",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/response.Error"
                         }
                     }
                 }
             }
         },
-        "/user/logout": {
+        "/problem/judge/rejudge/lecture/{lectureid}": {
             "post": {
                 "security": [
                     {
                         "OAuth2Password": [
-                            "me"
+                            "grading"
                         ]
                     }
                 ],
-                "description": "Logout user and invalidate JWT token",
+                "description": "Judge all grading requests of all problems of a lecture again with their current versions, from their stored files. The previous results are kept in the history of the requests.\nRequests still being judged are skipped.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "User"
+                    "Submit"
+                ],
+                "summary": "Rejudge grading requests of a lecture",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Lecture ID",
+                        "name": "lectureid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated verdicts to rejudge, e.g., WA,IE. All verdicts if omitted",
+                        "name": "results",
+                        "in": "formData"
+                    }
                 ],
-                "summary": "Logout user",
                 "responses": {
                     "200": {
-                        "description": "Logout successful",
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/response.Success"
+                            "$ref": "#/definitions/problem.RejudgeOutput"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "400": {
+                        "description": "Invalid request payload",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "No grading requests to rejudge",
                         "schema": {
                             "$ref": "#/definitions/response.Error"
                         }
@@ -1505,35 +1768,50 @@ const docTemplate = `{
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/response.Error"
                         }
                     }
                 }
             }
         },
-        "/user/me": {
+        "/problem/judge/rejudge/list/{lectureid}": {
             "get": {
                 "security": [
                     {
                         "OAuth2Password": [
-                            "me"
+                            "grading"
                         ]
                     }
                 ],
-                "description": "Get current user information from JWT token",
+                "description": "List the rejudges of grading requests of a lecture with their progress, the latest first.",
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "User"
+                    "Submit"
+                ],
+                "summary": "List rejudges of a lecture",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Lecture ID",
+                        "name": "lectureid",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Get current user information",
                 "responses": {
                     "200": {
-                        "description": "Current user information",
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/user.userResponse"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/problem.RejudgeSummary"
+                            }
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "400": {
+                        "description": "Invalid request payload",
                         "schema": {
                             "$ref": "#/definitions/response.Error"
                         }
@@ -1541,200 +1819,1836 @@ const docTemplate = `{
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/response.Error"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "admin.ListUserResponse": {
-            "type": "object",
-            "properties": {
-                "users": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/admin.UserInfo"
+        },
+        "/problem/judge/rejudge/problem/{lectureid}/{problemid}": {
+            "post": {
+                "security": [
+                    {
+                        "OAuth2Password": [
+                            "grading"
+                        ]
+                    }
+                ],
+                "description": "Judge all grading requests of a problem again with its current version, from their stored files. The previous results are kept in the history of the requests.\nRequests still being judged are skipped.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Submit"
+                ],
+                "summary": "Rejudge grading requests of a problem",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Lecture ID",
+                        "name": "lectureid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Problem ID",
+                        "name": "problemid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated verdicts to rejudge, e.g., WA,IE. All verdicts if omitted",
+                        "name": "results",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/problem.RejudgeOutput"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request payload",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "No grading requests to rejudge",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
                     }
                 }
             }
         },
-        "admin.ModifyUserProps": {
-            "type": "object",
-            "properties": {
-                "email": {
-                    "type": "string"
-                },
-                "name": {
-                    "type": "string",
-                    "maxLength": 30,
-                    "minLength": 1
-                },
-                "password": {
-                    "type": "string",
-                    "maxLength": 100,
-                    "minLength": 8
+        "/problem/judge/rejudge/request/{id}": {
+            "post": {
+                "security": [
+                    {
+                        "OAuth2Password": [
+                            "grading"
+                        ]
+                    }
+                ],
+                "description": "Judge an existing grading request again with the current version of the problem, from its stored files. The previous result is kept in the history of the request.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Submit"
+                ],
+                "summary": "Rejudge a grading request",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Grading Request ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/problem.RejudgeOutput"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request payload",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Grading request not found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "409": {
+                        "description": "Grading request is still being judged",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/problem/judge/rejudge/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "OAuth2Password": [
+                            "grading"
+                        ]
+                    }
+                ],
+                "description": "Get the grading requests of a rejudge with their previous and current results, and how many of them are judged again.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Submit"
+                ],
+                "summary": "Get the progress of a rejudge",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Rejudge ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/problem.RejudgeProgressOutput"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request payload",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Rejudge not found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/problem/judge/rerun/{id}": {
+            "post": {
+                "security": [
+                    {
+                        "OAuth2Password": [
+                            "grading"
+                        ]
+                    }
+                ],
+                "description": "Run every judge task of an existing grading request the specified number of times, to check whether timing-sensitive verdicts are stable. Every run is recorded in the result, and tasks whose runs got different verdicts are marked as flaky. The result of the grading request is replaced with the worst verdict of all runs, and the previous one is kept in its history as a rejudge.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Submit"
+                ],
+                "summary": "Rerun a grading request N times",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Grading Request ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of runs of each judge task (2-10)",
+                        "name": "times",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/problem.RejudgeOutput"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request payload",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Grading request not found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "409": {
+                        "description": "Grading request is still being judged",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/problem/judge/{lectureid}/{problemid}": {
+            "post": {
+                "security": [
+                    {
+                        "OAuth2Password": [
+                            "grading"
+                        ]
+                    }
+                ],
+                "description": "request a grading request, which is compiling program codes, and executes all test cases. note that the submission timestamp is specified by the user (manager or admin), and the target user ID (e.g., student) is also specified by the user.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Submit"
+                ],
+                "summary": "Request grading",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Lecture ID",
+                        "name": "lectureid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Problem ID",
+                        "name": "problemid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "User ID targeted for grading",
+                        "name": "userid",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Submission Timestamp, epoch seconds (e.g., 1764464361)",
+                        "name": "ts",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "file"
+                        },
+                        "collectionFormat": "csv",
+                        "description": "Files to be graded",
+                        "name": "files",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Grading request registered successfully",
+                        "schema": {
+                            "$ref": "#/definitions/response.Success"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request payload",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Problem not found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/problem/result/grading/history/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "OAuth2Password": [
+                            "grading"
+                        ]
+                    }
+                ],
+                "description": "Get the results of a grading request replaced by rejudges, the latest first.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Result"
+                ],
+                "summary": "Get previous results of a grading request",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Grading Request ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/problem.GradingHistoryEntry"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request payload",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/problem/result/grading/list/{lectureid}": {
+            "get": {
+                "security": [
+                    {
+                        "OAuth2Password": [
+                            "grading"
+                        ]
+                    }
+                ],
+                "description": "List grading results for a specific lecture.\nThe submission time of each result is checked against the deadline of its problem, and the penalty of the late policy is computed.\nDeadlines are extended for students with deadline extensions.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Result"
+                ],
+                "summary": "List Grading Results for a Specific Lecture",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "The ID of the lecture to retrieve grading results for.",
+                        "name": "lectureid",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/problem.GradingListOutput"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request\"\t\"No users found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Failed to get user info",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get user list\"\t\"Failed to get grading results\"\t\"Failed to get lecture info\"\t\"Failed to get deadline extensions\"\t\"Inconsistent data: user not found\"\t\"Inconsistent data: user not found in detail assembly",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/problem/result/grading/summary/{lectureid}/{userid}": {
+            "get": {
+                "security": [
+                    {
+                        "OAuth2Password": [
+                            "grading"
+                        ]
+                    }
+                ],
+                "description": "Get detailed information about a specific grading result.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Result"
+                ],
+                "summary": "Get Grading Result Detail",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "Lecture ID",
+                        "name": "lectureid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "userid",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/problem.GradingDetailOutput"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Failed to get user info",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Grading result not found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get grading result\"\t\"Failed to get lecture info\"\t\"Failed to get problem info\"\t\"Inconsistent data: user info missing\"\t\"File location not found\"\t\"Failed to fetch uploaded files\"\t\"Failed to read build stdout\"\t\"Failed to read build stderr\"\t\"Failed to read judge stdout\"\t\"Failed to read judge stderr",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/problem/result/validation/detail/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "OAuth2Password": [
+                            "me"
+                        ]
+                    }
+                ],
+                "description": "Get detailed information about a specific validation result.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Result"
+                ],
+                "summary": "Get Validation Result Detail",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "Validation Result ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/problem.DetailOutput"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Failed to get user info",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Validation result not found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get validation result\"\t\"File location not found\"\t\"Failed to fetch uploaded files\"\t\"Failed to read build stdout\"\t\"Failed to read build stderr\"\t\"Failed to read judge stdout\"\t\"Failed to read judge stderr",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/problem/result/validation/list": {
+            "get": {
+                "security": [
+                    {
+                        "OAuth2Password": [
+                            "grading"
+                        ]
+                    }
+                ],
+                "description": "List validation results (not detailed, just summary) for the current user.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Result"
+                ],
+                "summary": "List Validation Results for Current User",
+                "parameters": [
+                    {
+                        "minimum": 0,
+                        "type": "integer",
+                        "format": "int64",
+                        "default": 15000000,
+                        "description": "The anchor ID received in the previous request.",
+                        "name": "anchor",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "next",
+                            "prev"
+                        ],
+                        "type": "string",
+                        "default": "next",
+                        "description": "The direction to fetch results. Use 'next' to get older results and 'prev' to get newer results.",
+                        "name": "direction",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/problem.ListingOutput"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Failed to get user info",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get lecture entries\"\t\"Failed to get validation results",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/problem/result/validation/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "OAuth2Password": [
+                            "me"
+                        ]
+                    }
+                ],
+                "description": "Get summary information about a specific validation result.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Result"
+                ],
+                "summary": "Get Validation Result Summary",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "Validation Result ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/problem.ValidationResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Failed to get user info",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Validation result not found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get validation result",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/problem/validate/batch/{lectureid}": {
+            "post": {
+                "security": [
+                    {
+                        "OAuth2Password": [
+                            "me"
+                        ]
+                    }
+                ],
+                "description": "This endpoint allows users to request validation for all problems within a specific lecture.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Submit"
+                ],
+                "summary": "Request validation for all problems in a specific lecture entry.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Lecture ID",
+                        "name": "lectureid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "Zip file containing all program codes you're submitting",
+                        "name": "zipfile",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Batch validation requests registered successfully",
+                        "schema": {
+                            "$ref": "#/definitions/response.Success"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request payload",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "No problems found for the given lecture ID",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to register batch validation requests",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/problem/validate/{lectureid}/{problemid}": {
+            "post": {
+                "security": [
+                    {
+                        "OAuth2Password": [
+                            "me"
+                        ]
+                    }
+                ],
+                "description": "request a validation request, which is just compiling program codes, and executes some simple test cases.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Submit"
+                ],
+                "summary": "Request validation",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Lecture ID",
+                        "name": "lectureid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Problem ID",
+                        "name": "problemid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "file"
+                        },
+                        "collectionFormat": "csv",
+                        "description": "Files to validate",
+                        "name": "files",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Validation request registered successfully",
+                        "schema": {
+                            "$ref": "#/definitions/response.Success"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/grading/list": {
+            "get": {
+                "security": [
+                    {
+                        "OAuth2Password": [
+                            "grading"
+                        ]
+                    }
+                ],
+                "description": "List all users with their IDs and names",
+                "tags": [
+                    "User"
+                ],
+                "summary": "List all users",
+                "responses": {
+                    "200": {
+                        "description": "List of users",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/user.userInfoEntity"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/login": {
+            "post": {
+                "description": "User login with user ID and password. Returns a JWT token if successful.",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "User Login",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "username",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Password",
+                        "name": "password",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Login successful. Returns a JWT token.",
+                        "schema": {
+                            "$ref": "#/definitions/user.userLoginResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request. This error occurs if the user ID or password is missing or incorrect.",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error. This error occurs if there is an issue with the database or password hashing.",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/logout": {
+            "post": {
+                "security": [
+                    {
+                        "OAuth2Password": [
+                            "me"
+                        ]
+                    }
+                ],
+                "description": "Logout user and invalidate JWT token",
+                "tags": [
+                    "User"
+                ],
+                "summary": "Logout user",
+                "responses": {
+                    "200": {
+                        "description": "Logout successful",
+                        "schema": {
+                            "$ref": "#/definitions/response.Success"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/me": {
+            "get": {
+                "security": [
+                    {
+                        "OAuth2Password": [
+                            "me"
+                        ]
+                    }
+                ],
+                "description": "Get current user information from JWT token",
+                "tags": [
+                    "User"
+                ],
+                "summary": "Get current user information",
+                "responses": {
+                    "200": {
+                        "description": "Current user information",
+                        "schema": {
+                            "$ref": "#/definitions/user.userResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "admin.ListUserResponse": {
+            "type": "object",
+            "properties": {
+                "users": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/admin.UserInfo"
+                    }
+                }
+            }
+        },
+        "admin.ModifyUserProps": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 30,
+                    "minLength": 1
+                },
+                "password": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "minLength": 8
+                },
+                "role": {
+                    "type": "string",
+                    "enum": [
+                        "admin",
+                        "manager",
+                        "student"
+                    ]
+                }
+            }
+        },
+        "admin.UserInfo": {
+            "type": "object",
+            "properties": {
+                "archived": {
+                    "type": "boolean"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "admin.registerUserRequest": {
+            "type": "object",
+            "required": [
+                "name",
+                "password",
+                "role",
+                "user_id"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 30,
+                    "minLength": 1
+                },
+                "password": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "minLength": 8
+                },
+                "role": {
+                    "type": "string",
+                    "enum": [
+                        "admin",
+                        "manager",
+                        "student"
+                    ]
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "problem.BenchmarkDetail": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "error": {
+                    "description": "why the benchmark is IE",
+                    "type": "string"
+                },
+                "estimated_class": {
+                    "description": "in big-O notation, empty if not estimated",
+                    "type": "string"
+                },
+                "expected_classes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "fits": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/problem.ComplexityFit"
+                    }
+                },
+                "points": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/problem.BenchmarkPoint"
+                    }
+                },
+                "result_id": {
+                    "type": "integer"
+                },
+                "task_id": {
+                    "type": "integer"
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "problem.BenchmarkPoint": {
+            "type": "object",
+            "properties": {
+                "memory_kb": {
+                    "type": "integer"
+                },
+                "n": {
+                    "type": "integer"
+                },
+                "result_id": {
+                    "type": "integer"
+                },
+                "time_ms": {
+                    "type": "integer"
+                }
+            }
+        },
+        "problem.CloneLectureRequest": {
+            "type": "object",
+            "required": [
+                "deadline",
+                "id",
+                "start_date"
+            ],
+            "properties": {
+                "deadline": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "sourceID": {
+                    "type": "integer"
+                },
+                "start_date": {
+                    "type": "integer"
+                },
+                "title": {
+                    "description": "the title of the source lecture if empty",
+                    "type": "string"
+                }
+            }
+        },
+        "problem.ComplexityFit": {
+            "type": "object",
+            "properties": {
+                "class": {
+                    "description": "in big-O notation",
+                    "type": "string"
+                },
+                "residual": {
+                    "description": "smaller is better",
+                    "type": "number"
+                }
+            }
+        },
+        "problem.DeadlineExtensionEntry": {
+            "type": "object",
+            "properties": {
+                "deadline": {
+                    "type": "integer"
+                },
+                "granted_at": {
+                    "type": "integer"
+                },
+                "granted_user_id": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "lecture_id": {
+                    "type": "integer"
+                },
+                "problem_id": {
+                    "description": "null for all problems of the lecture",
+                    "type": "integer"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                },
+                "user_name": {
+                    "type": "string"
+                }
+            }
+        },
+        "problem.DeadlineExtensionRequest": {
+            "type": "object",
+            "required": [
+                "deadline",
+                "user_id"
+            ],
+            "properties": {
+                "deadline": {
+                    "type": "integer"
+                },
+                "lectureID": {
+                    "type": "integer"
+                },
+                "problem_id": {
+                    "description": "null for all problems of the lecture",
+                    "type": "integer"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "problem.DetailOutput": {
+            "type": "object",
+            "properties": {
+                "build_logs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/problem.DetailedTaskLog"
+                    }
+                },
+                "cached": {
+                    "description": "result reused from an identical job",
+                    "type": "boolean"
+                },
+                "cpu_time_ms": {
+                    "type": "integer"
+                },
+                "flaky": {
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "judge_logs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/problem.DetailedTaskLog"
+                    }
+                },
+                "lecture_id": {
+                    "type": "integer"
+                },
+                "lecture_title": {
+                    "type": "string"
+                },
+                "memory_kb": {
+                    "type": "integer"
+                },
+                "problem_id": {
+                    "type": "integer"
+                },
+                "problem_title": {
+                    "type": "string"
+                },
+                "result_id": {
+                    "type": "integer"
+                },
+                "submission_ts": {
+                    "type": "integer"
+                },
+                "test_files": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/util.FileData"
+                    }
+                },
+                "time_ms": {
+                    "type": "integer"
+                },
+                "ts": {
+                    "type": "integer"
+                },
+                "uploaded_files": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/util.FileData"
+                    }
+                },
+                "user_id": {
+                    "type": "string"
+                },
+                "user_name": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "problem.DetailedTaskLog": {
+            "type": "object",
+            "properties": {
+                "command": {
+                    "type": "string"
+                },
+                "cpu_time_ms": {
+                    "type": "integer"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "exit_code": {
+                    "type": "integer"
+                },
+                "expected_exit_code": {
+                    "type": "integer"
+                },
+                "expected_stderr": {
+                    "description": "base64 encoded, compressed with gzip",
+                    "type": "string"
+                },
+                "expected_stdout": {
+                    "description": "base64 encoded, compressed with gzip",
+                    "type": "string"
+                },
+                "flaky": {
+                    "description": "repeated runs got different verdicts",
+                    "type": "boolean"
+                },
+                "ignore_exit": {
+                    "type": "boolean"
+                },
+                "memcheck": {
+                    "description": "errors found by Valgrind, empty if not a memcheck task",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/problem.MemcheckFinding"
+                    }
+                },
+                "memory_kb": {
+                    "type": "integer"
+                },
+                "message_on_fail": {
+                    "description": "hint of the test case for the failure, empty if the task passed",
+                    "type": "string"
+                },
+                "processes": {
+                    "description": "processes checked by the command, empty if not a multi-process task",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/problem.ProcessDetail"
+                    }
+                },
+                "result_id": {
+                    "type": "integer"
+                },
+                "runs": {
+                    "description": "every run of a repeated task, empty if run only once",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/problem.TaskRunLog"
+                    }
+                },
+                "stderr": {
+                    "description": "base64 encoded, compressed with gzip",
+                    "type": "string"
+                },
+                "stdin": {
+                    "description": "base64 encoded, compressed with gzip",
+                    "type": "string"
+                },
+                "stdout": {
+                    "description": "base64 encoded, compressed with gzip",
+                    "type": "string"
+                },
+                "test_case_id": {
+                    "type": "integer"
+                },
+                "threads": {
+                    "description": "CPU time of each thread of the command",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/problem.ThreadDetail"
+                    }
+                },
+                "time_ms": {
+                    "type": "integer"
+                },
+                "truncated": {
+                    "description": "stdout or stderr is cut off at the output limit or the display limit",
+                    "type": "boolean"
+                }
+            }
+        },
+        "problem.FileDiff": {
+            "type": "object",
+            "properties": {
+                "new": {
+                    "description": "nil if removed",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/util.FileData"
+                        }
+                    ]
+                },
+                "old": {
+                    "description": "nil if added",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/util.FileData"
+                        }
+                    ]
+                },
+                "path": {
+                    "description": "relative to the resource directory",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "\"added\", \"removed\" or \"modified\"",
+                    "type": "string"
+                }
+            }
+        },
+        "problem.FileGroup": {
+            "type": "object",
+            "properties": {
+                "files": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/util.FileData"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "problem.GradingDetailOutput": {
+            "type": "object",
+            "properties": {
+                "detail_list": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/problem.GradingDetailPerProblem"
+                    }
+                },
+                "file_groups": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/problem.FileGroup"
+                    }
+                },
+                "lecture_id": {
+                    "type": "integer"
+                },
+                "lecture_info": {
+                    "$ref": "#/definitions/util.LectureEntry"
+                },
+                "test_files_per_problem": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/problem.TestFilesPerProblem"
+                    }
+                },
+                "user_id": {
+                    "type": "string"
+                },
+                "user_name": {
+                    "type": "string"
+                }
+            }
+        },
+        "problem.GradingDetailPerProblem": {
+            "type": "object",
+            "properties": {
+                "benchmark_logs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/problem.BenchmarkDetail"
+                    }
+                },
+                "build_logs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/problem.DetailedTaskLog"
+                    }
+                },
+                "cached": {
+                    "description": "result reused from an identical job",
+                    "type": "boolean"
+                },
+                "cpu_time_ms": {
+                    "type": "integer"
+                },
+                "file_group_id": {
+                    "type": "integer"
+                },
+                "flaky": {
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "judge_logs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/problem.DetailedTaskLog"
+                    }
+                },
+                "memory_kb": {
+                    "type": "integer"
+                },
+                "mutation_logs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/problem.MutationDetail"
+                    }
+                },
+                "problem_id": {
+                    "type": "integer"
+                },
+                "request_user_id": {
+                    "type": "string"
+                },
+                "request_user_name": {
+                    "type": "string"
+                },
+                "result_id": {
+                    "type": "integer"
+                },
+                "submission_ts": {
+                    "type": "integer"
+                },
+                "time_ms": {
+                    "type": "integer"
+                },
+                "ts": {
+                    "type": "integer"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "problem.GradingHistoryEntry": {
+            "type": "object",
+            "properties": {
+                "archived_at": {
+                    "type": "integer"
+                },
+                "cpu_time_ms": {
+                    "type": "integer"
+                },
+                "memory_kb": {
+                    "type": "integer"
+                },
+                "problem_version": {
+                    "type": "integer"
+                },
+                "rejudge_id": {
+                    "type": "integer"
+                },
+                "result_id": {
+                    "type": "integer"
+                },
+                "time_ms": {
+                    "type": "integer"
+                }
+            }
+        },
+        "problem.GradingListOutput": {
+            "type": "object",
+            "properties": {
+                "detail": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/problem.UserGradingResult"
+                    }
                 },
-                "role": {
-                    "type": "string",
-                    "enum": [
-                        "admin",
-                        "manager",
-                        "student"
-                    ]
+                "lecture_info": {
+                    "$ref": "#/definitions/util.LectureEntry"
                 }
             }
         },
-        "admin.UserInfo": {
+        "problem.GradingResultPerProblem": {
             "type": "object",
             "properties": {
-                "archived": {
+                "cached": {
+                    "description": "result reused from an identical job",
                     "type": "boolean"
                 },
-                "email": {
-                    "type": "string"
+                "cpu_time_ms": {
+                    "type": "integer"
                 },
-                "name": {
-                    "type": "string"
+                "deadline": {
+                    "description": "deadline of the problem the submission is judged against, extended for the student",
+                    "type": "integer"
                 },
-                "role": {
-                    "type": "string"
+                "extended": {
+                    "description": "the deadline is extended for the student",
+                    "type": "boolean"
                 },
-                "user_id": {
-                    "type": "string"
+                "flaky": {
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "late_seconds": {
+                    "description": "time from the deadline to the submission, 0 if submitted in time",
+                    "type": "integer"
+                },
+                "memory_kb": {
+                    "type": "integer"
+                },
+                "penalty": {
+                    "description": "fraction of the score deducted by the late policy, between 0 and 1",
+                    "type": "number"
+                },
+                "problem_id": {
+                    "type": "integer"
+                },
+                "result_id": {
+                    "type": "integer"
+                },
+                "submission_ts": {
+                    "type": "integer"
+                },
+                "time_ms": {
+                    "type": "integer"
                 }
             }
         },
-        "admin.registerUserRequest": {
+        "problem.LectureEntryRequest": {
             "type": "object",
             "required": [
-                "name",
-                "password",
-                "role",
-                "user_id"
+                "deadline",
+                "id",
+                "start_date",
+                "title"
             ],
             "properties": {
-                "email": {
-                    "type": "string"
-                },
-                "name": {
-                    "type": "string",
-                    "maxLength": 30,
-                    "minLength": 1
+                "deadline": {
+                    "type": "integer"
                 },
-                "password": {
-                    "type": "string",
-                    "maxLength": 100,
-                    "minLength": 8
+                "id": {
+                    "type": "integer",
+                    "default": 0
                 },
-                "role": {
-                    "type": "string",
-                    "enum": [
-                        "admin",
-                        "manager",
-                        "student"
+                "late_policy": {
+                    "description": "null if late submissions are not penalized",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/util.LatePolicy"
+                        }
                     ]
                 },
-                "user_id": {
+                "start_date": {
+                    "type": "integer"
+                },
+                "title": {
                     "type": "string"
                 }
             }
         },
-        "problem.DetailOutput": {
+        "problem.LintIssue": {
             "type": "object",
             "properties": {
-                "build_logs": {
+                "message": {
+                    "type": "string"
+                },
+                "path": {
+                    "description": "location in init.json or init.yaml, e.g., \"judge[2].stdout\", or \"\" for the package itself",
+                    "type": "string"
+                }
+            }
+        },
+        "problem.LintOutput": {
+            "type": "object",
+            "properties": {
+                "issues": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/problem.DetailedTaskLog"
+                        "$ref": "#/definitions/problem.LintIssue"
                     }
                 },
-                "id": {
-                    "type": "integer"
+                "title": {
+                    "description": "title in init.json or init.yaml, if it can be read",
+                    "type": "string"
                 },
-                "judge_logs": {
+                "valid": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "problem.ListRequiredFilesResponse": {
+            "type": "object",
+            "properties": {
+                "list": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/problem.DetailedTaskLog"
+                        "$ref": "#/definitions/problem.RequiredFiles"
+                    }
+                }
+            }
+        },
+        "problem.ListingOutput": {
+            "type": "object",
+            "properties": {
+                "lecture_info": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/util.LectureEntry"
                     }
                 },
-                "lecture_id": {
-                    "type": "integer"
-                },
-                "lecture_title": {
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/problem.ValidationResult"
+                    }
+                }
+            }
+        },
+        "problem.MemcheckFinding": {
+            "type": "object",
+            "properties": {
+                "kind": {
+                    "description": "e.g., \"InvalidRead\", \"Leak_DefinitelyLost\"",
                     "type": "string"
                 },
-                "memory_kb": {
-                    "type": "integer"
+                "leak": {
+                    "type": "boolean"
                 },
-                "problem_id": {
+                "leaked_bytes": {
                     "type": "integer"
                 },
-                "problem_title": {
+                "location": {
+                    "description": "e.g., \"list.c:12 (push)\", empty if unknown",
                     "type": "string"
                 },
-                "result_id": {
-                    "type": "integer"
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "problem.MutationDetail": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
                 },
-                "submission_ts": {
-                    "type": "integer"
+                "min_score": {
+                    "description": "0 if any score is accepted",
+                    "type": "number"
                 },
-                "test_files": {
+                "mutants": {
+                    "description": "empty if the test suite failed on the reference implementation",
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/util.FileData"
+                        "$ref": "#/definitions/problem.MutationRun"
                     }
                 },
-                "time_ms": {
-                    "type": "integer"
+                "reference": {
+                    "$ref": "#/definitions/problem.MutationRun"
                 },
-                "ts": {
+                "result_id": {
                     "type": "integer"
                 },
-                "uploaded_files": {
+                "score": {
+                    "description": "fraction of mutants killed",
+                    "type": "number"
+                },
+                "survived": {
+                    "description": "names of mutants not killed",
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/util.FileData"
+                        "type": "string"
                     }
                 },
-                "user_id": {
-                    "type": "string"
+                "task_id": {
+                    "type": "integer"
                 },
-                "user_name": {
+                "title": {
                     "type": "string"
                 }
             }
         },
-        "problem.DetailedTaskLog": {
+        "problem.MutationRun": {
             "type": "object",
             "properties": {
-                "command": {
-                    "type": "string"
-                },
-                "description": {
-                    "type": "string"
-                },
                 "exit_code": {
                     "type": "integer"
                 },
-                "expected_exit_code": {
-                    "type": "integer"
-                },
-                "expected_stderr": {
-                    "description": "base64 encoded, compressed with gzip",
-                    "type": "string"
-                },
-                "expected_stdout": {
-                    "description": "base64 encoded, compressed with gzip",
-                    "type": "string"
-                },
-                "ignore_exit": {
+                "killed": {
                     "type": "boolean"
                 },
                 "memory_kb": {
                     "type": "integer"
                 },
+                "name": {
+                    "type": "string"
+                },
                 "result_id": {
                     "type": "integer"
                 },
@@ -1742,204 +3656,235 @@ const docTemplate = `{
                     "description": "base64 encoded, compressed with gzip",
                     "type": "string"
                 },
-                "stdin": {
-                    "description": "base64 encoded, compressed with gzip",
-                    "type": "string"
-                },
                 "stdout": {
                     "description": "base64 encoded, compressed with gzip",
                     "type": "string"
                 },
-                "test_case_id": {
-                    "type": "integer"
-                },
                 "time_ms": {
                     "type": "integer"
                 }
             }
         },
-        "problem.FileGroup": {
+        "problem.ProblemDeadlineRequest": {
             "type": "object",
             "properties": {
-                "files": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/util.FileData"
-                    }
+                "deadline": {
+                    "description": "unix time, null for the deadline of the lecture",
+                    "type": "integer"
                 },
-                "id": {
+                "late_policy": {
+                    "description": "null for the late policy of the lecture",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/util.LatePolicy"
+                        }
+                    ]
+                },
+                "lectureID": {
+                    "type": "integer"
+                },
+                "problemID": {
                     "type": "integer"
                 }
             }
         },
-        "problem.GradingDetailOutput": {
+        "problem.ProblemVersionOutput": {
             "type": "object",
             "properties": {
-                "detail_list": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/problem.GradingDetailPerProblem"
-                    }
-                },
-                "file_groups": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/problem.FileGroup"
-                    }
+                "current": {
+                    "description": "whether the version is used for new submissions",
+                    "type": "boolean"
                 },
-                "lecture_id": {
+                "registered_at": {
                     "type": "integer"
                 },
-                "lecture_info": {
-                    "$ref": "#/definitions/util.LectureEntry"
-                },
-                "test_files_per_problem": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/problem.TestFilesPerProblem"
-                    }
-                },
-                "user_id": {
+                "status": {
+                    "description": "\"unverified\", \"verifying\", \"verified\", \"broken\", \"generating\" or \"review\"",
                     "type": "string"
                 },
-                "user_name": {
+                "title": {
                     "type": "string"
+                },
+                "version": {
+                    "type": "integer"
                 }
             }
         },
-        "problem.GradingDetailPerProblem": {
-            "type": "object",
-            "properties": {
-                "build_logs": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/problem.DetailedTaskLog"
-                    }
+        "problem.ProcessDetail": {
+            "type": "object",
+            "properties": {
+                "background": {
+                    "type": "boolean"
                 },
-                "file_group_id": {
-                    "type": "integer"
+                "command": {
+                    "type": "string"
                 },
-                "id": {
+                "cpu_time_ms": {
                     "type": "integer"
                 },
-                "judge_logs": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/problem.DetailedTaskLog"
-                    }
-                },
-                "memory_kb": {
+                "exit_code": {
                     "type": "integer"
                 },
-                "problem_id": {
+                "memory_kb": {
+                    "description": "of the whole container, including other processes",
                     "type": "integer"
                 },
-                "request_user_id": {
+                "name": {
                     "type": "string"
                 },
-                "request_user_name": {
-                    "type": "string"
+                "ready": {
+                    "description": "the readiness check passed, or there is none",
+                    "type": "boolean"
                 },
                 "result_id": {
                     "type": "integer"
                 },
-                "submission_ts": {
-                    "type": "integer"
+                "stderr": {
+                    "description": "base64 encoded, compressed with gzip",
+                    "type": "string"
+                },
+                "stdout": {
+                    "description": "base64 encoded, compressed with gzip",
+                    "type": "string"
+                },
+                "threads": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/problem.ThreadDetail"
+                    }
                 },
                 "time_ms": {
                     "type": "integer"
                 },
-                "ts": {
-                    "type": "integer"
+                "truncated": {
+                    "description": "stdout or stderr is cut off at the output limit or the display limit",
+                    "type": "boolean"
                 }
             }
         },
-        "problem.GradingListOutput": {
+        "problem.RejudgeOutput": {
             "type": "object",
             "properties": {
-                "detail": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/problem.UserGradingResult"
-                    }
+                "id": {
+                    "type": "integer"
                 },
-                "lecture_info": {
-                    "$ref": "#/definitions/util.LectureEntry"
+                "total": {
+                    "description": "number of grading requests rejudged",
+                    "type": "integer"
                 }
             }
         },
-        "problem.GradingResultPerProblem": {
+        "problem.RejudgeProgressOutput": {
             "type": "object",
             "properties": {
+                "done": {
+                    "description": "number of grading requests judged again",
+                    "type": "integer"
+                },
                 "id": {
                     "type": "integer"
                 },
-                "memory_kb": {
+                "lecture_id": {
                     "type": "integer"
                 },
                 "problem_id": {
+                    "description": "null for all problems of the lecture",
                     "type": "integer"
                 },
-                "result_id": {
+                "request_id": {
+                    "description": "null for all grading requests of the problems",
                     "type": "integer"
                 },
-                "submission_ts": {
+                "request_user_id": {
+                    "type": "string"
+                },
+                "requests": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/problem.RejudgedRequest"
+                    }
+                },
+                "results": {
+                    "description": "verdicts rejudged, empty for all",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "total": {
                     "type": "integer"
                 },
-                "time_ms": {
+                "ts": {
                     "type": "integer"
                 }
             }
         },
-        "problem.LectureEntryRequest": {
+        "problem.RejudgeSummary": {
             "type": "object",
-            "required": [
-                "deadline",
-                "id",
-                "start_date",
-                "title"
-            ],
             "properties": {
-                "deadline": {
+                "done": {
+                    "description": "number of grading requests judged again",
                     "type": "integer"
                 },
                 "id": {
-                    "type": "integer",
-                    "default": 0
+                    "type": "integer"
                 },
-                "start_date": {
+                "lecture_id": {
                     "type": "integer"
                 },
-                "title": {
+                "problem_id": {
+                    "description": "null for all problems of the lecture",
+                    "type": "integer"
+                },
+                "request_id": {
+                    "description": "null for all grading requests of the problems",
+                    "type": "integer"
+                },
+                "request_user_id": {
                     "type": "string"
-                }
-            }
-        },
-        "problem.ListRequiredFilesResponse": {
-            "type": "object",
-            "properties": {
-                "list": {
+                },
+                "results": {
+                    "description": "verdicts rejudged, empty for all",
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/problem.RequiredFiles"
+                        "type": "string"
                     }
+                },
+                "total": {
+                    "type": "integer"
+                },
+                "ts": {
+                    "type": "integer"
                 }
             }
         },
-        "problem.ListingOutput": {
+        "problem.RejudgedRequest": {
             "type": "object",
             "properties": {
-                "lecture_info": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/util.LectureEntry"
-                    }
+                "previous_result_id": {
+                    "type": "integer"
                 },
-                "results": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/problem.ValidationResult"
-                    }
+                "previous_version": {
+                    "type": "integer"
+                },
+                "problem_id": {
+                    "type": "integer"
+                },
+                "request_id": {
+                    "type": "integer"
+                },
+                "result_id": {
+                    "description": "current result, WJ or Judging while being judged",
+                    "type": "integer"
+                },
+                "submission_ts": {
+                    "type": "integer"
+                },
+                "user_id": {
+                    "type": "string"
+                },
+                "user_name": {
+                    "type": "string"
                 }
             }
         },
@@ -1960,6 +3905,26 @@ const docTemplate = `{
                 }
             }
         },
+        "problem.TaskRunLog": {
+            "type": "object",
+            "properties": {
+                "cpu_time_ms": {
+                    "type": "integer"
+                },
+                "exit_code": {
+                    "type": "integer"
+                },
+                "memory_kb": {
+                    "type": "integer"
+                },
+                "result_id": {
+                    "type": "integer"
+                },
+                "time_ms": {
+                    "type": "integer"
+                }
+            }
+        },
         "problem.TestFilesPerProblem": {
             "type": "object",
             "properties": {
@@ -1974,6 +3939,21 @@ const docTemplate = `{
                 }
             }
         },
+        "problem.ThreadDetail": {
+            "type": "object",
+            "properties": {
+                "cpu_time_ms": {
+                    "type": "integer"
+                },
+                "name": {
+                    "description": "name of the program running the thread",
+                    "type": "string"
+                },
+                "tid": {
+                    "type": "integer"
+                }
+            }
+        },
         "problem.UserGradingResult": {
             "type": "object",
             "properties": {
@@ -1994,6 +3974,9 @@ const docTemplate = `{
         "problem.ValidationResult": {
             "type": "object",
             "properties": {
+                "cpu_time_ms": {
+                    "type": "integer"
+                },
                 "id": {
                     "type": "integer"
                 },
@@ -2023,6 +4006,75 @@ const docTemplate = `{
                 }
             }
         },
+        "problem.VerificationOutput": {
+            "type": "object",
+            "properties": {
+                "failed_build_logs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/problem.DetailedTaskLog"
+                    }
+                },
+                "failed_judge_logs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/problem.DetailedTaskLog"
+                    }
+                },
+                "generated_files": {
+                    "description": "expected outputs generated from the reference solution, to be reviewed",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/util.FileData"
+                    }
+                },
+                "lecture_id": {
+                    "type": "integer"
+                },
+                "problem_id": {
+                    "type": "integer"
+                },
+                "result_id": {
+                    "type": "integer"
+                },
+                "status": {
+                    "description": "\"unverified\", \"verifying\", \"verified\", \"broken\", \"generating\" or \"review\"",
+                    "type": "string"
+                },
+                "ts": {
+                    "type": "integer"
+                },
+                "verification_id": {
+                    "description": "0 if the problem has never been verified",
+                    "type": "integer"
+                },
+                "version": {
+                    "description": "latest version of the problem, which may not be current until it is verified",
+                    "type": "integer"
+                }
+            }
+        },
+        "problem.VersionDiffOutput": {
+            "type": "object",
+            "properties": {
+                "detail_changed": {
+                    "description": "whether the settings in init.json differ",
+                    "type": "boolean"
+                },
+                "files": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/problem.FileDiff"
+                    }
+                },
+                "from": {
+                    "type": "integer"
+                },
+                "to": {
+                    "type": "integer"
+                }
+            }
+        },
         "response.Error": {
             "type": "object",
             "properties": {
@@ -2107,12 +4159,41 @@ const docTemplate = `{
                 }
             }
         },
+        "util.LatePolicy": {
+            "type": "object",
+            "properties": {
+                "grace_minutes": {
+                    "description": "submissions within this period after the deadline are not penalized",
+                    "type": "integer"
+                },
+                "kind": {
+                    "type": "string",
+                    "enum": [
+                        "hard",
+                        "linear",
+                        "stepped"
+                    ]
+                },
+                "penalty_per_day": {
+                    "description": "fraction of the score deducted per day, e.g., 0.1 (linear and stepped)",
+                    "type": "number"
+                }
+            }
+        },
         "util.LectureEntry": {
             "type": "object",
             "properties": {
                 "deadline": {
                     "type": "integer"
                 },
+                "late_policy": {
+                    "description": "null if late submissions are not penalized",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/util.LatePolicy"
+                        }
+                    ]
+                },
                 "lecture_id": {
                     "type": "integer"
                 },
@@ -2133,6 +4214,12 @@ const docTemplate = `{
         "util.ProblemDetail": {
             "type": "object",
             "properties": {
+                "cpu_cores": {
+                    "type": "integer"
+                },
+                "cpu_time_ms": {
+                    "type": "integer"
+                },
                 "description": {
                     "type": "string"
                 },
@@ -2168,17 +4255,53 @@ const docTemplate = `{
         "util.ProblemEntry": {
             "type": "object",
             "properties": {
+                "deadline": {
+                    "description": "deadline of the problem, or of the lecture if not set, extended for the student",
+                    "type": "integer"
+                },
+                "extended": {
+                    "description": "the deadline is extended for the student",
+                    "type": "boolean"
+                },
+                "late_policy": {
+                    "description": "late policy of the problem, or of the lecture if not set",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/util.LatePolicy"
+                        }
+                    ]
+                },
                 "lecture_id": {
                     "type": "integer"
                 },
+                "problem_deadline": {
+                    "description": "deadline set on the problem itself, null if not set",
+                    "type": "integer"
+                },
                 "problem_id": {
                     "type": "integer"
                 },
+                "problem_late_policy": {
+                    "description": "late policy set on the problem itself, null if not set",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/util.LatePolicy"
+                        }
+                    ]
+                },
                 "registered_at": {
                     "type": "integer"
                 },
+                "status": {
+                    "description": "verification status of the reference solution",
+                    "type": "string"
+                },
                 "title": {
                     "type": "string"
+                },
+                "version": {
+                    "description": "current version",
+                    "type": "integer"
                 }
             }
         }
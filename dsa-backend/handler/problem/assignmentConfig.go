@@ -4,18 +4,40 @@ import (
 	"dsa-backend/fileutil"
 	"encoding/json"
 	"errors"
+	"regexp"
+
+	"github.com/dsa-uts/dsa-project/database/model"
+	"github.com/dsa-uts/dsa-project/database/model/leakpolicy"
 )
 
+// Environment variable names allowed in test cases
+var envNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Process names allowed in multi-process test cases, which are used in file names
+var processNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
 type AssignmentConfig struct {
-	SubID         int        `json:"sub_id"`
-	Title         string     `json:"title"`
-	MDfile        string     `json:"md_file"`
-	TimeMS        *int64     `json:"time_ms,omitempty"`
-	MemoryMB      *int64     `json:"memory_mb,omitempty"`
-	TestFiles     []string   `json:"test_files"`
-	RequiredFiles []string   `json:"required_files"`
-	Build         []TestCase `json:"build"`
-	Judge         []TestCase `json:"judge"`
+	SubID          int        `json:"sub_id"`
+	Title          string     `json:"title"`
+	MDfile         string     `json:"md_file"`
+	TimeMS         *int64     `json:"time_ms,omitempty"`
+	CPUTimeMS      *int64     `json:"cpu_time_ms,omitempty"`
+	CPUCores       *int64     `json:"cpu_cores,omitempty"`
+	MemoryMB       *int64     `json:"memory_mb,omitempty"`
+	OutputLimitKB  *int64     `json:"output_limit_kb,omitempty"`
+	DisplayLimitKB *int64     `json:"display_limit_kb,omitempty"`
+	TestFiles      []string   `json:"test_files"`
+	RequiredFiles  []string   `json:"required_files"`
+	Build          []TestCase `json:"build"`
+	Judge          []TestCase `json:"judge"`
+
+	Benchmark []BenchmarkConfig `json:"benchmark,omitempty"`
+	Mutation  []MutationConfig  `json:"mutation,omitempty"`
+
+	ReferenceSolution string `json:"reference_solution,omitempty"`
+	GenerateOutputs   bool   `json:"generate_outputs,omitempty"`
+
+	LeakPolicy *string `json:"leak_policy,omitempty"`
 }
 
 type TestCase struct {
@@ -28,17 +50,61 @@ type TestCase struct {
 	Stdout        string `json:"stdout,omitempty"`
 	Stderr        string `json:"stderr,omitempty"`
 	ExitCode      *int64 `json:"exit,omitempty"`
+
+	Args     []string          `json:"args,omitempty"`
+	Env      map[string]string `json:"env,omitempty"`
+	Fixtures string            `json:"fixtures,omitempty"`
+	Repeat   int64             `json:"repeat,omitempty"`
+	Memcheck bool              `json:"memcheck,omitempty"`
+
+	Processes []ProcessConfig `json:"processes,omitempty"`
+}
+
+type ProcessConfig struct {
+	Name       string `json:"name"`
+	Command    string `json:"command"`
+	Stdin      string `json:"stdin,omitempty"`
+	Ready      string `json:"ready,omitempty"`
+	Background bool   `json:"background,omitempty"`
+}
+
+type BenchmarkConfig struct {
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	Command     string   `json:"command"`
+	Generator   string   `json:"generator"`
+	Sizes       []int64  `json:"sizes"`
+	TimeMS      *int64   `json:"time_ms,omitempty"`
+	Expected    []string `json:"expected,omitempty"`
+}
+
+type MutationConfig struct {
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	Command     string   `json:"command"`
+	Reference   string   `json:"reference"`
+	Mutants     []string `json:"mutants"`
+	TimeMS      *int64   `json:"time_ms,omitempty"`
+	MinScore    float64  `json:"min_score,omitempty"`
 }
 
 func (ac *AssignmentConfig) Decode(data []byte) error {
 	if err := json.Unmarshal(data, ac); err != nil {
 		return errors.New("Failed to parse assignment config: " + err.Error())
 	}
+	ac.normalize()
+	return nil
+}
 
+// normalize fills in default values, and cleans all paths.
+func (ac *AssignmentConfig) normalize() {
 	ac.setDefaults()
 
 	// Clean all path to avoid path traversal attack
 	ac.MDfile = fileutil.SanitizeRelPath(ac.MDfile)
+	if ac.ReferenceSolution != "" {
+		ac.ReferenceSolution = fileutil.SanitizeRelPath(ac.ReferenceSolution)
+	}
 	for i := range ac.TestFiles {
 		ac.TestFiles[i] = fileutil.SanitizeRelPath(ac.TestFiles[i])
 	}
@@ -65,9 +131,22 @@ func (ac *AssignmentConfig) Decode(data []byte) error {
 		if ac.Judge[i].Stderr != "" {
 			ac.Judge[i].Stderr = fileutil.SanitizeRelPath(ac.Judge[i].Stderr)
 		}
+		if ac.Judge[i].Fixtures != "" {
+			ac.Judge[i].Fixtures = fileutil.SanitizeRelPath(ac.Judge[i].Fixtures)
+		}
+		for j := range ac.Judge[i].Processes {
+			if ac.Judge[i].Processes[j].Stdin != "" {
+				ac.Judge[i].Processes[j].Stdin = fileutil.SanitizeRelPath(ac.Judge[i].Processes[j].Stdin)
+			}
+		}
 	}
 
-	return nil
+	for i := range ac.Mutation {
+		ac.Mutation[i].Reference = fileutil.SanitizeRelPath(ac.Mutation[i].Reference)
+		for j := range ac.Mutation[i].Mutants {
+			ac.Mutation[i].Mutants[j] = fileutil.SanitizeRelPath(ac.Mutation[i].Mutants[j])
+		}
+	}
 }
 
 func (conf *AssignmentConfig) setDefaults() {
@@ -79,6 +158,24 @@ func (conf *AssignmentConfig) setDefaults() {
 		defaultMemory := int64(256) // Default memory in MB
 		conf.MemoryMB = &defaultMemory
 	}
+	if conf.CPUCores == nil {
+		defaultCores := int64(1) // Single-threaded programs need only one core
+		conf.CPUCores = &defaultCores
+	}
+
+	if conf.OutputLimitKB == nil {
+		defaultOutputLimit := int64(DEFAULT_OUTPUT_LIMIT_KB)
+		conf.OutputLimitKB = &defaultOutputLimit
+	}
+	if conf.DisplayLimitKB == nil {
+		defaultDisplayLimit := min(int64(DEFAULT_DISPLAY_LIMIT_KB), *conf.OutputLimitKB)
+		conf.DisplayLimitKB = &defaultDisplayLimit
+	}
+
+	if conf.LeakPolicy == nil {
+		defaultLeakPolicy := string(leakpolicy.Fail)
+		conf.LeakPolicy = &defaultLeakPolicy
+	}
 
 	for i := range conf.Build {
 		conf.Build[i].setDefaults()
@@ -87,6 +184,22 @@ func (conf *AssignmentConfig) setDefaults() {
 	for i := range conf.Judge {
 		conf.Judge[i].setDefaults()
 	}
+
+	for i := range conf.Benchmark {
+		if conf.Benchmark[i].TimeMS == nil {
+			// Default to the time limit of the problem
+			defaultTime := *conf.TimeMS
+			conf.Benchmark[i].TimeMS = &defaultTime
+		}
+	}
+
+	for i := range conf.Mutation {
+		if conf.Mutation[i].TimeMS == nil {
+			// Default to the time limit of the problem
+			defaultTime := *conf.TimeMS
+			conf.Mutation[i].TimeMS = &defaultTime
+		}
+	}
 }
 
 func (t *TestCase) setDefaults() {
@@ -98,3 +211,118 @@ func (t *TestCase) setDefaults() {
 		t.MessageOnFail = "failed to execute " + t.Title
 	}
 }
+
+// makeAssignmentConfig restores the config of a registered problem from its detail, with all default values filled in,
+// so that the problem is registered with the same detail from the restored config.
+func makeAssignmentConfig(problemID int64, title string, detail model.Detail) AssignmentConfig {
+	timeMS := detail.TimeMS
+	memoryMB := detail.MemoryMB
+	cpuCores := max(detail.CPUCores, 1)
+	outputLimitKB := detail.OutputLimitKB
+	if outputLimitKB <= 0 {
+		outputLimitKB = DEFAULT_OUTPUT_LIMIT_KB
+	}
+	displayLimitKB := detail.DisplayLimitKB
+	if displayLimitKB <= 0 {
+		displayLimitKB = min(DEFAULT_DISPLAY_LIMIT_KB, outputLimitKB)
+	}
+	leakPolicy := string(detail.LeakPolicy)
+	if leakPolicy == "" {
+		leakPolicy = string(leakpolicy.Fail)
+	}
+
+	config := AssignmentConfig{
+		SubID:             int(problemID),
+		Title:             title,
+		MDfile:            detail.DescriptionPath,
+		TimeMS:            &timeMS,
+		CPUCores:          &cpuCores,
+		MemoryMB:          &memoryMB,
+		OutputLimitKB:     &outputLimitKB,
+		DisplayLimitKB:    &displayLimitKB,
+		TestFiles:         append([]string{}, detail.TestFiles...),
+		RequiredFiles:     append([]string{}, detail.RequiredFiles...),
+		Build:             []TestCase{},
+		Judge:             []TestCase{},
+		ReferenceSolution: detail.ReferenceSolutionPath,
+		LeakPolicy:        &leakPolicy,
+	}
+	if detail.CPUTimeMS > 0 {
+		cpuTimeMS := detail.CPUTimeMS
+		config.CPUTimeMS = &cpuTimeMS
+	}
+
+	for _, t := range detail.BuildTasks {
+		config.Build = append(config.Build, makeTestCaseConfig(t))
+	}
+	for _, t := range detail.JudgeTasks {
+		config.Judge = append(config.Judge, makeTestCaseConfig(t))
+	}
+
+	for _, b := range detail.BenchmarkTasks {
+		benchmarkTimeMS := b.TimeMS
+		expected := []string{}
+		for _, class := range b.Expected {
+			expected = append(expected, string(class))
+		}
+		config.Benchmark = append(config.Benchmark, BenchmarkConfig{
+			Title:       b.Title,
+			Description: b.Description,
+			Command:     b.Command,
+			Generator:   b.Generator,
+			Sizes:       b.Sizes,
+			TimeMS:      &benchmarkTimeMS,
+			Expected:    expected,
+		})
+	}
+
+	for _, m := range detail.MutationTasks {
+		mutationTimeMS := m.TimeMS
+		config.Mutation = append(config.Mutation, MutationConfig{
+			Title:       m.Title,
+			Description: m.Description,
+			Command:     m.Command,
+			Reference:   m.ReferencePath,
+			Mutants:     m.MutantPaths,
+			TimeMS:      &mutationTimeMS,
+			MinScore:    m.MinScore,
+		})
+	}
+
+	return config
+}
+
+func makeTestCaseConfig(t model.TestCase) TestCase {
+	evalOnly := t.Evaluation
+	config := TestCase{
+		EvalOnly:      &evalOnly,
+		Title:         t.Title,
+		Description:   t.Description,
+		MessageOnFail: t.MessageOnFail,
+		Command:       t.Command,
+		Stdin:         t.StdinPath,
+		Stdout:        t.StdoutPath,
+		Stderr:        t.StderrPath,
+		Args:          t.Args,
+		Env:           t.Env,
+		Fixtures:      t.FixturesPath,
+		Repeat:        t.Repeat,
+		Memcheck:      t.Memcheck,
+	}
+	// Versions registered before the message was stored get the default one
+	config.setDefaults()
+	if !t.IgnoreExit {
+		exitCode := t.ExitCode
+		config.ExitCode = &exitCode
+	}
+	for _, p := range t.Processes {
+		config.Processes = append(config.Processes, ProcessConfig{
+			Name:       p.Name,
+			Command:    p.Command,
+			Stdin:      p.StdinPath,
+			Ready:      p.Ready,
+			Background: p.Background,
+		})
+	}
+	return config
+}
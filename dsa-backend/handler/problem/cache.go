@@ -0,0 +1,123 @@
+package problem
+
+import (
+	"context"
+	"dsa-backend/handler/problem/util"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/dsa-uts/dsa-project/database/model"
+	"github.com/dsa-uts/dsa-project/database/model/problemstatus"
+)
+
+// lookupResultCache computes the cache key of the job and stores it in the job, so that its result
+// is cached when it finishes. Returns the result of an identical job run with the current sandbox images,
+// marked as cached, or nil if there is none. Failures of the cache are not errors, the job is simply run.
+// The job must be run with the given version of the problem.
+//
+// Output files of the cached result belong to another request, which may be of another student,
+// so they are copied into the result directory of the job, and the log refers to the copies.
+func (h *Handler) lookupResultCache(ctx context.Context, job *model.JobQueue, lectureID, problemID, version int64) *model.ResultCache {
+	resourceDigest, err := h.resourceDigest(ctx, lectureID, problemID, version)
+	if err != nil {
+		return nil
+	}
+
+	cacheKey, err := util.ComputeCacheKey(job.Detail, resourceDigest)
+	if err != nil {
+		return nil
+	}
+	job.Detail.CacheKey = cacheKey
+
+	cached, err := h.resultCacheStore.GetResultCache(ctx, cacheKey)
+	if err != nil {
+		return nil
+	}
+
+	// The outputs may have been cleaned up, then the job is run again
+	if err := copyCachedOutputs(&cached.Log, job.Detail.ResultDir); err != nil {
+		return nil
+	}
+
+	cached.Log.Cached = true
+	return cached
+}
+
+// resourceDigest returns the hash of the resource files of a version of the problem,
+// which is computed once and recorded with the version.
+// Versions being verified have none, since expected outputs may still be generated into their resource files.
+func (h *Handler) resourceDigest(ctx context.Context, lectureID, problemID, version int64) (string, error) {
+	problemVersion, err := h.problemStore.GetProblemVersion(ctx, lectureID, problemID, version)
+	if err != nil {
+		return "", err
+	}
+	if problemVersion.ResourceDigest != "" {
+		return problemVersion.ResourceDigest, nil
+	}
+	if problemVersion.Status == problemstatus.Verifying || problemVersion.Status == problemstatus.Generating {
+		return "", errors.New("resource files are being verified")
+	}
+	if problemVersion.ResourceLocation == nil {
+		return "", errors.New("resource location not found")
+	}
+
+	digest, err := util.HashDirectory(problemVersion.ResourceLocation.Path)
+	if err != nil {
+		return "", err
+	}
+	if err := h.problemStore.SetResourceDigest(ctx, lectureID, problemID, version, digest); err != nil {
+		return "", err
+	}
+	return digest, nil
+}
+
+// Copies the output files referred to by the log into resultDir, and rewrites the paths in the log to the copies.
+// Outputs of a job are stored flat in its result directory, so their names do not collide.
+func copyCachedOutputs(log *model.RequestLog, resultDir string) error {
+	if err := os.MkdirAll(resultDir, 0755); err != nil {
+		return fmt.Errorf("failed to create result directory %s: %w", resultDir, err)
+	}
+
+	// Tasks refer to the outputs of their worst runs as well
+	copied := make(map[string]string)
+	for _, outputPath := range log.OutputPaths() {
+		if *outputPath == "" {
+			continue
+		}
+		if dest, exists := copied[*outputPath]; exists {
+			*outputPath = dest
+			continue
+		}
+
+		dest := filepath.Join(resultDir, filepath.Base(*outputPath))
+		if dest != *outputPath {
+			if err := copyFile(*outputPath, dest); err != nil {
+				return err
+			}
+		}
+		copied[*outputPath] = dest
+		*outputPath = dest
+	}
+	return nil
+}
+
+func copyFile(src, dest string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", src, err)
+	}
+	defer in.Close()
+
+	out, err := os.Create(dest)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", dest, err)
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return fmt.Errorf("failed to copy %s: %w", src, err)
+	}
+	return out.Close()
+}
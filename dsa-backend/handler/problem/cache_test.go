@@ -0,0 +1,77 @@
+package problem
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dsa-uts/dsa-project/database/model"
+)
+
+func TestCopyCachedOutputs(t *testing.T) {
+	cachedDir := t.TempDir()
+	resultDir := filepath.Join(t.TempDir(), "result")
+
+	files := map[string]string{
+		"judge_1_run1_stdout.txt":   "first",
+		"judge_1_run2_stdout.txt":   "second",
+		"judge_1_server_stdout.txt": "server",
+		"mutation_1_stdout.txt":     "mutation",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(cachedDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cached := func(name string) string { return filepath.Join(cachedDir, name) }
+
+	log := model.RequestLog{
+		JudgeResults: []model.TaskLog{{
+			// The task refers to the output of its worst run
+			StdoutPath: cached("judge_1_run2_stdout.txt"),
+			Runs: []model.RunLog{
+				{StdoutPath: cached("judge_1_run1_stdout.txt")},
+				{StdoutPath: cached("judge_1_run2_stdout.txt")},
+			},
+			Processes: []model.ProcessLog{{StdoutPath: cached("judge_1_server_stdout.txt")}},
+		}},
+		MutationResults: []model.MutationLog{{
+			Reference: model.MutationRun{StdoutPath: cached("mutation_1_stdout.txt")},
+		}},
+	}
+
+	if err := copyCachedOutputs(&log, resultDir); err != nil {
+		t.Fatalf("copyCachedOutputs() error = %v", err)
+	}
+
+	for _, outputPath := range log.OutputPaths() {
+		if *outputPath == "" {
+			continue
+		}
+		if filepath.Dir(*outputPath) != resultDir {
+			t.Errorf("path %s is not in the result directory %s", *outputPath, resultDir)
+			continue
+		}
+		data, err := os.ReadFile(*outputPath)
+		if err != nil {
+			t.Errorf("failed to read %s: %v", *outputPath, err)
+			continue
+		}
+		if want := files[filepath.Base(*outputPath)]; string(data) != want {
+			t.Errorf("content of %s = %q, want %q", *outputPath, data, want)
+		}
+	}
+	if got := log.JudgeResults[0].StdoutPath; got != log.JudgeResults[0].Runs[1].StdoutPath {
+		t.Errorf("task output %s differs from the output of its worst run %s", got, log.JudgeResults[0].Runs[1].StdoutPath)
+	}
+}
+
+func TestCopyCachedOutputsMissing(t *testing.T) {
+	// Outputs of the cached result may have been cleaned up, then the cache is not used
+	log := model.RequestLog{
+		JudgeResults: []model.TaskLog{{StdoutPath: filepath.Join(t.TempDir(), "judge_1_stdout.txt")}},
+	}
+	if err := copyCachedOutputs(&log, t.TempDir()); err == nil {
+		t.Error("copyCachedOutputs() with a missing output succeeded")
+	}
+}
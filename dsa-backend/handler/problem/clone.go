@@ -0,0 +1,169 @@
+package problem
+
+import (
+	"context"
+	"dsa-backend/fileutil"
+	"dsa-backend/handler/response"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/dsa-uts/dsa-project/database/model"
+	"github.com/dsa-uts/dsa-project/database/model/problemstatus"
+	"github.com/labstack/echo/v4"
+	"github.com/spf13/afero"
+)
+
+type CloneLectureRequest struct {
+	SourceID  int64  `param:"lectureid"`
+	ID        int64  `json:"id" validate:"required"`
+	Title     string `json:"title"` // the title of the source lecture if empty
+	StartDate int64  `json:"start_date" validate:"required"`
+	Deadline  int64  `json:"deadline" validate:"required"`
+}
+
+// CloneLecture godoc
+//
+//	@Summary		Clone a lecture with all its problems
+//	@Description	Create a new lecture, e.g., for a new term, with copies of all problems of an existing lecture.
+//	@Description	The resource files and the settings of the current version of each problem are copied, and registered as its first version. Submissions are not copied.
+//	@Description	Late policies are copied, and deadlines of problems are moved by as much as the deadline of the lecture.
+//	@Tags			Update
+//	@Accept			json
+//	@Produce		json
+//	@Param			lectureid		path		int					true	"Lecture ID to clone"
+//	@Param			lectureEntry	body		CloneLectureRequest	true	"New lecture entry details"
+//	@Success		200				{object}	response.Success	"Lecture cloned successfully"
+//	@Failure		400				{object}	response.Error		"Invalid request"
+//	@Failure		404				{object}	response.Error		"Lecture not found"
+//	@Failure		409				{object}	response.Error		"Lecture entry already exists, or a problem is being verified"
+//	@Failure		500				{object}	response.Error		"Internal server error"
+//	@Security		OAuth2Password[grading]
+//	@Router			/problem/crud/clone/{lectureid} [post]
+func (h *Handler) CloneLecture(c echo.Context) error {
+	var req CloneLectureRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, response.NewError("invalid request: "+err.Error()))
+	}
+	if err := c.Validate(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, response.NewError("invalid request: "+err.Error()))
+	}
+
+	ctx := context.Background()
+
+	source, err := h.problemStore.GetLectureAndAllProblems(ctx, req.SourceID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, response.NewError("lecture not found"))
+	}
+
+	if _, err := h.problemStore.GetLectureByID(ctx, req.ID); err == nil {
+		return echo.NewHTTPError(http.StatusConflict, response.NewError("lecture entry already exists"))
+	}
+
+	// Expected outputs or verification results would never arrive to the copies
+	for _, problem := range source.Problems {
+		if problem.Status == problemstatus.Verifying || problem.Status == problemstatus.Generating {
+			return echo.NewHTTPError(http.StatusConflict, response.NewError("problem is being verified, try again later: "+problem.Title))
+		}
+	}
+
+	title := req.Title
+	if title == "" {
+		title = source.Title
+	}
+
+	// ---------------------------------------------------------------------------
+	// destDir: upload/resource/{lectureID}/{problemID}/{YYYY-MM-DD-HH-mm-ss}/
+	// ---------------------------------------------------------------------------
+	timestamp := time.Now().Format("2006-01-02T15-04-05")
+	lectureIDstr := strconv.FormatInt(req.ID, 10)
+
+	// Remove the copied resource files unless the clone is registered
+	copiedDirs := []string{}
+	registered := false
+	defer func() {
+		if !registered {
+			for _, dir := range copiedDirs {
+				os.RemoveAll(dir)
+			}
+		}
+	}()
+
+	osFs := afero.NewOsFs()
+	problems := make([]*model.Problem, 0, len(source.Problems))
+	locations := make([]*model.FileLocation, 0, len(source.Problems))
+	for _, problem := range source.Problems {
+		srcDir, err := h.problemStore.FetchResourcePath(ctx, problem.LectureID, problem.ProblemID)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("failed to get resource path: "+err.Error()))
+		}
+		absSrcDir, err := filepath.Abs(srcDir)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("failed to get absolute path of resource directory: "+err.Error()))
+		}
+
+		destDir := filepath.Join(RESOURCE_DIR, lectureIDstr, strconv.FormatInt(problem.ProblemID, 10), timestamp)
+		absDestDir, err := filepath.Abs(destDir)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("failed to get absolute path of destination directory: "+err.Error()))
+		}
+		if _, err := os.Stat(destDir); err == nil {
+			return echo.NewHTTPError(http.StatusConflict, response.NewError("destination directory already exists: "+destDir))
+		} else if !os.IsNotExist(err) {
+			return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("failed to check destination directory: "+err.Error()))
+		}
+		if err := os.MkdirAll(absDestDir, 0755); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("failed to create destination directory: "+err.Error()))
+		}
+		copiedDirs = append(copiedDirs, absDestDir)
+
+		srcFs := afero.NewBasePathFs(osFs, absSrcDir)
+		jailedFs := afero.NewBasePathFs(osFs, absDestDir)
+		if err := fileutil.CopyContentsBetweenAferoFs(srcFs, "/", jailedFs, "/"); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("failed to copy resource files: "+err.Error()))
+		}
+
+		// Problems keep their deadlines relative to the one of the lecture
+		var deadline *time.Time
+		if problem.Deadline != nil {
+			moved := problem.Deadline.Add(time.Unix(req.Deadline, 0).Sub(source.Deadline))
+			deadline = &moved
+		}
+
+		problems = append(problems, &model.Problem{
+			ProblemID:    problem.ProblemID,
+			RegisteredAt: time.Now(),
+			Title:        problem.Title,
+			Detail:       problem.Detail,
+			Status:       problem.Status,
+			Deadline:     deadline,
+			LatePolicy:   problem.LatePolicy,
+		})
+		locations = append(locations, &model.FileLocation{
+			Path: destDir,
+			Ts:   time.Now(),
+		})
+	}
+
+	lecture := &model.Lecture{
+		ID:         req.ID,
+		Title:      title,
+		StartDate:  time.Unix(req.StartDate, 0),
+		Deadline:   time.Unix(req.Deadline, 0),
+		LatePolicy: source.LatePolicy,
+	}
+	if err := h.problemStore.CreateLectureWithProblems(ctx, lecture, problems, locations); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("failed to clone lecture: "+err.Error()))
+	}
+	registered = true
+
+	for i, problem := range problems {
+		if err := h.registerFileReferences(ctx, problem.LectureID, problem.ProblemID, locations[i].Path, problem.Detail.DescriptionPath); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("failed to register files referenced from the description: "+err.Error()))
+		}
+	}
+
+	return c.JSON(http.StatusOK, response.NewSuccess("Lecture cloned successfully"))
+}
@@ -1,8 +1,48 @@
 package problem
 
 const (
-	UPLOAD_DIR     = "upload"
-	RESOURCE_DIR   = "upload/resource"
-	VALIDATION_DIR = "upload/validation"
-	GRADING_DIR    = "upload/grading"
+	UPLOAD_DIR       = "upload"
+	RESOURCE_DIR     = "upload/resource"
+	VALIDATION_DIR   = "upload/validation"
+	GRADING_DIR      = "upload/grading"
+	VERIFICATION_DIR = "upload/verification"
+)
+
+const (
+	SCHEMA_PATH = "resource/schema.json" // schema of init.json, published in the repository root
+)
+
+const (
+	CONFIG_FILE_JSON = "init.json"
+	CONFIG_FILE_YAML = "init.yaml" // same keys as init.json, for multi-line descriptions and commands
+)
+
+const (
+	DEFAULT_OUTPUT_LIMIT_KB  = 4    // max size of stdout/stderr kept for each task
+	MAX_OUTPUT_LIMIT_KB      = 8192 // must be smaller than the file size limit of the sandbox
+	DEFAULT_DISPLAY_LIMIT_KB = 64   // max size of stdout/stderr shown in results
+)
+
+const (
+	MAX_REPEAT_COUNT = 10 // max number of runs of a repeated judge task, must match the judge server
+)
+
+const (
+	MIN_BENCHMARK_SIZES = 3  // needed to estimate the complexity class
+	MAX_BENCHMARK_SIZES = 20 // each size is run once, so keep the grading time reasonable
+	// Generated inputs must fit in the output limit of the judge server (8 MB),
+	// which allows about 8 bytes per element at this size.
+	MAX_BENCHMARK_SIZE = 1_000_000
+)
+
+const (
+	MAX_MUTANTS = 20 // each mutant builds and runs the test suite once
+)
+
+const (
+	MAX_CPU_CORES = 8 // cores allocated to a job, must not exceed the core pool of the judge server
+)
+
+const (
+	MAX_PROCESSES = 8 // processes of a multi-process task, must fit in the process limit of the judge container
 )
@@ -0,0 +1,66 @@
+package problem
+
+import (
+	"context"
+	"dsa-backend/handler/problem/util"
+	"dsa-backend/handler/response"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type ProblemDeadlineRequest struct {
+	LectureID  int64            `param:"lectureid"`
+	ProblemID  int64            `param:"problemid"`
+	Deadline   *int64           `json:"deadline"`    // unix time, null for the deadline of the lecture
+	LatePolicy *util.LatePolicy `json:"late_policy"` // null for the late policy of the lecture
+}
+
+// UpdateProblemDeadline godoc
+//
+//	@Summary		Set the deadline and the late policy of a problem
+//	@Description	Set the deadline and the late-submission policy of a problem, which override the ones of the lecture. null restores the ones of the lecture.
+//	@Description	Late policies are "hard" (no score after the deadline), "linear" (penalty_per_day in proportion to the time after the deadline) and "stepped" (penalty_per_day for each started day after the deadline).
+//	@Description	Submissions within grace_minutes after the deadline are not penalized with any policy.
+//	@Tags			Update
+//	@Accept			json
+//	@Produce		json
+//	@Param			lectureid	path		int						true	"Lecture ID"
+//	@Param			problemid	path		int						true	"Problem ID"
+//	@Param			deadline	body		ProblemDeadlineRequest	true	"Deadline and late policy"
+//	@Success		200			{object}	response.Success
+//	@Failure		400			{object}	response.Error	"Invalid request"
+//	@Failure		404			{object}	response.Error	"Problem not found"
+//	@Failure		500			{object}	response.Error	"Internal server error"
+//	@Security		OAuth2Password[grading]
+//	@Router			/problem/crud/deadline/{lectureid}/{problemid} [patch]
+func (h *Handler) UpdateProblemDeadline(c echo.Context) error {
+	var req ProblemDeadlineRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, response.NewError("invalid request: "+err.Error()))
+	}
+	if req.LatePolicy != nil {
+		if err := req.LatePolicy.Policy().Validate(); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, response.NewError("invalid late_policy: "+err.Error()))
+		}
+	}
+
+	ctx := context.Background()
+
+	if _, err := h.problemStore.GetProblemByID(ctx, req.LectureID, req.ProblemID); err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, response.NewError("Problem not found"))
+	}
+
+	var deadline *time.Time
+	if req.Deadline != nil {
+		t := time.Unix(*req.Deadline, 0)
+		deadline = &t
+	}
+
+	if err := h.problemStore.UpdateProblemDeadline(ctx, req.LectureID, req.ProblemID, deadline, req.LatePolicy.Policy()); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("Failed to update deadline: "+err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response.NewSuccess("Deadline updated successfully"))
+}
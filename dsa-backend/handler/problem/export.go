@@ -0,0 +1,150 @@
+package problem
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"dsa-backend/handler/response"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/dsa-uts/dsa-project/database/model"
+	"github.com/labstack/echo/v4"
+)
+
+type ExportProblemRequest struct {
+	LectureID int64  `param:"lectureid"`
+	ProblemID int64  `param:"problemid"`
+	Version   int64  `query:"version"`
+	Format    string `query:"format"` // "json" (default) or "yaml"
+}
+
+// ExportProblem godoc
+//
+//	@Summary		Export a problem as a zip package
+//	@Description	Rebuild a problem package from the resource files of a version of a problem. init.json (or init.yaml) is regenerated from the registered settings with all default values filled in, so the package can be registered again as it is.
+//	@Description	Expected outputs generated from the reference solution are included as resource files.
+//	@Tags			Update
+//	@Produce		application/zip
+//	@Param			lectureid	path		int	true	"Lecture ID"
+//	@Param			problemid	path		int	true	"Problem ID"
+//	@Param			version		query		int		false	"Version to export, the current one if omitted"
+//	@Param			format		query		string	false	"Format of the config, init.json or init.yaml"	Enums(json, yaml)	default(json)
+//	@Success		200			{file}		binary
+//	@Failure		400			{object}	response.Error	"Invalid request"
+//	@Failure		404			{object}	response.Error	"Problem not found"
+//	@Failure		500			{object}	response.Error	"Internal server error"
+//	@Security		OAuth2Password[grading]
+//	@Router			/problem/crud/export/{lectureid}/{problemid} [get]
+func (h *Handler) ExportProblem(c echo.Context) error {
+	var req ExportProblemRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, response.NewError("invalid request: "+err.Error()))
+	}
+
+	configFile := CONFIG_FILE_JSON
+	switch req.Format {
+	case "", "json":
+	case "yaml":
+		configFile = CONFIG_FILE_YAML
+	default:
+		return echo.NewHTTPError(http.StatusBadRequest, response.NewError("format must be \"json\" or \"yaml\""))
+	}
+
+	ctx := context.Background()
+
+	if req.Version == 0 {
+		problem, err := h.problemStore.GetProblemByID(ctx, req.LectureID, req.ProblemID)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusNotFound, response.NewError("Problem not found"))
+		}
+		req.Version = problem.Version
+	}
+
+	version, err := h.problemStore.GetProblemVersion(ctx, req.LectureID, req.ProblemID, req.Version)
+	if err != nil || version.ResourceLocation == nil {
+		return echo.NewHTTPError(http.StatusNotFound, response.NewError("Problem not found"))
+	}
+
+	data, err := buildProblemPackage(version, configFile)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("Failed to build problem package: "+err.Error()))
+	}
+
+	filename := fmt.Sprintf("problem-%d-%d-v%d.zip", version.LectureID, version.ProblemID, version.Version)
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename))
+	return c.Blob(http.StatusOK, "application/zip", data)
+}
+
+// buildProblemPackage zips the resource files of the version with configFile, init.json or init.yaml, regenerated from its detail.
+// The config uploaded originally is replaced, since it may lack default values or generated outputs.
+func buildProblemPackage(version model.ProblemVersion, configFile string) ([]byte, error) {
+	resourceDir := version.ResourceLocation.Path
+
+	var buf bytes.Buffer
+	zipWriter := zip.NewWriter(&buf)
+
+	err := filepath.WalkDir(resourceDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(resourceDir, path)
+		if err != nil {
+			return err
+		}
+		if rel == CONFIG_FILE_JSON || rel == CONFIG_FILE_YAML {
+			return nil
+		}
+
+		src, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer src.Close()
+
+		dst, err := zipWriter.Create(filepath.ToSlash(rel))
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(dst, src)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	config := makeAssignmentConfig(version.ProblemID, version.Title, version.Detail)
+	dst, err := zipWriter.Create(configFile)
+	if err != nil {
+		return nil, err
+	}
+	if configFile == CONFIG_FILE_YAML {
+		data, err := marshalConfigYAML(config)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := dst.Write(data); err != nil {
+			return nil, err
+		}
+	} else {
+		encoder := json.NewEncoder(dst)
+		encoder.SetEscapeHTML(false) // keep commands such as "make && ./a.out" readable
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(config); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := zipWriter.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
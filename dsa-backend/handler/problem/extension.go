@@ -0,0 +1,191 @@
+package problem
+
+import (
+	"context"
+	"dsa-backend/handler/auth"
+	"dsa-backend/handler/response"
+	"net/http"
+	"time"
+
+	"github.com/dsa-uts/dsa-project/database/model"
+	"github.com/labstack/echo/v4"
+)
+
+type DeadlineExtensionParam struct {
+	LectureID int64 `param:"lectureid"`
+}
+
+type DeadlineExtensionRequest struct {
+	LectureID int64  `param:"lectureid"`
+	UserID    string `json:"user_id" validate:"required"`
+	ProblemID *int64 `json:"problem_id"` // null for all problems of the lecture
+	Deadline  int64  `json:"deadline" validate:"required"`
+	Reason    string `json:"reason"`
+}
+
+type DeadlineExtensionDeleteParam struct {
+	LectureID int64 `param:"lectureid"`
+	ID        int64 `param:"id"`
+}
+
+type DeadlineExtensionEntry struct {
+	ID            int64  `json:"id"`
+	UserID        string `json:"user_id"`
+	UserName      string `json:"user_name"`
+	LectureID     int64  `json:"lecture_id"`
+	ProblemID     *int64 `json:"problem_id"` // null for all problems of the lecture
+	Deadline      int64  `json:"deadline"`
+	Reason        string `json:"reason"`
+	GrantedUserID string `json:"granted_user_id"`
+	GrantedAt     int64  `json:"granted_at"`
+}
+
+func makeDeadlineExtensionEntry(extension model.DeadlineExtension) DeadlineExtensionEntry {
+	entry := DeadlineExtensionEntry{
+		ID:        extension.ID,
+		LectureID: extension.LectureID,
+		ProblemID: extension.ProblemID,
+		Deadline:  extension.Deadline.Unix(),
+		Reason:    extension.Reason,
+		GrantedAt: extension.GrantedAt.Unix(),
+	}
+	if extension.User != nil {
+		entry.UserID = extension.User.UserID
+		entry.UserName = extension.User.Name
+	}
+	if extension.GrantedUser != nil {
+		entry.GrantedUserID = extension.GrantedUser.UserID
+	}
+	return entry
+}
+
+// ListDeadlineExtensions godoc
+//
+//	@Summary		List deadline extensions of a lecture
+//	@Description	List the individual deadline extensions of students in a lecture, ordered by student and problem.
+//	@Tags			Update
+//	@Produce		json
+//	@Param			lectureid	path		int	true	"Lecture ID"
+//	@Success		200			{array}		DeadlineExtensionEntry
+//	@Failure		400			{object}	response.Error	"Invalid request"
+//	@Failure		500			{object}	response.Error	"Failed to get deadline extensions"
+//	@Security		OAuth2Password[grading]
+//	@Router			/problem/crud/extension/{lectureid} [get]
+func (h *Handler) ListDeadlineExtensions(c echo.Context) error {
+	var req DeadlineExtensionParam
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, response.NewError("Invalid request: "+err.Error()))
+	}
+
+	ctx := context.Background()
+
+	extensions, err := h.problemStore.GetDeadlineExtensions(ctx, req.LectureID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("Failed to get deadline extensions"))
+	}
+
+	output := make([]DeadlineExtensionEntry, 0, len(extensions))
+	for _, extension := range extensions {
+		output = append(output, makeDeadlineExtensionEntry(extension))
+	}
+	return c.JSON(http.StatusOK, output)
+}
+
+// PutDeadlineExtension godoc
+//
+//	@Summary		Grant a deadline extension to a student
+//	@Description	Extend the deadline of a problem, or of all problems of a lecture, for a student, e.g., for approved accommodations or medical leave.
+//	@Description	An extension replaces the one of the same student and problem (or lecture). It never brings a deadline forward, and the latest one applies if several cover a problem.
+//	@Tags			Update
+//	@Accept			json
+//	@Produce		json
+//	@Param			lectureid	path		int							true	"Lecture ID"
+//	@Param			extension	body		DeadlineExtensionRequest	true	"Deadline extension"
+//	@Success		200			{object}	DeadlineExtensionEntry
+//	@Failure		400			{object}	response.Error	"Invalid request"
+//	@Failure		401			{object}	response.Error	"Failed to get user info"
+//	@Failure		404			{object}	response.Error	"User not found"	"Lecture not found"	"Problem not found"
+//	@Failure		500			{object}	response.Error	"Failed to grant deadline extension"
+//	@Security		OAuth2Password[grading]
+//	@Router			/problem/crud/extension/{lectureid} [put]
+func (h *Handler) PutDeadlineExtension(c echo.Context) error {
+	var req DeadlineExtensionRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, response.NewError("Invalid request: "+err.Error()))
+	}
+	if err := c.Validate(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, response.NewError("Invalid request: "+err.Error()))
+	}
+
+	claim, err := auth.GetJWTClaims(&c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, response.NewError("Failed to get user info"))
+	}
+
+	ctx := context.Background()
+
+	user, err := h.userStore.GetUserByUserID(ctx, req.UserID)
+	if err != nil || user == nil {
+		return echo.NewHTTPError(http.StatusNotFound, response.NewError("User not found"))
+	}
+
+	if _, err := h.problemStore.GetLectureByID(ctx, req.LectureID); err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, response.NewError("Lecture not found"))
+	}
+	if req.ProblemID != nil {
+		if _, err := h.problemStore.GetProblemByID(ctx, req.LectureID, *req.ProblemID); err != nil {
+			return echo.NewHTTPError(http.StatusNotFound, response.NewError("Problem not found"))
+		}
+	}
+
+	extension := model.DeadlineExtension{
+		UserCode:        user.ID,
+		LectureID:       req.LectureID,
+		ProblemID:       req.ProblemID,
+		Deadline:        time.Unix(req.Deadline, 0),
+		Reason:          req.Reason,
+		GrantedUserCode: claim.ID,
+		GrantedAt:       time.Now(),
+	}
+	if err := h.problemStore.PutDeadlineExtension(ctx, &extension); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("Failed to grant deadline extension: "+err.Error()))
+	}
+
+	extension.User = user
+	entry := makeDeadlineExtensionEntry(extension)
+	entry.GrantedUserID = claim.UserID
+	return c.JSON(http.StatusOK, entry)
+}
+
+// DeleteDeadlineExtension godoc
+//
+//	@Summary		Revoke a deadline extension
+//	@Description	Revoke an individual deadline extension, so that the student is judged against the usual deadline again.
+//	@Tags			Update
+//	@Produce		json
+//	@Param			lectureid	path		int	true	"Lecture ID"
+//	@Param			id			path		int	true	"Deadline extension ID"
+//	@Success		200			{object}	response.Success
+//	@Failure		400			{object}	response.Error	"Invalid request"
+//	@Failure		404			{object}	response.Error	"Deadline extension not found"
+//	@Failure		500			{object}	response.Error	"Failed to revoke deadline extension"
+//	@Security		OAuth2Password[grading]
+//	@Router			/problem/crud/extension/{lectureid}/{id} [delete]
+func (h *Handler) DeleteDeadlineExtension(c echo.Context) error {
+	var req DeadlineExtensionDeleteParam
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, response.NewError("Invalid request: "+err.Error()))
+	}
+
+	ctx := context.Background()
+
+	deleted, err := h.problemStore.DeleteDeadlineExtension(ctx, req.LectureID, req.ID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("Failed to revoke deadline extension: "+err.Error()))
+	}
+	if !deleted {
+		return echo.NewHTTPError(http.StatusNotFound, response.NewError("Deadline extension not found"))
+	}
+
+	return c.JSON(http.StatusOK, response.NewSuccess("Deadline extension revoked successfully"))
+}
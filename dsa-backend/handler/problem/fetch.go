@@ -17,6 +17,7 @@ import (
 //
 //	@Summary		list all problem entry, nested in lecture entry.
 //	@Description	get all lecture entries, each containing its problem entries. When you don't have scopes "grading" or "admin", you will only see lecture entries that are published.
+//	@Description	Deadlines of the problems are extended for you if you have deadline extensions, where you don't have scopes "grading" or "admin".
 //	@Tags			Fetch
 //	@Produce		json
 //	@Success		200	{array}		util.LectureEntry
@@ -34,7 +35,13 @@ func (h *Handler) ListProblems(c echo.Context) error {
 	rightsToSeeAll := jwtClaim.HasAllScopes(auth.ScopeGrading) || jwtClaim.HasAllScopes(auth.ScopeAdmin)
 	filter := !rightsToSeeAll
 
-	responseList, err := util.FetchLectureEntry(ctx, h.problemStore, filter)
+	// Students see their own deadlines, extended individually
+	userCode := jwtClaim.ID
+	if rightsToSeeAll {
+		userCode = -1
+	}
+
+	responseList, err := util.FetchLectureEntry(ctx, h.problemStore, filter, userCode)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("failed to fetch lecture entries"))
 	}
@@ -140,6 +147,9 @@ func (h *Handler) ListRequiredFiles(c echo.Context) error {
 		fileList := make([]string, 0)
 
 		for _, problem := range lecture.Problems {
+			if filter && !problem.IsVisible() {
+				continue
+			}
 			for _, filename := range problem.Detail.RequiredFiles {
 				_, exists := fileSet[filename]
 				if exists {
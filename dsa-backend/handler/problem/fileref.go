@@ -0,0 +1,109 @@
+package problem
+
+import (
+	"context"
+	"dsa-backend/handler/auth"
+	"dsa-backend/handler/problem/util"
+	"dsa-backend/handler/response"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/dsa-uts/dsa-project/database/model"
+	"github.com/labstack/echo/v4"
+)
+
+type FileReferenceRequest struct {
+	ID int64 `param:"id"`
+}
+
+// GetFileReference godoc
+//
+//	@Summary		Get a file referenced from a problem description
+//	@Description	Get an image or an attachment linked from the markdown of a problem. Links in the markdown returned by /problem/fetch/detail are rewritten to this endpoint.
+//	@Description	When you don't have scopes "grading" or "admin", you can only get files of published problems.
+//	@Tags			Fetch
+//	@Produce		octet-stream
+//	@Param			id	path		int	true	"File reference ID"
+//	@Success		200	{file}		binary
+//	@Failure		400	{object}	response.Error	"invalid request"
+//	@Failure		404	{object}	response.Error	"file not found"
+//	@Security		OAuth2Password[me]
+//	@Router			/problem/fetch/fileref/{id} [get]
+func (h *Handler) GetFileReference(c echo.Context) error {
+	var req FileReferenceRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, response.NewError("invalid request"))
+	}
+
+	ctx := context.Background()
+
+	// Check your role
+	jwtClaim, err := auth.GetJWTClaims(&c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, response.NewError("failed to get JWT claims"))
+	}
+	rightsToSeeAll := jwtClaim.HasAllScopes(auth.ScopeGrading) || jwtClaim.HasAllScopes(auth.ScopeAdmin)
+	filter := !rightsToSeeAll
+
+	ref, err := h.fileStore.GetFileReference(ctx, req.ID)
+	if err != nil || ref.FileLocation == nil || ref.Problem == nil {
+		return echo.NewHTTPError(http.StatusNotFound, response.NewError("file not found"))
+	}
+
+	// Files of unpublished lectures and unverified problems are hidden, as well as the problems themselves
+	if filter {
+		lecture, err := h.problemStore.GetLectureByID(ctx, ref.LectureID)
+		if err != nil || lecture.StartDate.After(time.Now()) || !ref.Problem.IsVisible() {
+			return echo.NewHTTPError(http.StatusNotFound, response.NewError("file not found"))
+		}
+	}
+
+	return c.File(ref.FileLocation.Path)
+}
+
+// registerFileReferences registers the files in resourceDir linked from the description of the problem,
+// so that they are served by GetFileReference. Files registered already are skipped,
+// and links to files which do not exist are left as they are.
+func (h *Handler) registerFileReferences(ctx context.Context, lectureID, problemID int64, resourceDir string, descriptionPath string) error {
+	markdown, err := os.ReadFile(filepath.Join(resourceDir, descriptionPath))
+	if err != nil {
+		return err
+	}
+
+	refs, err := h.fileStore.GetFileReferencesOfProblem(ctx, lectureID, problemID)
+	if err != nil {
+		return err
+	}
+	registered := map[string]bool{}
+	for _, ref := range refs {
+		if ref.FileLocation != nil {
+			registered[ref.FileLocation.Path] = true
+		}
+	}
+
+	for _, rel := range util.RelativeLinks(string(markdown), descriptionPath) {
+		path := filepath.Join(resourceDir, filepath.FromSlash(rel))
+		if registered[path] {
+			continue
+		}
+		if stat, err := os.Stat(path); err != nil || !stat.Mode().IsRegular() {
+			continue
+		}
+
+		ref := &model.FileReference{
+			LectureID: lectureID,
+			ProblemID: problemID,
+		}
+		fileLocation := &model.FileLocation{
+			Path: path,
+			Ts:   time.Now(),
+		}
+		if err := h.fileStore.RegisterFileReference(ctx, ref, fileLocation); err != nil {
+			return err
+		}
+		registered[path] = true
+	}
+	return nil
+}
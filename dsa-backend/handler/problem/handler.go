@@ -17,6 +17,8 @@ type Handler struct {
 	userStore     database.UserStore
 	jobQueueStore database.JobQueueStore
 	jwtSecret     string
+
+	resultCacheStore database.ResultCacheStore
 }
 
 func NewProblemHandler(jwtSecret string, db *bun.DB) *Handler {
@@ -28,6 +30,8 @@ func NewProblemHandler(jwtSecret string, db *bun.DB) *Handler {
 		userStore:     *database.NewUserStore(db),
 		jobQueueStore: *database.NewJobQueueStore(db),
 		jwtSecret:     jwtSecret,
+
+		resultCacheStore: *database.NewResultCacheStore(db),
 	}
 }
 
@@ -40,6 +44,7 @@ func (h *Handler) RegisterRoutes(r *echo.Group) {
 	fetchRouter.GET("/list", h.ListProblems)
 	fetchRouter.GET("/detail/:lectureid/:problemid", h.GetProblemInfo)
 	fetchRouter.GET("/requiredfiles", h.ListRequiredFiles)
+	fetchRouter.GET("/fileref/:id", h.GetFileReference)
 
 	validateRouter := r.Group("/validate")
 	validateRouter.POST("/:lectureid/:problemid", h.RequestValidation)
@@ -48,13 +53,31 @@ func (h *Handler) RegisterRoutes(r *echo.Group) {
 	judgeRouter := r.Group("/judge", middleware.RequiredScopesMiddleware(auth.ScopeGrading))
 	judgeRouter.POST("/:lectureid/:problemid", h.RequestGrading)
 	judgeRouter.POST("/batch/:lectureid", h.BatchGrading)
+	judgeRouter.POST("/rerun/:id", h.RerunGrading)
+	judgeRouter.POST("/rejudge/request/:id", h.RejudgeRequest)
+	judgeRouter.POST("/rejudge/problem/:lectureid/:problemid", h.RejudgeProblem)
+	judgeRouter.POST("/rejudge/lecture/:lectureid", h.RejudgeLecture)
+	judgeRouter.GET("/rejudge/list/:lectureid", h.ListRejudges)
+	judgeRouter.GET("/rejudge/:id", h.GetRejudgeProgress)
 
 	crudRouter := r.Group("/crud", middleware.RequiredScopesMiddleware(auth.ScopeGrading))
 	crudRouter.PUT("/create", h.CreateLectureEntry)
 	crudRouter.PATCH("/update/:lectureid", h.UpdateLectureEntry)
 	crudRouter.DELETE("/delete/:lectureid", h.DeleteLectureEntry)
+	crudRouter.POST("/clone/:lectureid", h.CloneLecture)
 	crudRouter.POST("/create/:lectureid/:problemid", h.RegisterProblem)
+	crudRouter.POST("/lint", h.LintProblemPackage)
 	crudRouter.DELETE("/delete/:lectureid/:problemid", h.DeleteProblem)
+	crudRouter.GET("/verification/:lectureid/:problemid", h.GetVerificationResult)
+	crudRouter.POST("/publish/:lectureid/:problemid", h.PublishProblem)
+	crudRouter.GET("/versions/:lectureid/:problemid", h.ListProblemVersions)
+	crudRouter.GET("/diff/:lectureid/:problemid", h.DiffProblemVersions)
+	crudRouter.POST("/rollback/:lectureid/:problemid/:version", h.RollbackProblem)
+	crudRouter.GET("/export/:lectureid/:problemid", h.ExportProblem)
+	crudRouter.PATCH("/deadline/:lectureid/:problemid", h.UpdateProblemDeadline)
+	crudRouter.GET("/extension/:lectureid", h.ListDeadlineExtensions)
+	crudRouter.PUT("/extension/:lectureid", h.PutDeadlineExtension)
+	crudRouter.DELETE("/extension/:lectureid/:id", h.DeleteDeadlineExtension)
 
 	resultRouter := r.Group("/result")
 	resultRouter.GET("/validation/:id", h.GetValidationResult)
@@ -64,4 +87,5 @@ func (h *Handler) RegisterRoutes(r *echo.Group) {
 	gradingResultRouter := resultRouter.Group("/grading", middleware.RequiredScopesMiddleware(auth.ScopeGrading))
 	gradingResultRouter.GET("/list/:lectureid", h.ListGradingResults)
 	gradingResultRouter.GET("/summary/:lectureid/:userid", h.GetGradingResult)
+	gradingResultRouter.GET("/history/:id", h.GetGradingHistory)
 }
@@ -0,0 +1,479 @@
+package problem
+
+import (
+	"dsa-backend/fileutil"
+	"dsa-backend/handler/response"
+	"dsa-backend/jsonschema"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/dsa-uts/dsa-project/database/model/complexity"
+	"github.com/dsa-uts/dsa-project/database/model/leakpolicy"
+	"github.com/labstack/echo/v4"
+	"github.com/spf13/afero"
+)
+
+type LintIssue struct {
+	Path    string `json:"path"` // location in init.json or init.yaml, e.g., "judge[2].stdout", or "" for the package itself
+	Message string `json:"message"`
+}
+
+type LintOutput struct {
+	Valid  bool        `json:"valid"`
+	Title  string      `json:"title"` // title in init.json or init.yaml, if it can be read
+	Issues []LintIssue `json:"issues"`
+}
+
+// LintProblemPackage godoc
+//
+//	@Summary		Lint a problem package
+//	@Description	Check a problem package as RegisterProblem does, without registering anything, and report all problems found at once:
+//	@Description	violations of the schema of init.json or init.yaml (unknown keys, wrong types, ...), missing files, duplicate titles and paths escaping the package.
+//	@Tags			Update
+//	@Accept			multipart/form-data
+//	@Produce		json
+//	@Param			file	formData	file	true	"Zip file contains problem resources"
+//	@Success		200		{object}	LintOutput
+//	@Failure		400		{object}	response.Error	"Invalid request"
+//	@Failure		500		{object}	response.Error	"Internal server error"
+//	@Security		OAuth2Password[grading]
+//	@Router			/problem/crud/lint [post]
+func (h *Handler) LintProblemPackage(c echo.Context) error {
+	memFs, baseDir, err := readProblemPackage(c)
+	if err != nil {
+		return err
+	}
+
+	config, issues, err := lintProblemPackage(memFs, baseDir)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("failed to lint problem package: "+err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, LintOutput{
+		Valid:  len(issues) == 0,
+		Title:  config.Title,
+		Issues: issues,
+	})
+}
+
+// readProblemPackage extracts the uploaded zip file into a memory filesystem,
+// and returns the directory containing init.json or init.yaml. The errors are returned as *echo.HTTPError.
+func readProblemPackage(c echo.Context) (afero.Fs, string, error) {
+	zipFile, err := c.FormFile("file")
+	if err != nil {
+		return nil, "", echo.NewHTTPError(http.StatusBadRequest, response.NewError("failed to read file: "+err.Error()))
+	}
+	src, err := zipFile.Open()
+	if err != nil {
+		return nil, "", echo.NewHTTPError(http.StatusInternalServerError, response.NewError("failed to open file: "+err.Error()))
+	}
+	defer src.Close()
+
+	// Create temporary in-memory fs
+	memFs := afero.NewMemMapFs()
+
+	// Extract zip file to temporary fs
+	if err = fileutil.SafeExtractZip(memFs, src, zipFile.Size, "/"); err != nil {
+		return nil, "", echo.NewHTTPError(http.StatusInternalServerError, response.NewError("failed to unzip file: "+err.Error()))
+	}
+
+	// Check if the first level contains only one folder
+	baseDirInMemFs := "/"
+	files, err := afero.ReadDir(memFs, baseDirInMemFs)
+	if err != nil {
+		return nil, "", echo.NewHTTPError(http.StatusInternalServerError, response.NewError("failed to read extracted directory: "+err.Error()))
+	}
+
+	if len(files) == 1 && files[0].IsDir() {
+		// Unnest the folder
+		baseDirInMemFs = filepath.Join("/", files[0].Name())
+	}
+
+	return memFs, baseDirInMemFs, nil
+}
+
+// The schema is read once, when a problem package is checked for the first time
+var loadConfigSchema = sync.OnceValues(func() (*jsonschema.Schema, error) {
+	data, err := os.ReadFile(SCHEMA_PATH)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema: %w", err)
+	}
+	return jsonschema.Parse(data)
+})
+
+// lintProblemPackage reads init.json or init.yaml in baseDir, and checks it against the schema and the files in the package.
+// All problems found are returned as issues, and the config is valid only if there are none.
+// An error is returned only if the check itself fails.
+func lintProblemPackage(memFs afero.Fs, baseDir string) (AssignmentConfig, []LintIssue, error) {
+	config := AssignmentConfig{}
+	issues := []LintIssue{}
+
+	// Check if either init.json or init.yaml exists
+	isFile := func(name string) bool {
+		stat, err := memFs.Stat(filepath.Join(baseDir, name))
+		return err == nil && !stat.IsDir()
+	}
+	configFile := CONFIG_FILE_JSON
+	switch hasJSON, hasYAML := isFile(CONFIG_FILE_JSON), isFile(CONFIG_FILE_YAML); {
+	case hasJSON && hasYAML:
+		issues = append(issues, LintIssue{Message: "both init.json and init.yaml found, keep only one of them"})
+		return config, issues, nil
+	case hasYAML:
+		configFile = CONFIG_FILE_YAML
+	case !hasJSON:
+		issues = append(issues, LintIssue{Message: "init.json or init.yaml not found or is a directory"})
+		return config, issues, nil
+	}
+
+	initData, err := afero.ReadFile(memFs, filepath.Join(baseDir, configFile))
+	if err != nil {
+		return config, nil, fmt.Errorf("failed to read %s: %w", configFile, err)
+	}
+
+	// init.yaml is checked as the equivalent init.json, so that the locations of issues are the same
+	if configFile == CONFIG_FILE_YAML {
+		if initData, err = yamlToJSON(initData); err != nil {
+			issues = append(issues, LintIssue{Message: "invalid YAML: " + err.Error()})
+			return config, issues, nil
+		}
+	}
+
+	schema, err := loadConfigSchema()
+	if err != nil {
+		return config, nil, err
+	}
+	for _, e := range schema.ValidateJSON(initData) {
+		issues = append(issues, LintIssue{Path: e.Path, Message: e.Message})
+	}
+
+	// Values of wrong types are skipped, and reported by the schema already,
+	// so that the other checks run on the rest of the config
+	if err := json.Unmarshal(initData, &config); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if !errors.As(err, &typeErr) {
+			if len(issues) == 0 {
+				issues = append(issues, LintIssue{Message: "failed to parse " + configFile + ": " + err.Error()})
+			}
+			return config, issues, nil
+		}
+		if len(issues) == 0 {
+			issues = append(issues, LintIssue{Path: typeErr.Field, Message: "failed to parse " + configFile + ": " + err.Error()})
+		}
+	}
+
+	// Paths are checked before they are cleaned
+	for _, p := range config.paths() {
+		if escapesPackage(p.path) {
+			issues = append(issues, LintIssue{Path: p.location, Message: "path must be inside the package: " + p.path})
+		}
+	}
+	config.normalize()
+
+	// A location reported already, e.g., a path escaping the package, is not reported again as a missing file
+	reported := map[string]bool{}
+	for _, issue := range issues {
+		reported[issue.Path] = true
+	}
+	for _, issue := range validateAssignmentConfig(memFs, baseDir, &config) {
+		if !reported[issue.Path] {
+			issues = append(issues, issue)
+		}
+	}
+
+	return config, issues, nil
+}
+
+type configPath struct {
+	location string // location in the config, e.g., "judge[2].stdout"
+	path     string
+}
+
+// Returns all paths to files and directories in the package, which appear in the config.
+func (ac *AssignmentConfig) paths() []configPath {
+	paths := []configPath{{"md_file", ac.MDfile}}
+	for i, f := range ac.TestFiles {
+		paths = append(paths, configPath{fmt.Sprintf("test_files[%d]", i), f})
+	}
+	if ac.ReferenceSolution != "" {
+		paths = append(paths, configPath{"reference_solution", ac.ReferenceSolution})
+	}
+
+	taskPaths := func(kind string, tasks []TestCase) {
+		for i, t := range tasks {
+			location := fmt.Sprintf("%s[%d]", kind, i)
+			for _, p := range []configPath{{"stdin", t.Stdin}, {"stdout", t.Stdout}, {"stderr", t.Stderr}, {"fixtures", t.Fixtures}} {
+				if p.path != "" {
+					paths = append(paths, configPath{location + "." + p.location, p.path})
+				}
+			}
+			for j, proc := range t.Processes {
+				if proc.Stdin != "" {
+					paths = append(paths, configPath{fmt.Sprintf("%s.processes[%d].stdin", location, j), proc.Stdin})
+				}
+			}
+		}
+	}
+	taskPaths("build", ac.Build)
+	taskPaths("judge", ac.Judge)
+
+	for i, m := range ac.Mutation {
+		paths = append(paths, configPath{fmt.Sprintf("mutation[%d].reference", i), m.Reference})
+		for j, mutant := range m.Mutants {
+			paths = append(paths, configPath{fmt.Sprintf("mutation[%d].mutants[%d]", i, j), mutant})
+		}
+	}
+	return paths
+}
+
+// Reports whether the relative path points outside of the package, e.g., "../secret" or "/etc/passwd".
+func escapesPackage(p string) bool {
+	p = strings.ReplaceAll(p, "\\", "/")
+	if path.IsAbs(p) {
+		return true
+	}
+	clean := path.Clean(p)
+	return clean == ".." || strings.HasPrefix(clean, "../")
+}
+
+// validateAssignmentConfig checks the decoded config and the files in the package it refers to.
+func validateAssignmentConfig(memFs afero.Fs, baseDir string, config *AssignmentConfig) []LintIssue {
+	issues := []LintIssue{}
+	report := func(location string, format string, args ...any) {
+		issues = append(issues, LintIssue{Path: location, Message: fmt.Sprintf(format, args...)})
+	}
+	isFile := func(p string) bool {
+		stat, err := memFs.Stat(filepath.Join(baseDir, p))
+		return err == nil && !stat.IsDir()
+	}
+	isDir := func(p string) bool {
+		stat, err := memFs.Stat(filepath.Join(baseDir, p))
+		return err == nil && stat.IsDir()
+	}
+	// Generated outputs need not exist, but must not be directories
+	isNotDir := func(p string) bool {
+		stat, err := memFs.Stat(filepath.Join(baseDir, p))
+		return os.IsNotExist(err) || (err == nil && !stat.IsDir())
+	}
+
+	// Check MDfile exists and is a file
+	if config.MDfile != "" && !isFile(config.MDfile) {
+		report("md_file", "md_file not found or is a directory: %s", config.MDfile)
+	}
+
+	// Check output limits
+	if *config.OutputLimitKB <= 0 || *config.OutputLimitKB > MAX_OUTPUT_LIMIT_KB {
+		report("output_limit_kb", "output_limit_kb must be between 1 and %d", MAX_OUTPUT_LIMIT_KB)
+	}
+	if *config.DisplayLimitKB <= 0 {
+		report("display_limit_kb", "display_limit_kb must be positive")
+	}
+
+	// Check test files exist and are files
+	for i, testFile := range config.TestFiles {
+		if !isFile(testFile) {
+			report(fmt.Sprintf("test_files[%d]", i), "test file not found or is a directory: %s", testFile)
+		}
+	}
+
+	// Expected outputs can be generated only by running the reference solution
+	if config.GenerateOutputs && config.ReferenceSolution == "" {
+		report("generate_outputs", "generate_outputs requires reference_solution")
+	}
+
+	// Titles identify tasks in results, so they must be unique
+	checkDuplicateTitles := func(kind string, titles []string) {
+		seen := map[string]bool{}
+		for i, title := range titles {
+			if seen[title] {
+				report(fmt.Sprintf("%s[%d].title", kind, i), "duplicate title in %s: %s", kind, title)
+			}
+			seen[title] = true
+		}
+	}
+	taskTitles := func(tasks []TestCase) []string {
+		titles := []string{}
+		for _, t := range tasks {
+			titles = append(titles, t.Title)
+		}
+		return titles
+	}
+	checkDuplicateTitles("build", taskTitles(config.Build))
+	checkDuplicateTitles("judge", taskTitles(config.Judge))
+
+	// Check Stdin, Stdout, Stderr files in tasks
+	checkTaskFiles := func(kind string, tasks []TestCase, generated bool) {
+		for i, t := range tasks {
+			location := fmt.Sprintf("%s[%d]", kind, i)
+			if t.Stdin != "" && !isFile(t.Stdin) {
+				report(location+".stdin", "stdin file not found or is a directory: %s", t.Stdin)
+			}
+			// Missing expected outputs of judge tasks are generated from the reference solution
+			if t.Stdout != "" && !isFile(t.Stdout) && !(generated && isNotDir(t.Stdout)) {
+				report(location+".stdout", "stdout file not found or is a directory: %s", t.Stdout)
+			}
+			if t.Stderr != "" && !isFile(t.Stderr) && !(generated && isNotDir(t.Stderr)) {
+				report(location+".stderr", "stderr file not found or is a directory: %s", t.Stderr)
+			}
+			for key := range t.Env {
+				if !envNamePattern.MatchString(key) {
+					report(location+".env", "invalid environment variable name: %s", key)
+				}
+			}
+			for _, placeholder := range unknownMessagePlaceholders(t.MessageOnFail) {
+				report(location+".message_on_fail", "unknown placeholder %s, use one of {%s}", placeholder, strings.Join(messagePlaceholders, "}, {"))
+			}
+		}
+	}
+	checkTaskFiles("build", config.Build, false)
+	checkTaskFiles("judge", config.Judge, config.GenerateOutputs)
+
+	// Fixtures are copied into a fresh working directory of judge tasks only,
+	// and only judge tasks can be repeated or run under Valgrind
+	for i, t := range config.Build {
+		location := fmt.Sprintf("build[%d]", i)
+		if t.Fixtures != "" {
+			report(location+".fixtures", "fixtures is not supported in build tasks: %s", t.Title)
+		}
+		if t.Repeat != 0 {
+			report(location+".repeat", "repeat is not supported in build tasks: %s", t.Title)
+		}
+		if t.Memcheck {
+			report(location+".memcheck", "memcheck is not supported in build tasks: %s", t.Title)
+		}
+		if len(t.Processes) > 0 {
+			report(location+".processes", "processes is not supported in build tasks: %s", t.Title)
+		}
+	}
+	if config.CPUTimeMS != nil && *config.CPUTimeMS <= 0 {
+		report("cpu_time_ms", "cpu_time_ms must be positive")
+	}
+	if *config.CPUCores < 1 || *config.CPUCores > MAX_CPU_CORES {
+		report("cpu_cores", "cpu_cores must be between 1 and %d", MAX_CPU_CORES)
+	}
+	if !leakpolicy.Policy(*config.LeakPolicy).IsValid() {
+		report("leak_policy", "leak_policy must be \"fail\" or \"warn\": %s", *config.LeakPolicy)
+	}
+	// Check reference solution directory exists
+	if config.ReferenceSolution != "" && !isDir(config.ReferenceSolution) {
+		report("reference_solution", "reference_solution directory not found or is not a directory: %s", config.ReferenceSolution)
+	}
+
+	// Check benchmark tasks
+	benchmarkTitles := []string{}
+	for i, b := range config.Benchmark {
+		location := fmt.Sprintf("benchmark[%d]", i)
+		benchmarkTitles = append(benchmarkTitles, b.Title)
+		if b.Command == "" || b.Generator == "" {
+			report(location, "command and generator are required in benchmark: %s", b.Title)
+		}
+		if len(b.Sizes) < MIN_BENCHMARK_SIZES || len(b.Sizes) > MAX_BENCHMARK_SIZES {
+			report(location+".sizes", "number of sizes of benchmark %s must be between %d and %d", b.Title, MIN_BENCHMARK_SIZES, MAX_BENCHMARK_SIZES)
+		}
+		for j, n := range b.Sizes {
+			if n <= 0 || (j > 0 && n <= b.Sizes[j-1]) {
+				report(location+".sizes", "sizes of benchmark must be positive and increasing: %s", b.Title)
+				break
+			}
+			if n > MAX_BENCHMARK_SIZE {
+				report(location+".sizes", "sizes of benchmark %s must be at most %d, since the generated input must be at most 8 MB", b.Title, MAX_BENCHMARK_SIZE)
+				break
+			}
+		}
+		if *b.TimeMS <= 0 {
+			report(location+".time_ms", "time_ms of benchmark must be positive: %s", b.Title)
+		}
+		for _, class := range b.Expected {
+			if !complexity.Class(class).IsValid() {
+				report(location+".expected", "unknown complexity class: %s", class)
+			}
+		}
+	}
+	checkDuplicateTitles("benchmark", benchmarkTitles)
+
+	// Check mutation tasks
+	mutationTitles := []string{}
+	for i, m := range config.Mutation {
+		location := fmt.Sprintf("mutation[%d]", i)
+		mutationTitles = append(mutationTitles, m.Title)
+		if m.Command == "" || m.Reference == "" {
+			report(location, "command and reference are required in mutation: %s", m.Title)
+		}
+		if len(m.Mutants) == 0 || len(m.Mutants) > MAX_MUTANTS {
+			report(location+".mutants", "number of mutants of mutation %s must be between 1 and %d", m.Title, MAX_MUTANTS)
+		}
+		for _, implPath := range append([]string{m.Reference}, m.Mutants...) {
+			if implPath == "" || !isDir(implPath) {
+				report(location, "implementation directory of mutation not found or is not a directory: %s", implPath)
+			}
+		}
+		if *m.TimeMS <= 0 {
+			report(location+".time_ms", "time_ms of mutation must be positive: %s", m.Title)
+		}
+		if m.MinScore < 0 || m.MinScore > 1 {
+			report(location+".min_score", "min_score of mutation must be between 0 and 1: %s", m.Title)
+		}
+	}
+	checkDuplicateTitles("mutation", mutationTitles)
+
+	for i, t := range config.Judge {
+		location := fmt.Sprintf("judge[%d]", i)
+		if t.Repeat < 0 || t.Repeat > MAX_REPEAT_COUNT {
+			report(location+".repeat", "repeat of %s must be between 1 and %d", t.Title, MAX_REPEAT_COUNT)
+		}
+		if t.Fixtures != "" && !isDir(t.Fixtures) {
+			report(location+".fixtures", "fixtures directory not found or is not a directory: %s", t.Fixtures)
+		}
+
+		// Check processes of multi-process tasks
+		if len(t.Processes) == 0 {
+			continue
+		}
+		if len(t.Processes) > MAX_PROCESSES {
+			report(location+".processes", "number of processes of %s must be at most %d", t.Title, MAX_PROCESSES)
+		}
+		if t.Memcheck {
+			report(location+".memcheck", "memcheck is not supported in multi-process tasks: %s", t.Title)
+		}
+		names := map[string]bool{}
+		foreground := false
+		for j, p := range t.Processes {
+			processLocation := fmt.Sprintf("%s.processes[%d]", location, j)
+			if !processNamePattern.MatchString(p.Name) || names[p.Name] {
+				report(processLocation+".name", "process names must be unique and consist of letters, digits, '-' and '_': %s", p.Name)
+			}
+			names[p.Name] = true
+			if p.Command == "" {
+				report(processLocation+".command", "command is required in process: %s", p.Name)
+			}
+			if p.Stdin != "" && !isFile(p.Stdin) {
+				report(processLocation+".stdin", "stdin file not found or is a directory: %s", p.Stdin)
+			}
+			foreground = foreground || !p.Background
+		}
+		if !foreground {
+			report(location+".processes", "some process must not be in background: %s", t.Title)
+		}
+	}
+
+	return issues
+}
+
+// Formats the issues as a message of response.Error
+func formatLintIssues(issues []LintIssue) string {
+	lines := []string{fmt.Sprintf("problem package has %d problem(s):", len(issues))}
+	for _, issue := range issues {
+		if issue.Path == "" {
+			lines = append(lines, "- "+issue.Message)
+		} else {
+			lines = append(lines, fmt.Sprintf("- %s: %s", issue.Path, issue.Message))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
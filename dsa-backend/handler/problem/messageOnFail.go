@@ -0,0 +1,57 @@
+package problem
+
+import (
+	"regexp"
+	"slices"
+	"strconv"
+
+	"github.com/dsa-uts/dsa-project/database/model"
+	"github.com/dsa-uts/dsa-project/database/model/requeststatus"
+)
+
+// Matches placeholders in message_on_fail, e.g., "{exit_code}", with the name in the first group.
+var messagePlaceholderPattern = regexp.MustCompile(`\{([a-z_]+)\}`)
+
+// Placeholders replaced with values of the result in message_on_fail
+var messagePlaceholders = []string{"title", "result", "exit_code", "expected_exit_code", "time_ms", "memory_kb"}
+
+// renderMessageOnFail replaces the placeholders in the message of the test case with values of its result,
+// e.g., "exited with {exit_code}, but {expected_exit_code} is expected" to "exited with 1, but 0 is expected".
+// Unknown placeholders are left as they are.
+func renderMessageOnFail(testCase model.TestCase, taskResult model.TaskLog) string {
+	expectedExitCode := strconv.FormatInt(testCase.ExitCode, 10)
+	if testCase.IgnoreExit {
+		expectedExitCode = "any"
+	}
+	values := map[string]string{
+		"title":              testCase.Title,
+		"result":             taskResult.ResultID.String(),
+		"exit_code":          strconv.FormatInt(taskResult.ExitCode, 10),
+		"expected_exit_code": expectedExitCode,
+		"time_ms":            strconv.FormatInt(taskResult.TimeMS, 10),
+		"memory_kb":          strconv.FormatInt(taskResult.MemoryKB, 10),
+	}
+	return messagePlaceholderPattern.ReplaceAllStringFunc(testCase.MessageOnFail, func(placeholder string) string {
+		if value, ok := values[placeholder[1:len(placeholder)-1]]; ok {
+			return value
+		}
+		return placeholder
+	})
+}
+
+// unknownMessagePlaceholders returns the placeholders in the message which are not replaced, e.g., mistyped ones.
+func unknownMessagePlaceholders(message string) []string {
+	unknown := []string{}
+	for _, match := range messagePlaceholderPattern.FindAllStringSubmatch(message, -1) {
+		if !slices.Contains(messagePlaceholders, match[1]) && !slices.Contains(unknown, match[0]) {
+			unknown = append(unknown, match[0])
+		}
+	}
+	return unknown
+}
+
+// Reports whether the hint of the test case is shown with the result.
+// Internal errors are not caused by the submission, so no hint is shown for them.
+func failedTask(taskResult model.TaskLog) bool {
+	return taskResult.ResultID != requeststatus.AC && taskResult.ResultID != requeststatus.IE
+}
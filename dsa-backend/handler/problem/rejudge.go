@@ -0,0 +1,511 @@
+package problem
+
+import (
+	"context"
+	"dsa-backend/handler/auth"
+	"dsa-backend/handler/problem/util"
+	"dsa-backend/handler/response"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/dsa-uts/dsa-project/database/model"
+	"github.com/dsa-uts/dsa-project/database/model/queuestatus"
+	"github.com/dsa-uts/dsa-project/database/model/queuetype"
+	"github.com/dsa-uts/dsa-project/database/model/requeststatus"
+	"github.com/labstack/echo/v4"
+)
+
+type RejudgeParam struct {
+	ID        int64  `param:"id"`
+	LectureID int64  `param:"lectureid"`
+	ProblemID int64  `param:"problemid"`
+	Results   string `form:"results"` // comma separated verdicts, e.g., "WA,IE"
+}
+
+// Parses the verdicts to rejudge. Empty means all verdicts.
+func (rp *RejudgeParam) parseResults() ([]requeststatus.State, error) {
+	results := []requeststatus.State{}
+	if strings.TrimSpace(rp.Results) == "" {
+		return results, nil
+	}
+	for _, name := range strings.Split(rp.Results, ",") {
+		state, ok := requeststatus.Parse(strings.TrimSpace(name))
+		if !ok || state == requeststatus.WJ || state == requeststatus.Judging {
+			return nil, fmt.Errorf("invalid verdict: %q", name)
+		}
+		results = append(results, state)
+	}
+	return results, nil
+}
+
+type RejudgeOutput struct {
+	ID    int64 `json:"id"`
+	Total int64 `json:"total"` // number of grading requests rejudged
+}
+
+// RejudgeRequest godoc
+//
+//	@Summary		Rejudge a grading request
+//	@Description	Judge an existing grading request again with the current version of the problem, from its stored files. The previous result is kept in the history of the request.
+//	@Tags			Submit
+//	@Produce		json
+//	@Param			id	path		int	true	"Grading Request ID"
+//	@Success		200	{object}	RejudgeOutput
+//	@Failure		400	{object}	response.Error	"Invalid request payload"
+//	@Failure		404	{object}	response.Error	"Grading request not found"
+//	@Failure		409	{object}	response.Error	"Grading request is still being judged"
+//	@Failure		500	{object}	response.Error	"Internal server error"
+//	@Security		OAuth2Password[grading]
+//	@Router			/problem/judge/rejudge/request/{id} [post]
+func (h *Handler) RejudgeRequest(c echo.Context) error {
+	var req RejudgeParam
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, response.NewError("Invalid request payload: "+err.Error()))
+	}
+
+	ctx := context.Background()
+
+	request, err := h.requestStore.GetGradingResultByID(ctx, req.ID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, response.NewError("Grading request not found"))
+	}
+
+	// Do not rejudge while the previous job is still in the queue, otherwise both results overwrite each other
+	if request.ResultID == requeststatus.WJ || request.ResultID == requeststatus.Judging {
+		return echo.NewHTTPError(http.StatusConflict, response.NewError("Grading request is still being judged"))
+	}
+
+	fileLocation, err := h.fileStore.GetFileLocation(ctx, request.UploadDirID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("Failed to get file location"))
+	}
+	request.FileLocation = fileLocation
+
+	rejudge := model.Rejudge{
+		LectureID: request.LectureID,
+		ProblemID: &request.ProblemID,
+		RequestID: &request.ID,
+		Results:   []requeststatus.State{},
+	}
+	return h.rejudge(c, &rejudge, []model.GradingRequest{*request}, 0)
+}
+
+// RejudgeProblem godoc
+//
+//	@Summary		Rejudge grading requests of a problem
+//	@Description	Judge all grading requests of a problem again with its current version, from their stored files. The previous results are kept in the history of the requests.
+//	@Description	Requests still being judged are skipped.
+//	@Tags			Submit
+//	@Accept			multipart/form-data
+//	@Produce		json
+//	@Param			lectureid	path		int		true	"Lecture ID"
+//	@Param			problemid	path		int		true	"Problem ID"
+//	@Param			results		formData	string	false	"Comma separated verdicts to rejudge, e.g., WA,IE. All verdicts if omitted"
+//	@Success		200			{object}	RejudgeOutput
+//	@Failure		400			{object}	response.Error	"Invalid request payload"
+//	@Failure		404			{object}	response.Error	"No grading requests to rejudge"
+//	@Failure		500			{object}	response.Error	"Internal server error"
+//	@Security		OAuth2Password[grading]
+//	@Router			/problem/judge/rejudge/problem/{lectureid}/{problemid} [post]
+func (h *Handler) RejudgeProblem(c echo.Context) error {
+	var req RejudgeParam
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, response.NewError("Invalid request payload: "+err.Error()))
+	}
+	results, err := req.parseResults()
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, response.NewError("Invalid request payload: "+err.Error()))
+	}
+
+	ctx := context.Background()
+
+	requests, err := h.requestStore.GetGradingResults(ctx, req.LectureID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("Failed to get grading requests"))
+	}
+
+	targets := []model.GradingRequest{}
+	for _, request := range requests {
+		if request.ProblemID == req.ProblemID {
+			targets = append(targets, request)
+		}
+	}
+
+	rejudge := model.Rejudge{
+		LectureID: req.LectureID,
+		ProblemID: &req.ProblemID,
+		Results:   results,
+	}
+	return h.rejudge(c, &rejudge, targets, 0)
+}
+
+// RejudgeLecture godoc
+//
+//	@Summary		Rejudge grading requests of a lecture
+//	@Description	Judge all grading requests of all problems of a lecture again with their current versions, from their stored files. The previous results are kept in the history of the requests.
+//	@Description	Requests still being judged are skipped.
+//	@Tags			Submit
+//	@Accept			multipart/form-data
+//	@Produce		json
+//	@Param			lectureid	path		int		true	"Lecture ID"
+//	@Param			results		formData	string	false	"Comma separated verdicts to rejudge, e.g., WA,IE. All verdicts if omitted"
+//	@Success		200			{object}	RejudgeOutput
+//	@Failure		400			{object}	response.Error	"Invalid request payload"
+//	@Failure		404			{object}	response.Error	"No grading requests to rejudge"
+//	@Failure		500			{object}	response.Error	"Internal server error"
+//	@Security		OAuth2Password[grading]
+//	@Router			/problem/judge/rejudge/lecture/{lectureid} [post]
+func (h *Handler) RejudgeLecture(c echo.Context) error {
+	var req RejudgeParam
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, response.NewError("Invalid request payload: "+err.Error()))
+	}
+	results, err := req.parseResults()
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, response.NewError("Invalid request payload: "+err.Error()))
+	}
+
+	ctx := context.Background()
+
+	requests, err := h.requestStore.GetGradingResults(ctx, req.LectureID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("Failed to get grading requests"))
+	}
+
+	rejudge := model.Rejudge{
+		LectureID: req.LectureID,
+		Results:   results,
+	}
+	return h.rejudge(c, &rejudge, requests, 0)
+}
+
+// rejudge registers the rejudge, and submits jobs judging the requests again.
+// Requests still being judged, or whose verdicts are not in rejudge.Results, are skipped.
+// The requests must have their FileLocation loaded.
+// If repeat is positive, every judge task is run that many times, see model.JobDetail.Repeat.
+func (h *Handler) rejudge(c echo.Context, rejudge *model.Rejudge, requests []model.GradingRequest, repeat int64) error {
+	ctx := context.Background()
+
+	claim, err := auth.GetJWTClaims(&c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, response.NewError("Failed to get user info"))
+	}
+
+	targets := []model.GradingRequest{}
+	for _, request := range requests {
+		if request.ResultID == requeststatus.WJ || request.ResultID == requeststatus.Judging {
+			continue
+		}
+		if len(rejudge.Results) > 0 && !slices.Contains(rejudge.Results, request.ResultID) {
+			continue
+		}
+		targets = append(targets, request)
+	}
+	if len(targets) == 0 {
+		return echo.NewHTTPError(http.StatusNotFound, response.NewError("No grading requests to rejudge"))
+	}
+
+	// Requests are judged with the current versions of the problems
+	problems := make(map[int64]model.ProblemVersion)
+	for _, request := range targets {
+		if _, exists := problems[request.ProblemID]; exists {
+			continue
+		}
+		problem, err := h.problemStore.GetProblemByID(ctx, request.LectureID, request.ProblemID)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("Failed to get problem info"))
+		}
+		version, err := h.problemStore.GetProblemVersion(ctx, problem.LectureID, problem.ProblemID, problem.Version)
+		if err != nil || version.ResourceLocation == nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("Failed to get problem info"))
+		}
+		problems[request.ProblemID] = version
+	}
+
+	// Total is the number of jobs actually queued, so it is set after queuing them
+	rejudge.TS = time.Now()
+	rejudge.RequestUserCode = claim.ID
+	rejudge.Total = 0
+	if err := h.requestStore.RegisterRejudge(ctx, rejudge); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("Failed to register rejudge"))
+	}
+
+	for _, request := range targets {
+		queued, err := h.queueRejudgeJob(ctx, rejudge.ID, request, problems[request.ProblemID], repeat)
+		if err != nil {
+			// Jobs queued so far are still rejudged
+			if err := h.requestStore.UpdateRejudgeTotal(ctx, rejudge.ID, rejudge.Total); err != nil {
+				return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("Failed to update rejudge"))
+			}
+			return err
+		}
+		if queued {
+			rejudge.Total++
+		}
+	}
+
+	if err := h.requestStore.UpdateRejudgeTotal(ctx, rejudge.ID, rejudge.Total); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("Failed to update rejudge"))
+	}
+
+	return c.JSON(http.StatusOK, RejudgeOutput{
+		ID:    rejudge.ID,
+		Total: rejudge.Total,
+	})
+}
+
+// Resets the grading request for the rejudge, and queues its job with the given version of the problem.
+// It reports false if the request has been reset by another rejudge since it was read, and is skipped.
+// The request is restored if the job cannot be queued, so that it keeps its previous result.
+func (h *Handler) queueRejudgeJob(ctx context.Context, rejudgeID int64, request model.GradingRequest, problem model.ProblemVersion, repeat int64) (bool, error) {
+	reset, err := h.requestStore.ResetGradingRequestForRejudge(ctx, &request, rejudgeID, problem.Version)
+	if err != nil {
+		return false, echo.NewHTTPError(http.StatusInternalServerError, response.NewError("Failed to reset grading request"))
+	}
+	if !reset {
+		return false, nil
+	}
+
+	// Results are stored next to the submitted files, in {basePath}/rejudge/{problemID}-{rejudgeID},
+	// so that they do not overwrite outputs of the previous run.
+	// Note that files of a batched grading request are shared among problems.
+	resultDir := filepath.Join(filepath.Dir(request.FileLocation.Path), "rejudge", fmt.Sprintf("%d-%d", request.ProblemID, rejudgeID))
+
+	detail := model.MakeJobDetail(
+		problem.Detail,
+		problem.ResourceLocation.Path,
+		request.FileLocation.Path,
+		resultDir,
+		problem.Detail.BuildTasks,
+		problem.Detail.JudgeTasks,
+	)
+	detail.Repeat = repeat
+	detail.BenchmarkTasks = problem.Detail.BenchmarkTasks
+	detail.MutationTasks = problem.Detail.MutationTasks
+
+	// A rejudge is requested to run the job again, so the result cache is not looked up,
+	// but the new result replaces the cached one.
+	if resourceDigest, err := h.resourceDigest(ctx, problem.LectureID, problem.ProblemID, problem.Version); err == nil {
+		if cacheKey, err := util.ComputeCacheKey(detail, resourceDigest); err == nil {
+			detail.CacheKey = cacheKey
+		}
+	}
+
+	job := model.JobQueue{
+		RequestType: queuetype.Grading,
+		RequestID:   request.ID,
+		Status:      queuestatus.Pending,
+		CreatedAt:   time.Now(),
+		Detail:      detail,
+	}
+
+	if err := h.jobQueueStore.InsertJob(ctx, &job); err != nil {
+		if err := h.requestStore.RestoreGradingRequestFromRejudge(ctx, &request, rejudgeID); err != nil {
+			return false, echo.NewHTTPError(http.StatusInternalServerError, response.NewError("Failed to restore grading request"))
+		}
+		return false, echo.NewHTTPError(http.StatusInternalServerError, response.NewError("Failed to register job"))
+	}
+	return true, nil
+}
+
+type RejudgeSummary struct {
+	ID            int64    `json:"id"`
+	TS            int64    `json:"ts"`
+	RequestUserID string   `json:"request_user_id"`
+	LectureID     int64    `json:"lecture_id"`
+	ProblemID     *int64   `json:"problem_id"` // null for all problems of the lecture
+	RequestID     *int64   `json:"request_id"` // null for all grading requests of the problems
+	Results       []string `json:"results"`    // verdicts rejudged, empty for all
+	Total         int64    `json:"total"`
+	Done          int64    `json:"done"` // number of grading requests judged again
+}
+
+type RejudgedRequest struct {
+	RequestID        int64  `json:"request_id"`
+	ProblemID        int64  `json:"problem_id"`
+	UserID           string `json:"user_id"`
+	UserName         string `json:"user_name"`
+	SubmissionTS     int64  `json:"submission_ts"`
+	PreviousVersion  int64  `json:"previous_version"`
+	PreviousResultID int64  `json:"previous_result_id"`
+	ResultID         int64  `json:"result_id"` // current result, WJ or Judging while being judged
+}
+
+type RejudgeProgressOutput struct {
+	RejudgeSummary
+	Requests []RejudgedRequest `json:"requests"`
+}
+
+func makeRejudgeSummary(rejudge model.Rejudge, histories []model.GradingRequestHistory) RejudgeSummary {
+	summary := RejudgeSummary{
+		ID:        rejudge.ID,
+		TS:        rejudge.TS.Unix(),
+		LectureID: rejudge.LectureID,
+		ProblemID: rejudge.ProblemID,
+		RequestID: rejudge.RequestID,
+		Results:   []string{},
+		Total:     rejudge.Total,
+	}
+	if rejudge.RequestUser != nil {
+		summary.RequestUserID = rejudge.RequestUser.UserID
+	}
+	for _, result := range rejudge.Results {
+		summary.Results = append(summary.Results, result.String())
+	}
+	// The request may have been rejudged again since, then it counts as done once the latest one finishes
+	for _, history := range histories {
+		if history.Request != nil && history.Request.ResultID != requeststatus.WJ && history.Request.ResultID != requeststatus.Judging {
+			summary.Done++
+		}
+	}
+	return summary
+}
+
+// GetRejudgeProgress godoc
+//
+//	@Summary		Get the progress of a rejudge
+//	@Description	Get the grading requests of a rejudge with their previous and current results, and how many of them are judged again.
+//	@Tags			Submit
+//	@Produce		json
+//	@Param			id	path		int	true	"Rejudge ID"
+//	@Success		200	{object}	RejudgeProgressOutput
+//	@Failure		400	{object}	response.Error	"Invalid request payload"
+//	@Failure		404	{object}	response.Error	"Rejudge not found"
+//	@Failure		500	{object}	response.Error	"Internal server error"
+//	@Security		OAuth2Password[grading]
+//	@Router			/problem/judge/rejudge/{id} [get]
+func (h *Handler) GetRejudgeProgress(c echo.Context) error {
+	var req RejudgeParam
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, response.NewError("Invalid request payload: "+err.Error()))
+	}
+
+	ctx := context.Background()
+
+	rejudge, err := h.requestStore.GetRejudgeByID(ctx, req.ID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, response.NewError("Rejudge not found"))
+	}
+
+	histories, err := h.requestStore.GetHistoriesOfRejudge(ctx, rejudge.ID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("Failed to get rejudged requests"))
+	}
+
+	output := RejudgeProgressOutput{
+		RejudgeSummary: makeRejudgeSummary(*rejudge, histories),
+		Requests:       []RejudgedRequest{},
+	}
+	for _, history := range histories {
+		if history.Request == nil {
+			continue
+		}
+		rejudged := RejudgedRequest{
+			RequestID:        history.RequestID,
+			ProblemID:        history.Request.ProblemID,
+			SubmissionTS:     history.Request.SubmissionTS.Unix(),
+			PreviousVersion:  history.ProblemVersion,
+			PreviousResultID: int64(history.ResultID),
+			ResultID:         int64(history.Request.ResultID),
+		}
+		if history.Request.SubjectUser != nil {
+			rejudged.UserID = history.Request.SubjectUser.UserID
+			rejudged.UserName = history.Request.SubjectUser.Name
+		}
+		output.Requests = append(output.Requests, rejudged)
+	}
+
+	return c.JSON(http.StatusOK, output)
+}
+
+// ListRejudges godoc
+//
+//	@Summary		List rejudges of a lecture
+//	@Description	List the rejudges of grading requests of a lecture with their progress, the latest first.
+//	@Tags			Submit
+//	@Produce		json
+//	@Param			lectureid	path		int	true	"Lecture ID"
+//	@Success		200			{array}		RejudgeSummary
+//	@Failure		400			{object}	response.Error	"Invalid request payload"
+//	@Failure		500			{object}	response.Error	"Internal server error"
+//	@Security		OAuth2Password[grading]
+//	@Router			/problem/judge/rejudge/list/{lectureid} [get]
+func (h *Handler) ListRejudges(c echo.Context) error {
+	var req RejudgeParam
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, response.NewError("Invalid request payload: "+err.Error()))
+	}
+
+	ctx := context.Background()
+
+	rejudges, err := h.requestStore.GetRejudges(ctx, req.LectureID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("Failed to get rejudges"))
+	}
+
+	output := make([]RejudgeSummary, 0, len(rejudges))
+	for _, rejudge := range rejudges {
+		histories, err := h.requestStore.GetHistoriesOfRejudge(ctx, rejudge.ID)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("Failed to get rejudged requests"))
+		}
+		output = append(output, makeRejudgeSummary(rejudge, histories))
+	}
+
+	return c.JSON(http.StatusOK, output)
+}
+
+type GradingHistoryEntry struct {
+	RejudgeID      int64 `json:"rejudge_id"`
+	ArchivedAt     int64 `json:"archived_at"`
+	ProblemVersion int64 `json:"problem_version"`
+	ResultID       int64 `json:"result_id"`
+	TimeMS         int64 `json:"time_ms"`
+	CPUTimeMS      int64 `json:"cpu_time_ms"`
+	MemoryKB       int64 `json:"memory_kb"`
+}
+
+// GetGradingHistory godoc
+//
+//	@Summary		Get previous results of a grading request
+//	@Description	Get the results of a grading request replaced by rejudges, the latest first.
+//	@Tags			Result
+//	@Produce		json
+//	@Param			id	path		int	true	"Grading Request ID"
+//	@Success		200	{array}		GradingHistoryEntry
+//	@Failure		400	{object}	response.Error	"Invalid request payload"
+//	@Failure		500	{object}	response.Error	"Internal server error"
+//	@Security		OAuth2Password[grading]
+//	@Router			/problem/result/grading/history/{id} [get]
+func (h *Handler) GetGradingHistory(c echo.Context) error {
+	var req RejudgeParam
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, response.NewError("Invalid request payload: "+err.Error()))
+	}
+
+	ctx := context.Background()
+
+	histories, err := h.requestStore.GetHistoriesOfGradingRequest(ctx, req.ID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("Failed to get history of grading request"))
+	}
+
+	output := make([]GradingHistoryEntry, 0, len(histories))
+	for _, history := range histories {
+		output = append(output, GradingHistoryEntry{
+			RejudgeID:      history.RejudgeID,
+			ArchivedAt:     history.ArchivedAt.Unix(),
+			ProblemVersion: history.ProblemVersion,
+			ResultID:       int64(history.ResultID),
+			TimeMS:         history.Log.TimeMS,
+			CPUTimeMS:      history.Log.CPUTimeMS,
+			MemoryKB:       history.Log.MemoryKB,
+		})
+	}
+
+	return c.JSON(http.StatusOK, output)
+}
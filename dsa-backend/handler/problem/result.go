@@ -35,6 +35,7 @@ type ValidationResult struct {
 	ProblemID int64  `json:"problem_id"`
 	ResultID  int64  `json:"result_id"`
 	TimeMS    int64  `json:"time_ms"`
+	CPUTimeMS int64  `json:"cpu_time_ms"`
 	MemoryKB  int64  `json:"memory_kb"`
 }
 
@@ -85,7 +86,7 @@ func (h *Handler) ListValidationResults(c echo.Context) error {
 	}
 
 	// get allowed lecture ids
-	lectureEntries, err := util.FetchLectureEntry(ctx, h.problemStore, filter)
+	lectureEntries, err := util.FetchLectureEntry(ctx, h.problemStore, filter, userCode)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("Failed to get lecture entries"))
 	}
@@ -117,6 +118,7 @@ func (h *Handler) ListValidationResults(c echo.Context) error {
 			ProblemID: result.ProblemID,
 			ResultID:  int64(result.ResultID),
 			TimeMS:    result.Log.TimeMS,
+			CPUTimeMS: result.Log.CPUTimeMS,
 			MemoryKB:  result.Log.MemoryKB,
 		})
 	}
@@ -189,6 +191,7 @@ func (h *Handler) GetValidationResult(c echo.Context) error {
 		ProblemID: validationRequest.ProblemID,
 		ResultID:  int64(validationRequest.ResultID),
 		TimeMS:    validationRequest.Log.TimeMS,
+		CPUTimeMS: validationRequest.Log.CPUTimeMS,
 		MemoryKB:  validationRequest.Log.MemoryKB,
 	})
 }
@@ -206,10 +209,14 @@ type DetailOutput struct {
 	ProblemID     int64             `json:"problem_id"`
 	LectureTitle  string            `json:"lecture_title"`
 	ProblemTitle  string            `json:"problem_title"`
+	Version       int64             `json:"version"`
 	SubmissionTS  int64             `json:"submission_ts"`
 	ResultID      int64             `json:"result_id"`
 	TimeMS        int64             `json:"time_ms"`
+	CPUTimeMS     int64             `json:"cpu_time_ms"`
 	MemoryKB      int64             `json:"memory_kb"`
+	Flaky         bool              `json:"flaky"`
+	Cached        bool              `json:"cached"` // result reused from an identical job
 	UploadedFiles []util.FileData   `json:"uploaded_files"`
 	TestFiles     []util.FileData   `json:"test_files"`
 	BuildLogs     []DetailedTaskLog `json:"build_logs"`
@@ -217,20 +224,70 @@ type DetailOutput struct {
 }
 
 type DetailedTaskLog struct {
-	TestCaseID       int64   `json:"test_case_id"`
-	Description      string  `json:"description"`
-	Command          string  `json:"command"`
-	ResultID         int64   `json:"result_id"`
-	TimeMS           int64   `json:"time_ms"`
-	MemoryKB         int64   `json:"memory_kb"`
-	ExitCode         int64   `json:"exit_code"`
-	ExpectedExitCode int64   `json:"expected_exit_code"`
-	IgnoreExit       bool    `json:"ignore_exit"`
-	Stdin            *string `json:"stdin"`           // base64 encoded, compressed with gzip
-	Stdout           string  `json:"stdout"`          // base64 encoded, compressed with gzip
-	Stderr           string  `json:"stderr"`          // base64 encoded, compressed with gzip
-	ExpectedStdout   *string `json:"expected_stdout"` // base64 encoded, compressed with gzip
-	ExpectedStderr   *string `json:"expected_stderr"` // base64 encoded, compressed with gzip
+	TestCaseID       int64        `json:"test_case_id"`
+	Description      string       `json:"description"`
+	MessageOnFail    string       `json:"message_on_fail"` // hint of the test case for the failure, empty if the task passed
+	Command          string       `json:"command"`
+	ResultID         int64        `json:"result_id"`
+	TimeMS           int64        `json:"time_ms"`
+	CPUTimeMS        int64        `json:"cpu_time_ms"`
+	MemoryKB         int64        `json:"memory_kb"`
+	ExitCode         int64        `json:"exit_code"`
+	ExpectedExitCode int64        `json:"expected_exit_code"`
+	IgnoreExit       bool         `json:"ignore_exit"`
+	Stdin            *string      `json:"stdin"`           // base64 encoded, compressed with gzip
+	Stdout           string       `json:"stdout"`          // base64 encoded, compressed with gzip
+	Stderr           string       `json:"stderr"`          // base64 encoded, compressed with gzip
+	ExpectedStdout   *string      `json:"expected_stdout"` // base64 encoded, compressed with gzip
+	ExpectedStderr   *string      `json:"expected_stderr"` // base64 encoded, compressed with gzip
+	Truncated        bool         `json:"truncated"`       // stdout or stderr is cut off at the output limit or the display limit
+	Flaky            bool         `json:"flaky"`           // repeated runs got different verdicts
+	Runs             []TaskRunLog `json:"runs"`            // every run of a repeated task, empty if run only once
+
+	Memcheck []MemcheckFinding `json:"memcheck"` // errors found by Valgrind, empty if not a memcheck task
+
+	Processes []ProcessDetail `json:"processes"` // processes checked by the command, empty if not a multi-process task
+
+	Threads []ThreadDetail `json:"threads"` // CPU time of each thread of the command
+}
+
+type ProcessDetail struct {
+	Name       string `json:"name"`
+	Command    string `json:"command"`
+	ResultID   int64  `json:"result_id"`
+	Ready      bool   `json:"ready"` // the readiness check passed, or there is none
+	Background bool   `json:"background"`
+	TimeMS     int64  `json:"time_ms"`
+	CPUTimeMS  int64  `json:"cpu_time_ms"`
+	MemoryKB   int64  `json:"memory_kb"` // of the whole container, including other processes
+	ExitCode   int64  `json:"exit_code"`
+	Stdout     string `json:"stdout"`    // base64 encoded, compressed with gzip
+	Stderr     string `json:"stderr"`    // base64 encoded, compressed with gzip
+	Truncated  bool   `json:"truncated"` // stdout or stderr is cut off at the output limit or the display limit
+
+	Threads []ThreadDetail `json:"threads"`
+}
+
+type ThreadDetail struct {
+	TID       int64  `json:"tid"`
+	Name      string `json:"name"` // name of the program running the thread
+	CPUTimeMS int64  `json:"cpu_time_ms"`
+}
+
+type MemcheckFinding struct {
+	Kind        string `json:"kind"` // e.g., "InvalidRead", "Leak_DefinitelyLost"
+	Message     string `json:"message"`
+	Leak        bool   `json:"leak"`
+	LeakedBytes int64  `json:"leaked_bytes"`
+	Location    string `json:"location"` // e.g., "list.c:12 (push)", empty if unknown
+}
+
+type TaskRunLog struct {
+	ResultID  int64 `json:"result_id"`
+	TimeMS    int64 `json:"time_ms"`
+	CPUTimeMS int64 `json:"cpu_time_ms"`
+	MemoryKB  int64 `json:"memory_kb"`
+	ExitCode  int64 `json:"exit_code"`
 }
 
 // GetValidationDetail gets detailed information about a specific validation result.
@@ -290,8 +347,8 @@ func (h *Handler) GetValidationDetail(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusNotFound, response.NewError("Validation result not found"))
 	}
 
-	// Fetch problem info to get test case info and test files.
-	problem_info, err := h.problemStore.GetProblemByID(ctx, validationRequest.LectureID, validationRequest.ProblemID)
+	// Fetch the version of the problem judged with, to get test case info and test files.
+	problem_info, err := h.problemStore.GetProblemVersion(ctx, validationRequest.LectureID, validationRequest.ProblemID, validationRequest.ProblemVersion)
 
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("Failed to get problem info"))
@@ -306,10 +363,14 @@ func (h *Handler) GetValidationDetail(c echo.Context) error {
 		ProblemID:    validationRequest.ProblemID,
 		LectureTitle: lecture_info.Title,
 		ProblemTitle: problem_info.Title,
+		Version:      problem_info.Version,
 		SubmissionTS: validationRequest.TS.Unix(), // for validation request, submission ts is same as request ts
 		ResultID:     int64(validationRequest.ResultID),
 		TimeMS:       validationRequest.Log.TimeMS,
+		CPUTimeMS:    validationRequest.Log.CPUTimeMS,
 		MemoryKB:     validationRequest.Log.MemoryKB,
+		Flaky:        validationRequest.Log.Flaky,
+		Cached:       validationRequest.Log.Cached,
 		// Fill in UploadedFiles later
 		// NOTE: initialize with empty slice to avoid null encoding in JSON
 		UploadedFiles: []util.FileData{},
@@ -336,11 +397,10 @@ func (h *Handler) GetValidationDetail(c echo.Context) error {
 
 	detail.UploadedFiles = fileDataList
 
-	resource_dir, err := h.problemStore.FetchResourcePath(ctx, validationRequest.LectureID, validationRequest.ProblemID)
-
-	if err != nil {
+	if problem_info.ResourceLocation == nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("Failed to get problem info"))
 	}
+	resource_dir := problem_info.ResourceLocation.Path
 
 	buildTaskDict := make(map[int64]model.TestCase)
 	for _, task := range problem_info.Detail.BuildTasks {
@@ -352,7 +412,7 @@ func (h *Handler) GetValidationDetail(c echo.Context) error {
 	}
 
 	// Fill in test files
-	testFiles, err := util.FetchTestFielsInProblem(ctx, h.problemStore, validationRequest.LectureID, validationRequest.ProblemID)
+	testFiles, err := util.FetchTestFielsInProblem(ctx, h.problemStore, validationRequest.LectureID, validationRequest.ProblemID, validationRequest.ProblemVersion)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("Failed to get problem info"))
 	}
@@ -365,7 +425,7 @@ func (h *Handler) GetValidationDetail(c echo.Context) error {
 			return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("Inconsistent data: build task not found"))
 		}
 
-		detailedTaskLog, err := makeDetailedTaskLog(buildResult, corresponding_task, resource_dir)
+		detailedTaskLog, err := makeDetailedTaskLog(buildResult, corresponding_task, resource_dir, displayLimitBytes(problem_info.Detail))
 		if err != nil {
 			return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("Failed to create detailed task log: "+err.Error()))
 		}
@@ -381,7 +441,7 @@ func (h *Handler) GetValidationDetail(c echo.Context) error {
 			return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("Inconsistent data: judge task not found"))
 		}
 
-		detailedTaskLog, err := makeDetailedTaskLog(judgeResult, corresponding_task, resource_dir)
+		detailedTaskLog, err := makeDetailedTaskLog(judgeResult, corresponding_task, resource_dir, displayLimitBytes(problem_info.Detail))
 		if err != nil {
 			return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("Failed to create detailed task log: "+err.Error()))
 		}
@@ -413,20 +473,30 @@ type GradingResultPerProblem struct {
 	ResultID     int64 `json:"result_id"`
 	SubmissionTS int64 `json:"submission_ts"`
 	TimeMS       int64 `json:"time_ms"`
+	CPUTimeMS    int64 `json:"cpu_time_ms"`
 	MemoryKB     int64 `json:"memory_kb"`
+	Flaky        bool  `json:"flaky"`
+	Cached       bool  `json:"cached"` // result reused from an identical job
+
+	Deadline    int64   `json:"deadline"`     // deadline of the problem the submission is judged against, extended for the student
+	Extended    bool    `json:"extended"`     // the deadline is extended for the student
+	LateSeconds int64   `json:"late_seconds"` // time from the deadline to the submission, 0 if submitted in time
+	Penalty     float64 `json:"penalty"`      // fraction of the score deducted by the late policy, between 0 and 1
 }
 
 // ListGradingResults lists grading results for a specific lecture.
 //
 //	@Summary		List Grading Results for a Specific Lecture
 //	@Description	List grading results for a specific lecture.
+//	@Description	The submission time of each result is checked against the deadline of its problem, and the penalty of the late policy is computed.
+//	@Description	Deadlines are extended for students with deadline extensions.
 //	@Tags			Result
 //	@Produce		json
 //	@Param			lectureid	path		int64	true	"The ID of the lecture to retrieve grading results for."
 //	@Success		200			{object}	GradingListOutput
 //	@Failure		400			{object}	response.Error	"Invalid request"	"No users found"
 //	@Failure		401			{object}	response.Error	"Failed to get user info"
-//	@Failure		500			{object}	response.Error	"Failed to get user list"	"Failed to get grading results"	"Failed to get lecture info"	"Inconsistent data: user not found"	"Inconsistent data: user not found in detail assembly"
+//	@Failure		500			{object}	response.Error	"Failed to get user list"	"Failed to get grading results"	"Failed to get lecture info"	"Failed to get deadline extensions"	"Inconsistent data: user not found"	"Inconsistent data: user not found in detail assembly"
 //	@Security		OAuth2Password[grading]
 //	@Router			/problem/result/grading/list/{lectureid} [get]
 func (h *Handler) ListGradingResults(c echo.Context) error {
@@ -455,7 +525,7 @@ func (h *Handler) ListGradingResults(c echo.Context) error {
 	}
 
 	// get lecture info
-	lectureEntry, err := util.FetchLectureByID(ctx, h.problemStore, props.LectureID, false)
+	lectureEntry, err := util.FetchLectureByID(ctx, h.problemStore, props.LectureID, false, -1)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("Failed to get lecture info"))
 	}
@@ -468,6 +538,22 @@ func (h *Handler) ListGradingResults(c echo.Context) error {
 		LectureInfo: *lectureEntry,
 	}
 
+	// Deadlines and late policies of the problems, where those of the lecture are the defaults
+	problemEntries := make(map[int64]util.ProblemEntry)
+	for _, problem := range lectureEntry.Problems {
+		problemEntries[problem.ProblemID] = problem
+	}
+
+	// Individual deadline extensions of the students
+	extensions, err := h.problemStore.GetDeadlineExtensions(ctx, props.LectureID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("Failed to get deadline extensions"))
+	}
+	extensionsOfUser := make(map[int64][]model.DeadlineExtension)
+	for _, extension := range extensions {
+		extensionsOfUser[extension.UserCode] = append(extensionsOfUser[extension.UserCode], extension)
+	}
+
 	// TODO: implement logic to fill in output.Detail
 	gradingResultDict := make(map[int64]UserGradingResult)
 	for _, user := range *userList {
@@ -485,13 +571,30 @@ func (h *Handler) ListGradingResults(c echo.Context) error {
 			return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("Inconsistent data: user not found"))
 		}
 
+		deadline, latePolicy := lectureEntry.Deadline, lectureEntry.LatePolicy
+		if problem, exists := problemEntries[result.ProblemID]; exists {
+			deadline, latePolicy = problem.Deadline, problem.LatePolicy
+		}
+		extended := model.ExtendDeadline(time.Unix(deadline, 0), props.LectureID, result.ProblemID, extensionsOfUser[result.UserCode])
+		penalty := 0.0
+		if latePolicy != nil {
+			penalty = latePolicy.Policy().Penalty(extended, result.SubmissionTS)
+		}
+
 		userResult.Results = append(userResult.Results, GradingResultPerProblem{
 			ID:           result.ID,
 			ProblemID:    result.ProblemID,
 			ResultID:     int64(result.ResultID),
 			SubmissionTS: result.SubmissionTS.Unix(),
 			TimeMS:       result.Log.TimeMS,
+			CPUTimeMS:    result.Log.CPUTimeMS,
 			MemoryKB:     result.Log.MemoryKB,
+			Flaky:        result.Log.Flaky,
+			Cached:       result.Log.Cached,
+			Deadline:     extended.Unix(),
+			Extended:     extended.Unix() > deadline,
+			LateSeconds:  max(result.SubmissionTS.Unix()-extended.Unix(), 0),
+			Penalty:      penalty,
 		})
 
 		gradingResultDict[result.UserCode] = userResult
@@ -547,6 +650,7 @@ type GradingDetailOutput struct {
 type GradingDetailPerProblem struct {
 	ID              int64             `json:"id"`
 	ProblemID       int64             `json:"problem_id"`
+	Version         int64             `json:"version"`
 	RequestUserID   string            `json:"request_user_id"`
 	RequestUserName string            `json:"request_user_name"`
 	TS              int64             `json:"ts"`
@@ -554,9 +658,61 @@ type GradingDetailPerProblem struct {
 	ResultID        int64             `json:"result_id"`
 	FileGroupID     int64             `json:"file_group_id"`
 	TimeMS          int64             `json:"time_ms"`
+	CPUTimeMS       int64             `json:"cpu_time_ms"`
 	MemoryKB        int64             `json:"memory_kb"`
+	Flaky           bool              `json:"flaky"`
+	Cached          bool              `json:"cached"` // result reused from an identical job
 	BuildLogs       []DetailedTaskLog `json:"build_logs"`
 	JudgeLogs       []DetailedTaskLog `json:"judge_logs"`
+	BenchmarkLogs   []BenchmarkDetail `json:"benchmark_logs"`
+	MutationLogs    []MutationDetail  `json:"mutation_logs"`
+}
+
+type BenchmarkDetail struct {
+	TaskID          int64            `json:"task_id"`
+	Title           string           `json:"title"`
+	Description     string           `json:"description"`
+	ResultID        int64            `json:"result_id"`
+	EstimatedClass  string           `json:"estimated_class"` // in big-O notation, empty if not estimated
+	ExpectedClasses []string         `json:"expected_classes"`
+	Points          []BenchmarkPoint `json:"points"`
+	Fits            []ComplexityFit  `json:"fits"`
+	Error           string           `json:"error,omitempty"` // why the benchmark is IE
+}
+
+type BenchmarkPoint struct {
+	N        int64 `json:"n"`
+	ResultID int64 `json:"result_id"`
+	TimeMS   int64 `json:"time_ms"`
+	MemoryKB int64 `json:"memory_kb"`
+}
+
+type MutationDetail struct {
+	TaskID      int64         `json:"task_id"`
+	Title       string        `json:"title"`
+	Description string        `json:"description"`
+	ResultID    int64         `json:"result_id"`
+	Score       float64       `json:"score"`     // fraction of mutants killed
+	MinScore    float64       `json:"min_score"` // 0 if any score is accepted
+	Reference   MutationRun   `json:"reference"`
+	Mutants     []MutationRun `json:"mutants"`  // empty if the test suite failed on the reference implementation
+	Survived    []string      `json:"survived"` // names of mutants not killed
+}
+
+type MutationRun struct {
+	Name     string `json:"name"`
+	ResultID int64  `json:"result_id"`
+	Killed   bool   `json:"killed"`
+	TimeMS   int64  `json:"time_ms"`
+	MemoryKB int64  `json:"memory_kb"`
+	ExitCode int64  `json:"exit_code"`
+	Stdout   string `json:"stdout"` // base64 encoded, compressed with gzip
+	Stderr   string `json:"stderr"` // base64 encoded, compressed with gzip
+}
+
+type ComplexityFit struct {
+	Class    string  `json:"class"`    // in big-O notation
+	Residual float64 `json:"residual"` // smaller is better
 }
 
 type FileGroup struct {
@@ -611,34 +767,35 @@ func (h *Handler) GetGradingResult(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("Failed to get lecture info"))
 	}
 
-	// create lecture entry
-	lectureEntry := util.LectureEntry{
-		LectureID: lectureData.ID,
-		Title:     lectureData.Title,
-		StartDate: lectureData.StartDate.Unix(),
-		Deadline:  lectureData.Deadline.Unix(),
-		// NOTE: initialize with empty slice to avoid null encoding in JSON
-		Problems: []util.ProblemEntry{},
-	}
-
-	for _, problem := range lectureData.Problems {
-		lectureEntry.Problems = append(lectureEntry.Problems, util.ProblemEntry{
-			LectureID:    problem.LectureID,
-			ProblemID:    problem.ProblemID,
-			RegisteredAt: problem.RegisteredAt.Unix(),
-			Title:        problem.Title,
-		})
+	// create lecture entry, with the deadlines extended for the student
+	lectureEntryOfUser, err := util.FetchLectureByID(ctx, h.problemStore, props.LectureID, false, userCode)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("Failed to get lecture info"))
 	}
+	lectureEntry := *lectureEntryOfUser
 
 	problemDict := make(map[int64]model.Problem)
 	for _, problem := range lectureData.Problems {
 		problemDict[problem.ProblemID] = *problem
 	}
 
-	// Fetch resource paths for all problems
-	resourceDirDict, err := h.problemStore.FetchAllResourceLocations(ctx, &props.LectureID, nil)
-	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("Failed to get problem info"))
+	// Results are shown with the versions of the problems they were judged with,
+	// which are fetched once for each version
+	versionDict := make(map[[2]int64]model.ProblemVersion)
+	getVersion := func(problemID, version int64) (model.ProblemVersion, error) {
+		key := [2]int64{problemID, version}
+		if problemVersion, exists := versionDict[key]; exists {
+			return problemVersion, nil
+		}
+		problemVersion, err := h.problemStore.GetProblemVersion(ctx, props.LectureID, problemID, version)
+		if err != nil {
+			return model.ProblemVersion{}, err
+		}
+		if problemVersion.ResourceLocation == nil {
+			return model.ProblemVersion{}, fmt.Errorf("resource location of problem %d version %d not found", problemID, version)
+		}
+		versionDict[key] = problemVersion
+		return problemVersion, nil
 	}
 
 	grResults, err := h.requestStore.GetGradingResultsByLectureIDAndUserCode(ctx, props.LectureID, userCode)
@@ -663,7 +820,7 @@ func (h *Handler) GetGradingResult(c echo.Context) error {
 	// Fill in TestFilesPerProblem
 	testFilesPerProblem := make([]TestFilesPerProblem, 0, len(lectureEntry.Problems))
 	for _, problem := range lectureEntry.Problems {
-		testFiles, err := util.FetchTestFielsInProblem(ctx, h.problemStore, problem.LectureID, problem.ProblemID)
+		testFiles, err := util.FetchTestFielsInProblem(ctx, h.problemStore, problem.LectureID, problem.ProblemID, problemDict[problem.ProblemID].Version)
 		if err != nil {
 			return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("Failed to get problem info"))
 		}
@@ -703,11 +860,11 @@ func (h *Handler) GetGradingResult(c echo.Context) error {
 
 	// Fill in DetailList
 	for _, grResult := range grResults {
-		var problemData model.Problem
-		problemData, exists := problemDict[grResult.ProblemID]
-		if !exists {
+		problemData, err := getVersion(grResult.ProblemID, grResult.ProblemVersion)
+		if err != nil {
 			return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("Failed to get problem info"))
 		}
+		resource_dir := problemData.ResourceLocation.Path
 
 		if grResult.RequestUser == nil {
 			return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("Inconsistent data: user info missing"))
@@ -716,6 +873,7 @@ func (h *Handler) GetGradingResult(c echo.Context) error {
 		detail := GradingDetailPerProblem{
 			ID:              grResult.ID,
 			ProblemID:       grResult.ProblemID,
+			Version:         grResult.ProblemVersion,
 			RequestUserID:   grResult.RequestUser.UserID,
 			RequestUserName: grResult.RequestUser.Name,
 			TS:              grResult.TS.Unix(),
@@ -723,12 +881,19 @@ func (h *Handler) GetGradingResult(c echo.Context) error {
 			ResultID:        int64(grResult.ResultID),
 			FileGroupID:     grResult.UploadDirID,
 			TimeMS:          grResult.Log.TimeMS,
+			CPUTimeMS:       grResult.Log.CPUTimeMS,
 			MemoryKB:        grResult.Log.MemoryKB,
+			Flaky:           grResult.Log.Flaky,
+			Cached:          grResult.Log.Cached,
 			// BuildLogs to be filled later
 			// NOTE: initialize with empty slice to avoid null encoding in JSON
 			BuildLogs: []DetailedTaskLog{},
 			// JudgeLogs to be filled later
 			JudgeLogs: []DetailedTaskLog{},
+			// BenchmarkLogs to be filled later
+			BenchmarkLogs: []BenchmarkDetail{},
+			// MutationLogs to be filled later
+			MutationLogs: []MutationDetail{},
 		}
 
 		buildTaskDict := make(map[int64]model.TestCase)
@@ -747,12 +912,7 @@ func (h *Handler) GetGradingResult(c echo.Context) error {
 				return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("Inconsistent data: build task not found"))
 			}
 
-			resource_dir, exists := resourceDirDict.Get(grResult.LectureID, grResult.ProblemID)
-			if !exists {
-				return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("Failed to get problem info"))
-			}
-
-			detailedTaskLog, err := makeDetailedTaskLog(buildResult, corresponding_task, resource_dir)
+			detailedTaskLog, err := makeDetailedTaskLog(buildResult, corresponding_task, resource_dir, displayLimitBytes(problemData.Detail))
 			if err != nil {
 				return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("Failed to create detailed task log: "+err.Error()))
 			}
@@ -766,17 +926,45 @@ func (h *Handler) GetGradingResult(c echo.Context) error {
 				return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("Inconsistent data: judge task not found"))
 			}
 
-			resource_dir, exists := resourceDirDict.Get(grResult.LectureID, grResult.ProblemID)
+			detailedTaskLog, err := makeDetailedTaskLog(judgeResult, corresponding_task, resource_dir, displayLimitBytes(problemData.Detail))
+			if err != nil {
+				return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("Failed to create detailed task log: "+err.Error()))
+			}
+
+			detail.JudgeLogs = append(detail.JudgeLogs, detailedTaskLog)
+		}
+
+		benchmarkTaskDict := make(map[int64]model.BenchmarkTask)
+		for _, task := range problemData.Detail.BenchmarkTasks {
+			benchmarkTaskDict[task.ID] = task
+		}
+
+		for _, benchmarkResult := range grResult.Log.BenchmarkResults {
+			corresponding_task, exists := benchmarkTaskDict[benchmarkResult.TaskID]
+			if !exists {
+				return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("Inconsistent data: benchmark task not found"))
+			}
+
+			detail.BenchmarkLogs = append(detail.BenchmarkLogs, makeBenchmarkDetail(benchmarkResult, corresponding_task))
+		}
+
+		mutationTaskDict := make(map[int64]model.MutationTask)
+		for _, task := range problemData.Detail.MutationTasks {
+			mutationTaskDict[task.ID] = task
+		}
+
+		for _, mutationResult := range grResult.Log.MutationResults {
+			corresponding_task, exists := mutationTaskDict[mutationResult.TaskID]
 			if !exists {
-				return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("Failed to get problem info"))
+				return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("Inconsistent data: mutation task not found"))
 			}
 
-			detailedTaskLog, err := makeDetailedTaskLog(judgeResult, corresponding_task, resource_dir)
+			mutationDetail, err := makeMutationDetail(mutationResult, corresponding_task, displayLimitBytes(problemData.Detail))
 			if err != nil {
-				return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("Failed to create detailed task log: "+err.Error()))
+				return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("Failed to create mutation log: "+err.Error()))
 			}
 
-			detail.JudgeLogs = append(detail.JudgeLogs, detailedTaskLog)
+			detail.MutationLogs = append(detail.MutationLogs, mutationDetail)
 		}
 
 		output.DetailList = append(output.DetailList, detail)
@@ -785,24 +973,32 @@ func (h *Handler) GetGradingResult(c echo.Context) error {
 	return c.JSON(http.StatusOK, output)
 }
 
-func makeDetailedTaskLog(taskResult model.TaskLog, testCase model.TestCase, resouce_dir string) (DetailedTaskLog, error) {
+// Returns the max size of stdout/stderr shown in results of the problem.
+func displayLimitBytes(detail model.Detail) int64 {
+	if detail.DisplayLimitKB <= 0 {
+		return DEFAULT_DISPLAY_LIMIT_KB * 1024
+	}
+	return detail.DisplayLimitKB * 1024
+}
+
+func makeDetailedTaskLog(taskResult model.TaskLog, testCase model.TestCase, resouce_dir string, displayLimit int64) (DetailedTaskLog, error) {
 	var stdinData *string = nil
 
 	if testCase.StdinPath != "" {
 		// If StdinPath is specified, try to fetch the stdin file
 		stdinPath := filepath.Join(resouce_dir, testCase.StdinPath)
-		stdin, err := util.FetchFile(stdinPath)
+		stdin, _, err := util.FetchFileWithLimit(stdinPath, displayLimit)
 		if err != nil {
 			return DetailedTaskLog{}, fmt.Errorf("failed to read stdin: %w", err)
 		}
 		stdinData = &stdin.Data
 	}
 
-	stdout, err := util.FetchFile(taskResult.StdoutPath)
+	stdout, stdoutTruncated, err := util.FetchFileWithLimit(taskResult.StdoutPath, displayLimit)
 	if err != nil {
 		return DetailedTaskLog{}, fmt.Errorf("failed to read stdout: %w", err)
 	}
-	stderr, err := util.FetchFile(taskResult.StderrPath)
+	stderr, stderrTruncated, err := util.FetchFileWithLimit(taskResult.StderrPath, displayLimit)
 	if err != nil {
 		return DetailedTaskLog{}, fmt.Errorf("failed to read stderr: %w", err)
 	}
@@ -810,7 +1006,7 @@ func makeDetailedTaskLog(taskResult model.TaskLog, testCase model.TestCase, reso
 	var expectedStdoutData *string = nil
 	if testCase.StdoutPath != "" {
 		stdoutPath := filepath.Join(resouce_dir, testCase.StdoutPath)
-		expected_stdout, err := util.FetchFile(stdoutPath)
+		expected_stdout, _, err := util.FetchFileWithLimit(stdoutPath, displayLimit)
 		if err != nil {
 			return DetailedTaskLog{}, fmt.Errorf("failed to read expected stdout: %w", err)
 		}
@@ -820,19 +1016,57 @@ func makeDetailedTaskLog(taskResult model.TaskLog, testCase model.TestCase, reso
 	var expectedStderrData *string = nil
 	if testCase.StderrPath != "" {
 		stderrPath := filepath.Join(resouce_dir, testCase.StderrPath)
-		expected_stderr, err := util.FetchFile(stderrPath)
+		expected_stderr, _, err := util.FetchFileWithLimit(stderrPath, displayLimit)
 		if err != nil {
 			return DetailedTaskLog{}, fmt.Errorf("failed to read expected stderr: %w", err)
 		}
 		expectedStderrData = &expected_stderr.Data
 	}
 
+	runs := []TaskRunLog{}
+	for _, run := range taskResult.Runs {
+		runs = append(runs, TaskRunLog{
+			ResultID:  int64(run.ResultID),
+			TimeMS:    run.TimeMS,
+			CPUTimeMS: run.CPUTimeMS,
+			MemoryKB:  run.MemoryKB,
+			ExitCode:  run.ExitCode,
+		})
+	}
+
+	memcheck := []MemcheckFinding{}
+	for _, finding := range taskResult.Memcheck {
+		memcheck = append(memcheck, MemcheckFinding{
+			Kind:        finding.Kind,
+			Message:     finding.Message,
+			Leak:        finding.Leak,
+			LeakedBytes: finding.LeakedBytes,
+			Location:    finding.Location,
+		})
+	}
+
+	messageOnFail := ""
+	if failedTask(taskResult) {
+		messageOnFail = renderMessageOnFail(testCase, taskResult)
+	}
+
+	processes := []ProcessDetail{}
+	for _, processResult := range taskResult.Processes {
+		processDetail, err := makeProcessDetail(processResult, testCase, displayLimit)
+		if err != nil {
+			return DetailedTaskLog{}, err
+		}
+		processes = append(processes, processDetail)
+	}
+
 	return DetailedTaskLog{
 		TestCaseID:       taskResult.TestCaseID,
 		Description:      testCase.Description,
+		MessageOnFail:    messageOnFail,
 		Command:          testCase.Command,
 		ResultID:         int64(taskResult.ResultID),
 		TimeMS:           taskResult.TimeMS,
+		CPUTimeMS:        taskResult.CPUTimeMS,
 		MemoryKB:         taskResult.MemoryKB,
 		ExitCode:         taskResult.ExitCode,
 		ExpectedExitCode: testCase.ExitCode,
@@ -842,5 +1076,153 @@ func makeDetailedTaskLog(taskResult model.TaskLog, testCase model.TestCase, reso
 		Stderr:           stderr.Data,
 		ExpectedStdout:   expectedStdoutData,
 		ExpectedStderr:   expectedStderrData,
+		Truncated:        taskResult.Truncated || stdoutTruncated || stderrTruncated,
+		Flaky:            taskResult.Flaky,
+		Runs:             runs,
+		Memcheck:         memcheck,
+		Processes:        processes,
+		Threads:          makeThreadDetails(taskResult.Threads),
+	}, nil
+}
+
+func makeThreadDetails(threads []model.ThreadLog) []ThreadDetail {
+	details := []ThreadDetail{}
+	for _, thread := range threads {
+		details = append(details, ThreadDetail{
+			TID:       thread.TID,
+			Name:      thread.Name,
+			CPUTimeMS: thread.CPUTimeMS,
+		})
+	}
+	return details
+}
+
+func makeProcessDetail(processResult model.ProcessLog, testCase model.TestCase, displayLimit int64) (ProcessDetail, error) {
+	stdout, stdoutTruncated, err := util.FetchFileWithLimit(processResult.StdoutPath, displayLimit)
+	if err != nil {
+		return ProcessDetail{}, fmt.Errorf("failed to read stdout of process %s: %w", processResult.Name, err)
+	}
+	stderr, stderrTruncated, err := util.FetchFileWithLimit(processResult.StderrPath, displayLimit)
+	if err != nil {
+		return ProcessDetail{}, fmt.Errorf("failed to read stderr of process %s: %w", processResult.Name, err)
+	}
+
+	processDetail := ProcessDetail{
+		Name:      processResult.Name,
+		ResultID:  int64(processResult.ResultID),
+		Ready:     processResult.Ready,
+		TimeMS:    processResult.TimeMS,
+		CPUTimeMS: processResult.CPUTimeMS,
+		MemoryKB:  processResult.MemoryKB,
+		ExitCode:  processResult.ExitCode,
+		Stdout:    stdout.Data,
+		Stderr:    stderr.Data,
+		Truncated: processResult.Truncated || stdoutTruncated || stderrTruncated,
+		Threads:   makeThreadDetails(processResult.Threads),
+	}
+
+	for _, process := range testCase.Processes {
+		if process.Name == processResult.Name {
+			processDetail.Command = process.Command
+			processDetail.Background = process.Background
+		}
+	}
+
+	return processDetail, nil
+}
+
+func makeBenchmarkDetail(benchmarkResult model.BenchmarkLog, task model.BenchmarkTask) BenchmarkDetail {
+	estimatedClass := ""
+	if benchmarkResult.EstimatedClass != "" {
+		estimatedClass = benchmarkResult.EstimatedClass.String()
+	}
+
+	expectedClasses := []string{}
+	for _, class := range task.Expected {
+		expectedClasses = append(expectedClasses, class.String())
+	}
+
+	points := []BenchmarkPoint{}
+	for _, point := range benchmarkResult.Points {
+		points = append(points, BenchmarkPoint{
+			N:        point.N,
+			ResultID: int64(point.ResultID),
+			TimeMS:   point.TimeMS,
+			MemoryKB: point.MemoryKB,
+		})
+	}
+
+	fits := []ComplexityFit{}
+	for _, fit := range benchmarkResult.Fits {
+		fits = append(fits, ComplexityFit{
+			Class:    fit.Class.String(),
+			Residual: fit.Residual,
+		})
+	}
+
+	return BenchmarkDetail{
+		TaskID:          benchmarkResult.TaskID,
+		Title:           task.Title,
+		Description:     task.Description,
+		ResultID:        int64(benchmarkResult.ResultID),
+		EstimatedClass:  estimatedClass,
+		ExpectedClasses: expectedClasses,
+		Points:          points,
+		Fits:            fits,
+		Error:           benchmarkResult.Error,
+	}
+}
+
+func makeMutationDetail(mutationResult model.MutationLog, task model.MutationTask, displayLimit int64) (MutationDetail, error) {
+	reference, err := makeMutationRun(mutationResult.Reference, displayLimit)
+	if err != nil {
+		return MutationDetail{}, err
+	}
+
+	mutants := []MutationRun{}
+	for _, mutantResult := range mutationResult.Mutants {
+		mutant, err := makeMutationRun(mutantResult, displayLimit)
+		if err != nil {
+			return MutationDetail{}, err
+		}
+		mutants = append(mutants, mutant)
+	}
+
+	survived := []string{}
+	survived = append(survived, mutationResult.Survived...)
+
+	return MutationDetail{
+		TaskID:      mutationResult.TaskID,
+		Title:       task.Title,
+		Description: task.Description,
+		ResultID:    int64(mutationResult.ResultID),
+		Score:       mutationResult.Score,
+		MinScore:    task.MinScore,
+		Reference:   reference,
+		Mutants:     mutants,
+		Survived:    survived,
+	}, nil
+}
+
+func makeMutationRun(run model.MutationRun, displayLimit int64) (MutationRun, error) {
+	stdout, _, err := util.FetchFileWithLimit(run.StdoutPath, displayLimit)
+	if err != nil {
+		return MutationRun{}, fmt.Errorf("failed to read stdout: %w", err)
+	}
+
+	stderr, _, err := util.FetchFileWithLimit(run.StderrPath, displayLimit)
+	if err != nil {
+		return MutationRun{}, fmt.Errorf("failed to read stderr: %w", err)
+	}
+
+	return MutationRun{
+		Name:     run.Name,
+		ResultID: int64(run.ResultID),
+		Killed:   run.Killed,
+		TimeMS:   run.TimeMS,
+		MemoryKB: run.MemoryKB,
+		ExitCode: run.ExitCode,
+		Stdout:   stdout.Data,
+		Stderr:   stderr.Data,
 	}, nil
 }
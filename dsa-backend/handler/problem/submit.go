@@ -98,6 +98,14 @@ func (h *Handler) RequestValidation(c echo.Context) error {
 	userCode := claim.ID
 	userID := claim.UserID
 
+	// Students cannot submit to problems whose reference solution is not verified
+	if !claim.HasAllScopes(auth.ScopeGrading) && !claim.HasAllScopes(auth.ScopeAdmin) {
+		problem, err := h.problemStore.GetProblemByID(ctx, req.LectureID, req.ProblemID)
+		if err != nil || !problem.IsVisible() {
+			return echo.NewHTTPError(http.StatusNotFound, response.NewError("Problem not found"))
+		}
+	}
+
 	requestTime := time.Now()
 
 	// ---------------------------------------------------------------------------------------------
@@ -162,14 +170,21 @@ func (h *Handler) RequestValidation(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("Failed to register file location"))
 	}
 
+	// Get Problem info
+	problem, err := h.problemStore.GetProblemByID(ctx, req.LectureID, req.ProblemID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("Failed to get problem info"))
+	}
+
 	// Make request entry
 	request := model.ValidationRequest{
-		TS:          requestTime,
-		UserCode:    userCode,
-		LectureID:   req.LectureID,
-		ProblemID:   req.ProblemID,
-		UploadDirID: fileLocation.ID,
-		ResultID:    requeststatus.WJ,
+		TS:             requestTime,
+		UserCode:       userCode,
+		LectureID:      req.LectureID,
+		ProblemID:      req.ProblemID,
+		ProblemVersion: problem.Version,
+		UploadDirID:    fileLocation.ID,
+		ResultID:       requeststatus.WJ,
 	}
 
 	// Register request
@@ -182,11 +197,6 @@ func (h *Handler) RequestValidation(c echo.Context) error {
 	// Submit this request to job queue.
 	// --------------------------------------
 
-	// Get Problem info
-	problem, err := h.problemStore.GetProblemByID(ctx, req.LectureID, req.ProblemID)
-	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("Failed to get problem info"))
-	}
 	// All results are stored in {basePath}/result
 	resultDir := filepath.Join(basePath, "result")
 
@@ -218,16 +228,23 @@ func (h *Handler) RequestValidation(c echo.Context) error {
 		RequestID:   request.ID,
 		Status:      queuestatus.Pending,
 		CreatedAt:   time.Now(),
-		Detail: model.JobDetail{
-			TimeMS:      problem.Detail.TimeMS,
-			MemoryMB:    problem.Detail.MemoryMB,
-			TestFiles:   problem.Detail.TestFiles,
-			ResourceDir: resourcePath, // resource files for this problem
-			FileDir:     realFileDir,
-			ResultDir:   resultDir,
-			BuildTasks:  filteredBuildTasks,
-			JudgeTasks:  filteredJudgeTasks,
-		},
+		Detail: model.MakeJobDetail(
+			problem.Detail,
+			resourcePath, // resource files for this problem
+			realFileDir,
+			resultDir,
+			filteredBuildTasks,
+			filteredJudgeTasks,
+		),
+	}
+
+	// Reuse the result of an identical job, if any
+	if cached := h.lookupResultCache(ctx, &job, problem.LectureID, problem.ProblemID, problem.Version); cached != nil {
+		err = h.requestStore.UpdateResultOfValidationRequest(ctx, request.ID, cached.ResultID, cached.Log)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("Failed to update validation request"))
+		}
+		return c.JSON(http.StatusOK, response.NewSuccess("Validation request registered successfully"))
 	}
 
 	// Register job
@@ -417,14 +434,20 @@ func (h *Handler) BatchValidation(c echo.Context) error {
 	RequiringFilter := !claim.HasAllScopes(auth.ScopeGrading) && !claim.HasAllScopes(auth.ScopeAdmin)
 
 	for _, problem := range problems {
+		if RequiringFilter && !problem.IsVisible() {
+			// Hidden from students until the reference solution is verified
+			continue
+		}
+
 		// Make request entry
 		request := model.ValidationRequest{
-			TS:          requestTime,
-			UserCode:    userCode,
-			LectureID:   req.LectureID,
-			ProblemID:   problem.ProblemID,
-			UploadDirID: fileLocation.ID,
-			ResultID:    requeststatus.WJ,
+			TS:             requestTime,
+			UserCode:       userCode,
+			LectureID:      req.LectureID,
+			ProblemID:      problem.ProblemID,
+			ProblemVersion: problem.Version,
+			UploadDirID:    fileLocation.ID,
+			ResultID:       requeststatus.WJ,
 		}
 
 		// Fetch resource path for this problem
@@ -475,16 +498,23 @@ func (h *Handler) BatchValidation(c echo.Context) error {
 			RequestID:   request.ID,
 			Status:      queuestatus.Pending,
 			CreatedAt:   time.Now(),
-			Detail: model.JobDetail{
-				TimeMS:      problem.Detail.TimeMS,
-				MemoryMB:    problem.Detail.MemoryMB,
-				TestFiles:   problem.Detail.TestFiles,
-				ResourceDir: resourcePath,
-				FileDir:     realFileDir,
-				ResultDir:   resultDir,
-				BuildTasks:  filteredBuildTasks,
-				JudgeTasks:  filteredJudgeTasks,
-			},
+			Detail: model.MakeJobDetail(
+				problem.Detail,
+				resourcePath, // resource files for this problem
+				realFileDir,
+				resultDir,
+				filteredBuildTasks,
+				filteredJudgeTasks,
+			),
+		}
+
+		// Reuse the result of an identical job, if any
+		if cached := h.lookupResultCache(ctx, &job, problem.LectureID, problem.ProblemID, problem.Version); cached != nil {
+			err = h.requestStore.UpdateResultOfValidationRequest(ctx, request.ID, cached.ResultID, cached.Log)
+			if err != nil {
+				return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("Failed to update validation request"))
+			}
+			continue
 		}
 
 		// Register job
@@ -677,6 +707,12 @@ func (h *Handler) RequestGrading(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("Failed to register file location"))
 	}
 
+	// Get Problem info
+	problem, err := h.problemStore.GetProblemByID(ctx, req.LectureID, req.ProblemID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("Failed to get problem info"))
+	}
+
 	// Make request entry
 	request := model.GradingRequest{
 		LectureID:       req.LectureID,
@@ -685,6 +721,7 @@ func (h *Handler) RequestGrading(c echo.Context) error {
 		SubmissionTS:    submissionTS,
 		TS:              requestTime,
 		RequestUserCode: userCodeOfRequester,
+		ProblemVersion:  problem.Version,
 		UploadDirID:     fileLocation.ID,
 		ResultID:        requeststatus.WJ,
 		// Initialize empty RequestLog, to delete old logs.
@@ -703,11 +740,6 @@ func (h *Handler) RequestGrading(c echo.Context) error {
 	// Submit this request to job queue.
 	// --------------------------------------
 
-	// Get Problem info
-	problem, err := h.problemStore.GetProblemByID(ctx, req.LectureID, req.ProblemID)
-	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("Failed to get problem info"))
-	}
 	// All results are stored in {basePath}/result
 	resultDir := filepath.Join(basePath, "result")
 
@@ -717,16 +749,26 @@ func (h *Handler) RequestGrading(c echo.Context) error {
 		RequestID:   request.ID,
 		Status:      queuestatus.Pending,
 		CreatedAt:   time.Now(),
-		Detail: model.JobDetail{
-			TimeMS:      problem.Detail.TimeMS,
-			MemoryMB:    problem.Detail.MemoryMB,
-			TestFiles:   problem.Detail.TestFiles,
-			ResourceDir: resourcePath, // resource files for this problem
-			FileDir:     realFileDir,
-			ResultDir:   resultDir,
-			BuildTasks:  problem.Detail.BuildTasks, // We do not any filtering here, because only manager or admin can access this endpoint.
-			JudgeTasks:  problem.Detail.JudgeTasks,
-		},
+		Detail: model.MakeJobDetail(
+			problem.Detail,
+			resourcePath, // resource files for this problem
+			realFileDir,
+			resultDir,
+			problem.Detail.BuildTasks, // We do not any filtering here, because only manager or admin can access this endpoint.
+			problem.Detail.JudgeTasks,
+		),
+	}
+	// Benchmarks and mutation testing take a long time, so they are run for grading requests only.
+	job.Detail.BenchmarkTasks = problem.Detail.BenchmarkTasks
+	job.Detail.MutationTasks = problem.Detail.MutationTasks
+
+	// Reuse the result of an identical job, if any
+	if cached := h.lookupResultCache(ctx, &job, problem.LectureID, problem.ProblemID, problem.Version); cached != nil {
+		err = h.requestStore.UpdateResultOfGradingRequest(ctx, request.ID, cached.ResultID, cached.Log)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("Failed to update grading request"))
+		}
+		return c.JSON(http.StatusOK, response.NewSuccess("Grading request registered successfully"))
 	}
 
 	// Register job
@@ -950,6 +992,7 @@ func (h *Handler) BatchGrading(c echo.Context) error {
 			SubmissionTS:    submissionTS,
 			TS:              requestTime,
 			RequestUserCode: userCodeOfRequester,
+			ProblemVersion:  problem.Version,
 			UploadDirID:     fileLocation.ID,
 			ResultID:        requeststatus.WJ,
 			// Initialize empty RequestLog, to delete old logs.
@@ -986,16 +1029,26 @@ func (h *Handler) BatchGrading(c echo.Context) error {
 			RequestID:   request.ID,
 			Status:      queuestatus.Pending,
 			CreatedAt:   time.Now(),
-			Detail: model.JobDetail{
-				TimeMS:      problem.Detail.TimeMS,
-				MemoryMB:    problem.Detail.MemoryMB,
-				TestFiles:   problem.Detail.TestFiles,
-				ResourceDir: resourcePath,
-				FileDir:     realFileDir,
-				ResultDir:   resultDir,
-				BuildTasks:  problem.Detail.BuildTasks, // We do not any filtering here, because only manager or admin can access this endpoint.
-				JudgeTasks:  problem.Detail.JudgeTasks,
-			},
+			Detail: model.MakeJobDetail(
+				problem.Detail,
+				resourcePath, // resource files for this problem
+				realFileDir,
+				resultDir,
+				problem.Detail.BuildTasks, // We do not any filtering here, because only manager or admin can access this endpoint.
+				problem.Detail.JudgeTasks,
+			),
+		}
+		// Benchmarks and mutation testing take a long time, so they are run for grading requests only.
+		job.Detail.BenchmarkTasks = problem.Detail.BenchmarkTasks
+		job.Detail.MutationTasks = problem.Detail.MutationTasks
+
+		// Reuse the result of an identical job, if any
+		if cached := h.lookupResultCache(ctx, &job, problem.LectureID, problem.ProblemID, problem.Version); cached != nil {
+			err = h.requestStore.UpdateResultOfGradingRequest(ctx, request.ID, cached.ResultID, cached.Log)
+			if err != nil {
+				return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("Failed to update grading request"))
+			}
+			continue
 		}
 
 		// Register job
@@ -1014,3 +1067,68 @@ func (h *Handler) BatchGrading(c echo.Context) error {
 
 	return c.JSON(http.StatusOK, response.NewSuccess("Batched grading requests registered successfully"))
 }
+
+type RerunGradingParam struct {
+	ID    int64 `param:"id" validate:"required"`
+	Times int64 `form:"times" validate:"required,min=2,max=10"`
+}
+
+func (rgp *RerunGradingParam) bind(c echo.Context) error {
+	if err := c.Bind(rgp); err != nil {
+		return err
+	}
+	if err := c.Validate(rgp); err != nil {
+		return err
+	}
+	return nil
+}
+
+// RerunGrading godoc
+//
+//	@Summary		Rerun a grading request N times
+//	@Description	Run every judge task of an existing grading request the specified number of times, to check whether timing-sensitive verdicts are stable. Every run is recorded in the result, and tasks whose runs got different verdicts are marked as flaky. The result of the grading request is replaced with the worst verdict of all runs, and the previous one is kept in its history as a rejudge.
+//	@Tags			Submit
+//	@Accept			multipart/form-data
+//	@Produce		json
+//	@Param			id		path		int					true	"Grading Request ID"
+//	@Param			times	formData	int					true	"Number of runs of each judge task (2-10)"
+//	@Success		200		{object}	RejudgeOutput
+//	@Failure		400		{object}	response.Error		"Invalid request payload"
+//	@Failure		404		{object}	response.Error		"Grading request not found"
+//	@Failure		409		{object}	response.Error		"Grading request is still being judged"
+//	@Failure		500		{object}	response.Error		"Internal server error"
+//	@Security		OAuth2Password[grading]
+//	@Router			/problem/judge/rerun/{id} [post]
+func (h *Handler) RerunGrading(c echo.Context) error {
+	req := &RerunGradingParam{}
+	if err := req.bind(c); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, response.NewError("Invalid request payload: "+err.Error()))
+	}
+
+	ctx := context.Background()
+
+	request, err := h.requestStore.GetGradingResultByID(ctx, req.ID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, response.NewError("Grading request not found"))
+	}
+
+	// Do not rerun while the previous job is still in the queue, otherwise both results overwrite each other
+	if request.ResultID == requeststatus.WJ || request.ResultID == requeststatus.Judging {
+		return echo.NewHTTPError(http.StatusConflict, response.NewError("Grading request is still being judged"))
+	}
+
+	fileLocation, err := h.fileStore.GetFileLocation(ctx, request.UploadDirID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("Failed to get file location"))
+	}
+	request.FileLocation = fileLocation
+
+	// A rerun is a rejudge of the request, so that the previous result is kept in its history
+	rejudge := model.Rejudge{
+		LectureID: request.LectureID,
+		ProblemID: &request.ProblemID,
+		RequestID: &request.ID,
+		Results:   []requeststatus.State{},
+	}
+	return h.rejudge(c, &rejudge, []model.GradingRequest{*request}, req.Times)
+}
@@ -3,7 +3,9 @@ package problem
 import (
 	"context"
 	"dsa-backend/fileutil"
+	"dsa-backend/handler/problem/util"
 	"dsa-backend/handler/response"
+	"fmt"
 	"net/http"
 	"os"
 	"path/filepath"
@@ -11,6 +13,9 @@ import (
 	"time"
 
 	"github.com/dsa-uts/dsa-project/database/model"
+	"github.com/dsa-uts/dsa-project/database/model/complexity"
+	"github.com/dsa-uts/dsa-project/database/model/leakpolicy"
+	"github.com/dsa-uts/dsa-project/database/model/problemstatus"
 	"github.com/labstack/echo/v4"
 	"github.com/spf13/afero"
 )
@@ -20,6 +25,8 @@ type LectureEntryRequest struct {
 	Title     string `json:"title" validate:"required"`
 	StartDate int64  `json:"start_date" validate:"required"`
 	Deadline  int64  `json:"deadline" validate:"required"`
+
+	LatePolicy *util.LatePolicy `json:"late_policy"` // null if late submissions are not penalized
 }
 
 func (le *LectureEntryRequest) bind(c echo.Context) error {
@@ -29,6 +36,11 @@ func (le *LectureEntryRequest) bind(c echo.Context) error {
 	if err := c.Validate(le); err != nil {
 		return err
 	}
+	if le.LatePolicy != nil {
+		if err := le.LatePolicy.Policy().Validate(); err != nil {
+			return fmt.Errorf("late_policy: %w", err)
+		}
+	}
 	return nil
 }
 
@@ -74,10 +86,11 @@ func (h *Handler) CreateLectureEntry(c echo.Context) error {
 	}
 
 	err := h.problemStore.CreateLectureEntry(ctx, &model.Lecture{
-		ID:        lectureEntry.ID,
-		Title:     lectureEntry.Title,
-		StartDate: time.Unix(lectureEntry.StartDate, 0),
-		Deadline:  time.Unix(lectureEntry.Deadline, 0),
+		ID:         lectureEntry.ID,
+		Title:      lectureEntry.Title,
+		StartDate:  time.Unix(lectureEntry.StartDate, 0),
+		Deadline:   time.Unix(lectureEntry.Deadline, 0),
+		LatePolicy: lectureEntry.LatePolicy.Policy(),
 	})
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, response.NewError("failed to create lecture entry: "+err.Error()))
@@ -122,6 +135,7 @@ func (h *Handler) UpdateLectureEntry(c echo.Context) error {
 	lectureEntryInDB.Title = lectureEntryRequest.Title
 	lectureEntryInDB.StartDate = time.Unix(lectureEntryRequest.StartDate, 0)
 	lectureEntryInDB.Deadline = time.Unix(lectureEntryRequest.Deadline, 0)
+	lectureEntryInDB.LatePolicy = lectureEntryRequest.LatePolicy.Policy()
 
 	err = h.problemStore.UpdateLectureEntry(ctx, &lectureEntryInDB)
 	if err != nil {
@@ -173,8 +187,10 @@ type LectureIDProblemID struct {
 
 // RegisterProblem godoc
 //
-//	@Summary		Register a new problem
-//	@Description	Register a new problem associated with a lecture
+//	@Summary		Register a new problem or a new version of a problem
+//	@Description	Register a new problem associated with a lecture.
+//	@Description	If the problem already exists, the upload is registered as its new version, and previous submissions keep referring to the versions they were judged with.
+//	@Description	The settings are read from init.json, or from init.yaml with the same keys, at the root of the package.
 //	@Tags			Update
 //	@Accept			multipart/form-data
 //	@Produce		json
@@ -183,6 +199,7 @@ type LectureIDProblemID struct {
 //	@Param			file		formData	file				true	"Zip file contains problem resources"
 //	@Success		200			{object}	response.Success	"Problem registered successfully"
 //	@Failure		400			{object}	response.Error		"Invalid request"
+//	@Failure		409			{object}	response.Error		"Another version is being uploaded"
 //	@Failure		500			{object}	response.Error		"Internal server error"
 //	@Security		OAuth2Password[grading]
 //	@Router			/problem/crud/create/{lectureid}/{problemid} [post]
@@ -194,120 +211,26 @@ func (h *Handler) RegisterProblem(c echo.Context) error {
 
 	context := context.Background()
 
-	// Check the existence of problem entry
+	// Check the existence of problem entry, which is updated to a new version if it exists
 	exists, err := h.problemStore.CheckProblemExists(context, req.LectureID, req.ProblemID)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("failed to check problem existence: "+err.Error()))
 	}
-	if exists {
-		return echo.NewHTTPError(http.StatusNotFound, response.NewError("problem already exists"))
-	}
-
-	// Read zip file
-	zipFile, err := c.FormFile("file")
-	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, response.NewError("failed to read file: "+err.Error()))
-	}
-	src, err := zipFile.Open()
-	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("failed to open file: "+err.Error()))
-	}
-	defer src.Close()
-
-	// Create temporary in-memory fs
-	memFs := afero.NewMemMapFs()
-
-	// Extract zip file to temporary fs
-	if err = fileutil.SafeExtractZip(memFs, src, zipFile.Size, "/"); err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("failed to unzip file: "+err.Error()))
-	}
-
-	// Check if the first level contains only one folder
-	baseDirInMemFs := "/"
-	files, err := afero.ReadDir(memFs, baseDirInMemFs)
-	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("failed to read extracted directory: "+err.Error()))
-	}
-
-	if len(files) == 1 && files[0].IsDir() {
-		// Unnest the folder
-		baseDirInMemFs = filepath.Join("/", files[0].Name())
-	}
 
-	// Check if init.json exists
-	initPath := filepath.Join(baseDirInMemFs, "init.json")
-	if stat, err := memFs.Stat(initPath); os.IsNotExist(err) || stat.IsDir() {
-		return echo.NewHTTPError(http.StatusBadRequest, response.NewError("init.json not found or is a directory"))
-	}
-
-	// Parse init.json into AssignmentConfig
-	initFile, err := memFs.Open(initPath)
+	memFs, baseDirInMemFs, err := readProblemPackage(c)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("failed to open init.json: "+err.Error()))
+		return err
 	}
-	defer initFile.Close()
 
-	initData, err := afero.ReadAll(initFile)
+	// Check init.json or init.yaml and the files it refers to, and report all problems at once
+	config, issues, err := lintProblemPackage(memFs, baseDirInMemFs)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("failed to read init.json: "+err.Error()))
+		return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("failed to check problem package: "+err.Error()))
 	}
-
-	var config AssignmentConfig = AssignmentConfig{}
-	if err := config.Decode(initData); err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("failed to parse init.json: "+err.Error()))
-	}
-
-	// Validate config
-	// 1. Check MDfile exists
-	// 2. Check test files exists
-	// 3. Check Stdin, Stdout, Stderr files in test cases exist (if not empty)
-	{
-		// Check MDfile exists and is a file
-		mdFilePath := filepath.Join(baseDirInMemFs, config.MDfile)
-		if stat, err := memFs.Stat(mdFilePath); os.IsNotExist(err) || stat.IsDir() {
-			return echo.NewHTTPError(http.StatusBadRequest, response.NewError("md_file not found or is a directory: "+config.MDfile))
-		}
-
-		// Check test files exist and are files
-		for _, testFile := range config.TestFiles {
-			testFilePath := filepath.Join(baseDirInMemFs, testFile)
-			if stat, err := memFs.Stat(testFilePath); os.IsNotExist(err) || stat.IsDir() {
-				return echo.NewHTTPError(http.StatusBadRequest, response.NewError("test file not found or is a directory: "+testFile))
-			}
-		}
-
-		allTasks := append(config.Build, config.Judge...)
-
-		// Check Stdin, Stdout, Stderr files in tasks
-		for _, t := range allTasks {
-			if t.Stdin != "" {
-				stdinPath := filepath.Join(baseDirInMemFs, t.Stdin)
-				if stat, err := memFs.Stat(stdinPath); os.IsNotExist(err) || stat.IsDir() {
-					return echo.NewHTTPError(http.StatusBadRequest, response.NewError("stdin file not found or is a directory: "+t.Stdin))
-				}
-			}
-			if t.Stdout != "" {
-				stdoutPath := filepath.Join(baseDirInMemFs, t.Stdout)
-				if stat, err := memFs.Stat(stdoutPath); os.IsNotExist(err) || stat.IsDir() {
-					return echo.NewHTTPError(http.StatusBadRequest, response.NewError("stdout file not found or is a directory: "+t.Stdout))
-				}
-			}
-			if t.Stderr != "" {
-				stderrPath := filepath.Join(baseDirInMemFs, t.Stderr)
-				if stat, err := memFs.Stat(stderrPath); os.IsNotExist(err) || stat.IsDir() {
-					return echo.NewHTTPError(http.StatusBadRequest, response.NewError("stderr file not found or is a directory: "+t.Stderr))
-				}
-			}
-		}
+	if len(issues) > 0 {
+		return echo.NewHTTPError(http.StatusBadRequest, response.NewError(formatLintIssues(issues)))
 	}
 
-	// TODO: parse readme, and capture every link referencing image file in this zip file.
-	// e.g., [image1.png](image1.png)
-	// After that, we need to do those tasks below:
-	// 1. Register referenced file into FileReference Table.
-	// 2. Convert image links to use the new file reference URLs.
-	// e.g., Convert ![image1.png](image1.png) to ![image1.png](<base_url>/fileref/<id>)
-
 	// Set the destination directory
 	timestamp := time.Now().Format("2006-01-02T15-04-05")
 	lectureIDstr := strconv.FormatInt(req.LectureID, 10)
@@ -321,16 +244,11 @@ func (h *Handler) RegisterProblem(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("failed to get absolute path of destination directory: "+err.Error()))
 	}
 
-	// Check if the destination directory already exists
-	if info, err := os.Stat(destDir); err != nil {
-		if os.IsNotExist(err) {
-			// Directory does not exist, which is expected
-		} else if info.IsDir() {
-			// Directory already exists
-			return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("destination directory already exists: "+destDir))
-		} else {
-			return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("Failed to check upload directory"))
-		}
+	// Check if the destination directory already exists, e.g., when two versions are uploaded in the same second
+	if _, err := os.Stat(destDir); err == nil {
+		return echo.NewHTTPError(http.StatusConflict, response.NewError("destination directory already exists: "+destDir))
+	} else if !os.IsNotExist(err) {
+		return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("Failed to check upload directory"))
 	}
 
 	// Make destination directory
@@ -371,7 +289,7 @@ func (h *Handler) RegisterProblem(c echo.Context) error {
 		if t.ExitCode != nil {
 			exitCode = *t.ExitCode
 		}
-		return model.MakeTestCase(
+		testcase := model.MakeTestCase(
 			int64(id),     // ID
 			t.Title,       // Title
 			t.Description, // Description
@@ -383,6 +301,22 @@ func (h *Handler) RegisterProblem(c echo.Context) error {
 			exitCode,      // ExitCode,
 			ignoreExit,    // IgnoreExit
 		)
+		testcase.Args = t.Args
+		testcase.Env = t.Env
+		testcase.FixturesPath = t.Fixtures
+		testcase.Repeat = t.Repeat
+		testcase.Memcheck = t.Memcheck
+		testcase.MessageOnFail = t.MessageOnFail
+		for _, p := range t.Processes {
+			testcase.Processes = append(testcase.Processes, model.Process{
+				Name:       p.Name,
+				Command:    p.Command,
+				StdinPath:  p.Stdin,
+				Ready:      p.Ready,
+				Background: p.Background,
+			})
+		}
+		return testcase
 	}
 
 	for i, t := range config.Build {
@@ -395,14 +329,72 @@ func (h *Handler) RegisterProblem(c echo.Context) error {
 		judgeTasks = append(judgeTasks, testcase)
 	}
 
+	var benchmarkTasks []model.BenchmarkTask
+	for i, b := range config.Benchmark {
+		expected := []complexity.Class{}
+		for _, class := range b.Expected {
+			expected = append(expected, complexity.Class(class))
+		}
+		benchmarkTasks = append(benchmarkTasks, model.BenchmarkTask{
+			ID:          int64(i + 1),
+			Title:       b.Title,
+			Description: b.Description,
+			Command:     b.Command,
+			Generator:   b.Generator,
+			Sizes:       b.Sizes,
+			TimeMS:      *b.TimeMS,
+			Expected:    expected,
+		})
+	}
+
+	var mutationTasks []model.MutationTask
+	for i, m := range config.Mutation {
+		mutationTasks = append(mutationTasks, model.MutationTask{
+			ID:            int64(i + 1),
+			Title:         m.Title,
+			Description:   m.Description,
+			Command:       m.Command,
+			ReferencePath: m.Reference,
+			MutantPaths:   m.Mutants,
+			TimeMS:        *m.TimeMS,
+			MinScore:      m.MinScore,
+		})
+	}
+
+	// CPU time is not limited unless specified, only wall-clock time is
+	cpuTimeMS := int64(0)
+	if config.CPUTimeMS != nil {
+		cpuTimeMS = *config.CPUTimeMS
+	}
+
 	detail := model.Detail{
 		DescriptionPath: config.MDfile,
 		TimeMS:          *config.TimeMS,
+		CPUTimeMS:       cpuTimeMS,
+		CPUCores:        *config.CPUCores,
 		MemoryMB:        *config.MemoryMB,
 		TestFiles:       config.TestFiles,
 		RequiredFiles:   config.RequiredFiles,
 		BuildTasks:      buildtasks,
 		JudgeTasks:      judgeTasks,
+		OutputLimitKB:   *config.OutputLimitKB,
+		DisplayLimitKB:  *config.DisplayLimitKB,
+		BenchmarkTasks:  benchmarkTasks,
+		MutationTasks:   mutationTasks,
+
+		ReferenceSolutionPath: config.ReferenceSolution,
+
+		LeakPolicy: leakpolicy.Policy(*config.LeakPolicy),
+	}
+
+	// Problems with a reference solution are hidden from students until it passes all tasks,
+	// and problems with generated outputs are hidden until they are reviewed.
+	// Visible problems keep their current version until the new one is verified or reviewed.
+	status := problemstatus.Unverified
+	if config.GenerateOutputs {
+		status = problemstatus.Generating
+	} else if config.ReferenceSolution != "" {
+		status = problemstatus.Verifying
 	}
 
 	problem := &model.Problem{
@@ -412,14 +404,44 @@ func (h *Handler) RegisterProblem(c echo.Context) error {
 		Title:              config.Title,
 		ResourceLocationID: fileLocation.ID,
 		Detail:             detail,
+		Status:             status,
 	}
 
-	err = h.problemStore.RegisterProblem(context, problem)
+	if exists {
+		err = h.problemStore.AddProblemVersion(context, problem)
+	} else {
+		err = h.problemStore.RegisterProblem(context, problem)
+	}
 
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("failed to register problem: "+err.Error()))
 	}
 
+	// Results cached for the previous version are no longer valid
+	if err := h.resultCacheStore.DeleteResultCacheOfProblem(context, problem.LectureID, problem.ProblemID); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("failed to invalidate cached results: "+err.Error()))
+	}
+
+	// Images and attachments linked from the description are served through /fileref/{id},
+	// and the links are rewritten when the description is fetched
+	if err := h.registerFileReferences(context, problem.LectureID, problem.ProblemID, destDir, config.MDfile); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("failed to register files referenced from md_file: "+err.Error()))
+	}
+
+	if config.ReferenceSolution != "" {
+		if err := h.enqueueVerification(context, problem, destDir, config.GenerateOutputs); err != nil {
+			// Leave the problem hidden, since it has never been verified.
+			if err := h.problemStore.UpdateProblemStatus(context, problem.LectureID, problem.ProblemID, problem.Version, problemstatus.Broken); err != nil {
+				return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("failed to update problem status: "+err.Error()))
+			}
+			return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("failed to register verification job: "+err.Error()))
+		}
+		if config.GenerateOutputs {
+			return c.JSON(http.StatusOK, response.NewSuccess("Problem registered successfully, generating expected outputs from the reference solution"))
+		}
+		return c.JSON(http.StatusOK, response.NewSuccess("Problem registered successfully, verifying the reference solution"))
+	}
+
 	return c.JSON(http.StatusOK, response.NewSuccess("Problem registered successfully"))
 }
 
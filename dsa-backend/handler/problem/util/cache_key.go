@@ -0,0 +1,90 @@
+package util
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/dsa-uts/dsa-project/database/model"
+)
+
+// ComputeCacheKey returns a hash of everything in the job that determines its result:
+// the task definitions, the submitted files and the resource files of the problem,
+// which are given by their digest computed by HashDirectory, since they are shared by all jobs of a problem version.
+// Directories of the job are excluded, since they differ between requests.
+// Sandbox images are not covered here, they are checked by the judge server.
+func ComputeCacheKey(detail model.JobDetail, resourceDigest string) (string, error) {
+	h := sha256.New()
+
+	definition := detail
+	definition.ResourceDir = ""
+	definition.FileDir = ""
+	definition.ResultDir = ""
+	definition.CacheKey = ""
+
+	data, err := json.Marshal(definition)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal job detail: %w", err)
+	}
+	h.Write(data)
+
+	if err := hashDirectory(h, detail.FileDir); err != nil {
+		return "", err
+	}
+	h.Write([]byte{0})
+	h.Write([]byte(resourceDigest))
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// HashDirectory returns a hash of the relative paths and contents of all files in the directory.
+func HashDirectory(dir string) (string, error) {
+	h := sha256.New()
+	if err := hashDirectory(h, dir); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Writes the relative path, size and content of every file in the directory into h, in lexical order.
+func hashDirectory(h hash.Hash, dir string) error {
+	// separate directories, so that files cannot be moved from one to another without changing the hash
+	h.Write([]byte{0})
+
+	return filepath.WalkDir(dir, func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(dir, filePath)
+		if err != nil {
+			return err
+		}
+
+		file, err := os.Open(filePath)
+		if err != nil {
+			return fmt.Errorf("failed to open %s: %w", filePath, err)
+		}
+		defer file.Close()
+
+		stat, err := file.Stat()
+		if err != nil {
+			return fmt.Errorf("failed to stat %s: %w", filePath, err)
+		}
+
+		fmt.Fprintf(h, "%s\x00%d\x00", filepath.ToSlash(relPath), stat.Size())
+		if _, err := io.Copy(h, file); err != nil {
+			return fmt.Errorf("failed to read %s: %w", filePath, err)
+		}
+		return nil
+	})
+}
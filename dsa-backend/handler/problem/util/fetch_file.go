@@ -5,6 +5,8 @@ import (
 	"compress/gzip"
 	"encoding/base64"
 	"fmt"
+	"io"
+	"math"
 	"os"
 	"path/filepath"
 )
@@ -82,19 +84,38 @@ func FetchAllFilesInDirectory(dirPath string) ([]FileData, error) {
 // If the path is not a file, returns an error.
 // The file content is read, compressed using gzip, and then encoded in base64.
 func FetchFile(filePath string) (*FileData, error) {
+	fileData, _, err := FetchFileWithLimit(filePath, math.MaxInt64)
+	return fileData, err
+}
+
+// FetchFileWithLimit is same as FetchFile, but reads at most limit bytes of the file.
+// If the file is larger than limit, a truncation marker is appended to the data
+// and truncated is set to true.
+func FetchFileWithLimit(filePath string, limit int64) (fileData *FileData, truncated bool, err error) {
 	// Check if filePath is a file
 	stat, err := os.Stat(filePath)
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
 	if stat.IsDir() {
-		return nil, fmt.Errorf("provided path is a directory, not a file: %s", filePath)
+		return nil, false, fmt.Errorf("provided path is a directory, not a file: %s", filePath)
 	}
 
-	// read file
-	data, err := os.ReadFile(filePath)
+	file, err := os.Open(filePath)
 	if err != nil {
-		return nil, err
+		return nil, false, err
+	}
+	defer file.Close()
+
+	// read at most limit bytes
+	data, err := io.ReadAll(io.LimitReader(file, limit))
+	if err != nil {
+		return nil, false, err
+	}
+
+	if stat.Size() > limit {
+		truncated = true
+		data = fmt.Appendf(data, "\n... (truncated: showing first %d of %d bytes)\n", len(data), stat.Size())
 	}
 
 	// compress by gzip
@@ -103,14 +124,12 @@ func FetchFile(filePath string) (*FileData, error) {
 	gzipWriter.Write(data)
 	gzipWriter.Close()
 
-	data = buf.Bytes()
-
-	fileData := &FileData{
+	fileData = &FileData{
 		Name:         filepath.Base(filePath),
-		Data:         base64.StdEncoding.EncodeToString(data),
+		Data:         base64.StdEncoding.EncodeToString(buf.Bytes()),
 		Compression:  "gzip",
 		OriginalSize: stat.Size(),
 	}
 
-	return fileData, nil
+	return fileData, truncated, nil
 }
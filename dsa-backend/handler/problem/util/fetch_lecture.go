@@ -3,12 +3,15 @@ package util
 import (
 	"context"
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"slices"
 	"time"
 
 	"github.com/dsa-uts/dsa-project/database"
+	"github.com/dsa-uts/dsa-project/database/model"
+	"github.com/dsa-uts/dsa-project/database/model/latepolicy"
 )
 
 type LectureEntry struct {
@@ -17,21 +20,98 @@ type LectureEntry struct {
 	StartDate int64  `json:"start_date"`
 	Deadline  int64  `json:"deadline"`
 
+	LatePolicy *LatePolicy `json:"late_policy"` // null if late submissions are not penalized
+
 	Problems []ProblemEntry `json:"problems"`
 }
 
+// LatePolicy is the late-submission policy of a lecture or a problem, as latepolicy.Policy.
+type LatePolicy struct {
+	Kind          string  `json:"kind" enums:"hard,linear,stepped"`
+	GraceMinutes  int64   `json:"grace_minutes"`   // submissions within this period after the deadline are not penalized
+	PenaltyPerDay float64 `json:"penalty_per_day"` // fraction of the score deducted per day, e.g., 0.1 (linear and stepped)
+}
+
+// MakeLatePolicy returns the policy in responses, nil if p is nil.
+func MakeLatePolicy(p *latepolicy.Policy) *LatePolicy {
+	if p == nil {
+		return nil
+	}
+	return &LatePolicy{
+		Kind:          string(p.Kind),
+		GraceMinutes:  p.GraceMinutes,
+		PenaltyPerDay: p.PenaltyPerDay,
+	}
+}
+
+// Policy returns the policy in a request, nil if p is nil.
+func (p *LatePolicy) Policy() *latepolicy.Policy {
+	if p == nil {
+		return nil
+	}
+	return &latepolicy.Policy{
+		Kind:          latepolicy.Kind(p.Kind),
+		GraceMinutes:  p.GraceMinutes,
+		PenaltyPerDay: p.PenaltyPerDay,
+	}
+}
+
 type ProblemEntry struct {
 	LectureID    int64  `json:"lecture_id"`
 	ProblemID    int64  `json:"problem_id"`
 	RegisteredAt int64  `json:"registered_at"`
 	Title        string `json:"title"`
+	Status       string `json:"status"`  // verification status of the reference solution
+	Version      int64  `json:"version"` // current version
+
+	Deadline          int64       `json:"deadline"`            // deadline of the problem, or of the lecture if not set, extended for the student
+	Extended          bool        `json:"extended"`            // the deadline is extended for the student
+	LatePolicy        *LatePolicy `json:"late_policy"`         // late policy of the problem, or of the lecture if not set
+	ProblemDeadline   *int64      `json:"problem_deadline"`    // deadline set on the problem itself, null if not set
+	ProblemLatePolicy *LatePolicy `json:"problem_late_policy"` // late policy set on the problem itself, null if not set
+}
+
+func makeProblemEntry(lecture *model.Lecture, problem *model.Problem, extensions []model.DeadlineExtension) ProblemEntry {
+	deadline := problem.EffectiveDeadline(lecture)
+	extended := model.ExtendDeadline(deadline, problem.LectureID, problem.ProblemID, extensions)
+	entry := ProblemEntry{
+		LectureID:         problem.LectureID,
+		ProblemID:         problem.ProblemID,
+		Title:             problem.Title,
+		RegisteredAt:      problem.RegisteredAt.Unix(),
+		Status:            string(problem.Status),
+		Version:           problem.Version,
+		Deadline:          extended.Unix(),
+		Extended:          extended.After(deadline),
+		LatePolicy:        MakeLatePolicy(problem.EffectiveLatePolicy(lecture)),
+		ProblemLatePolicy: MakeLatePolicy(problem.LatePolicy),
+	}
+	if problem.Deadline != nil {
+		deadline := problem.Deadline.Unix()
+		entry.ProblemDeadline = &deadline
+	}
+	return entry
 }
 
-func FetchLectureEntry(ctx context.Context, problemStore database.ProblemStore, filter bool) ([]LectureEntry, error) {
+// fetchDeadlineExtensions fetches the deadline extensions of the student, none if userCode is -1.
+func fetchDeadlineExtensions(ctx context.Context, problemStore database.ProblemStore, userCode int64) ([]model.DeadlineExtension, error) {
+	if userCode == -1 {
+		return nil, nil
+	}
+	return problemStore.GetDeadlineExtensionsOfUser(ctx, userCode)
+}
+
+// FetchLectureEntry fetches all lectures and their problems.
+// Deadlines of the problems are extended for the student of userCode, or not if userCode is -1.
+func FetchLectureEntry(ctx context.Context, problemStore database.ProblemStore, filter bool, userCode int64) ([]LectureEntry, error) {
 	lectures, err := problemStore.GetAllLectureAndProblems(ctx)
 	if err != nil {
 		return nil, err
 	}
+	extensions, err := fetchDeadlineExtensions(ctx, problemStore, userCode)
+	if err != nil {
+		return nil, err
+	}
 
 	var lectureEntries []LectureEntry
 	for _, lecture := range lectures {
@@ -41,53 +121,57 @@ func FetchLectureEntry(ctx context.Context, problemStore database.ProblemStore,
 		}
 
 		lectureEntry := LectureEntry{
-			LectureID: lecture.ID,
-			Title:     lecture.Title,
-			StartDate: lecture.StartDate.Unix(),
-			Deadline:  lecture.Deadline.Unix(),
-			Problems:  []ProblemEntry{},
+			LectureID:  lecture.ID,
+			Title:      lecture.Title,
+			StartDate:  lecture.StartDate.Unix(),
+			Deadline:   lecture.Deadline.Unix(),
+			LatePolicy: MakeLatePolicy(lecture.LatePolicy),
+			Problems:   []ProblemEntry{},
 		}
 
 		for _, problem := range lecture.Problems {
-			problemEntry := ProblemEntry{
-				LectureID:    problem.LectureID,
-				ProblemID:    problem.ProblemID,
-				Title:        problem.Title,
-				RegisteredAt: problem.RegisteredAt.Unix(),
+			// If filter is true, filter out problems whose reference solution is not verified.
+			if filter && !problem.IsVisible() {
+				continue
 			}
-			lectureEntry.Problems = append(lectureEntry.Problems, problemEntry)
+			lectureEntry.Problems = append(lectureEntry.Problems, makeProblemEntry(&lecture, problem, extensions))
 		}
 		lectureEntries = append(lectureEntries, lectureEntry)
 	}
 	return lectureEntries, nil
 }
 
-func FetchLectureByID(ctx context.Context, problemStore database.ProblemStore, lectureID int64, filter bool) (*LectureEntry, error) {
+// FetchLectureByID fetches the lecture and its problems.
+// Deadlines of the problems are extended for the student of userCode, or not if userCode is -1.
+func FetchLectureByID(ctx context.Context, problemStore database.ProblemStore, lectureID int64, filter bool, userCode int64) (*LectureEntry, error) {
 	lecture, err := problemStore.GetLectureAndAllProblems(ctx, lectureID)
 	if err != nil {
 		return nil, err
 	}
+	extensions, err := fetchDeadlineExtensions(ctx, problemStore, userCode)
+	if err != nil {
+		return nil, err
+	}
 
 	// If filter is true, and the lecture is unpublished, return nil
 	if filter && lecture.StartDate.After(time.Now()) {
 		return nil, errors.New("lecture is not published")
 	}
 	lectureEntry := LectureEntry{
-		LectureID: lecture.ID,
-		Title:     lecture.Title,
-		StartDate: lecture.StartDate.Unix(),
-		Deadline:  lecture.Deadline.Unix(),
-		Problems:  []ProblemEntry{},
+		LectureID:  lecture.ID,
+		Title:      lecture.Title,
+		StartDate:  lecture.StartDate.Unix(),
+		Deadline:   lecture.Deadline.Unix(),
+		LatePolicy: MakeLatePolicy(lecture.LatePolicy),
+		Problems:   []ProblemEntry{},
 	}
 
 	for _, problem := range lecture.Problems {
-		problemEntry := ProblemEntry{
-			LectureID:    problem.LectureID,
-			ProblemID:    problem.ProblemID,
-			Title:        problem.Title,
-			RegisteredAt: problem.RegisteredAt.Unix(),
+		// If filter is true, filter out problems whose reference solution is not verified.
+		if filter && !problem.IsVisible() {
+			continue
 		}
-		lectureEntry.Problems = append(lectureEntry.Problems, problemEntry)
+		lectureEntry.Problems = append(lectureEntry.Problems, makeProblemEntry(&lecture, problem, extensions))
 	}
 
 	// sort problems by ProblemID to make output deterministic
@@ -105,6 +189,8 @@ type ProblemDetail struct {
 	Title         string     `json:"title"`
 	Description   string     `json:"description"`
 	TimeMS        int64      `json:"time_ms"`
+	CPUTimeMS     int64      `json:"cpu_time_ms"`
+	CPUCores      int64      `json:"cpu_cores"`
 	MemoryMB      int64      `json:"memory_mb"`
 	TestFiles     []FileData `json:"test_files"`
 	RequiredFiles []string   `json:"required_files"`
@@ -128,6 +214,11 @@ func FetchProblemDetail(ctx context.Context, problemStore database.ProblemStore,
 		return nil, err
 	}
 
+	// If filter is true, and the reference solution of the problem is not verified, return nil
+	if filter && !problem.IsVisible() {
+		return nil, errors.New("problem is not verified")
+	}
+
 	// read description md file
 	fileLocation, err := fileStore.GetFileLocation(ctx, problem.ResourceLocationID)
 	if err != nil {
@@ -142,12 +233,30 @@ func FetchProblemDetail(ctx context.Context, problemStore database.ProblemStore,
 		return nil, err
 	}
 
+	// Rewrite links to images and attachments in the problem package, since the package is not served as it is
+	refs, err := fileStore.GetFileReferencesOfProblem(ctx, lectureID, problemID)
+	if err != nil {
+		return nil, err
+	}
+	refIDs := map[string]int64{}
+	for _, ref := range refs {
+		if ref.FileLocation != nil {
+			refIDs[ref.FileLocation.Path] = ref.ID
+		}
+	}
+	description := RewriteRelativeLinks(string(mdContent), problem.Detail.DescriptionPath, func(path string) (string, bool) {
+		id, ok := refIDs[filepath.Join(fileLocation.Path, filepath.FromSlash(path))]
+		return fmt.Sprintf("/problem/fetch/fileref/%d", id), ok
+	})
+
 	detail := ProblemDetail{
 		LectureID:     problem.LectureID,
 		ProblemID:     problem.ProblemID,
 		Title:         problem.Title,
-		Description:   string(mdContent),
+		Description:   description,
 		TimeMS:        problem.Detail.TimeMS,
+		CPUTimeMS:     problem.Detail.CPUTimeMS,
+		CPUCores:      max(problem.Detail.CPUCores, 1),
 		MemoryMB:      problem.Detail.MemoryMB,
 		RequiredFiles: problem.Detail.RequiredFiles,
 	}
@@ -166,18 +275,17 @@ func FetchProblemDetail(ctx context.Context, problemStore database.ProblemStore,
 	return &detail, nil
 }
 
-func FetchTestFielsInProblem(ctx context.Context, problemStore database.ProblemStore, lectureID int64, problemID int64) ([]FileData, error) {
-	// fetch problem info
-	problem, err := problemStore.GetProblemByID(ctx, lectureID, problemID)
+// FetchTestFielsInProblem fetches the test files of the given version of the problem.
+func FetchTestFielsInProblem(ctx context.Context, problemStore database.ProblemStore, lectureID int64, problemID int64, version int64) ([]FileData, error) {
+	// fetch problem info and its resource directory
+	problem, err := problemStore.GetProblemVersion(ctx, lectureID, problemID, version)
 	if err != nil {
 		return nil, err
 	}
-
-	// fetch resource resource_dir
-	resource_dir, err := problemStore.FetchResourcePath(ctx, lectureID, problemID)
-	if err != nil {
-		return nil, err
+	if problem.ResourceLocation == nil {
+		return nil, fmt.Errorf("resource location of problem %d version %d not found", problemID, version)
 	}
+	resource_dir := problem.ResourceLocation.Path
 
 	var testFiles []FileData
 	for _, testFile := range problem.Detail.TestFiles {
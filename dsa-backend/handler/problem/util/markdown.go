@@ -0,0 +1,76 @@
+package util
+
+import (
+	"net/url"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Matches inline links and images in markdown, e.g., ![figure](images/fig1.png "title"),
+// with the link destination in the second group.
+var markdownLinkPattern = regexp.MustCompile(`(!?\[[^\]\n]*\]\(\s*)(<[^>\n]*>|[^)\s]+)`)
+
+// Matches src and href attributes of raw HTML in markdown, e.g., <img src="images/fig1.png" width="300">,
+// with the link destination in the second group.
+var htmlLinkPattern = regexp.MustCompile(`(\b(?:src|href)\s*=\s*["'])([^"'\n]*)`)
+
+// RewriteRelativeLinks replaces the destinations of links in the markdown which refer to files in the problem package.
+// replace receives the path of the referenced file relative to the package root, and returns the new destination,
+// or false to leave the link as it is. descriptionPath is the path of the markdown relative to the package root.
+func RewriteRelativeLinks(markdown string, descriptionPath string, replace func(path string) (string, bool)) string {
+	for _, pattern := range []*regexp.Regexp{markdownLinkPattern, htmlLinkPattern} {
+		var builder strings.Builder
+		last := 0
+		for _, match := range pattern.FindAllStringSubmatchIndex(markdown, -1) {
+			start, end := match[4], match[5]
+			rel, ok := resolveRelativeLink(markdown[start:end], descriptionPath)
+			if !ok {
+				continue
+			}
+			dest, ok := replace(rel)
+			if !ok {
+				continue
+			}
+			builder.WriteString(markdown[last:start])
+			builder.WriteString(dest)
+			last = end
+		}
+		builder.WriteString(markdown[last:])
+		markdown = builder.String()
+	}
+	return markdown
+}
+
+// RelativeLinks returns the paths of the files in the problem package referenced from the markdown, relative to the package root.
+func RelativeLinks(markdown string, descriptionPath string) []string {
+	paths := []string{}
+	RewriteRelativeLinks(markdown, descriptionPath, func(path string) (string, bool) {
+		if !slices.Contains(paths, path) {
+			paths = append(paths, path)
+		}
+		return "", false
+	})
+	return paths
+}
+
+// Resolves a link destination relative to the markdown into a path relative to the package root.
+// Absolute URLs, anchors and paths escaping the package are not resolved.
+func resolveRelativeLink(dest string, descriptionPath string) (string, bool) {
+	dest = strings.TrimSuffix(strings.TrimPrefix(dest, "<"), ">")
+	if dest == "" || strings.HasPrefix(dest, "#") || strings.HasPrefix(dest, "/") {
+		return "", false
+	}
+
+	u, err := url.Parse(dest)
+	if err != nil || u.Scheme != "" || u.Host != "" || u.Path == "" {
+		return "", false
+	}
+
+	rel := path.Join(path.Dir(descriptionPath), u.Path)
+	if rel == ".." || strings.HasPrefix(rel, "../") || path.IsAbs(rel) {
+		return "", false
+	}
+	return rel, true
+}
@@ -0,0 +1,249 @@
+package problem
+
+import (
+	"context"
+	"dsa-backend/handler/problem/util"
+	"dsa-backend/handler/response"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/dsa-uts/dsa-project/database/model"
+	"github.com/dsa-uts/dsa-project/database/model/problemstatus"
+	"github.com/dsa-uts/dsa-project/database/model/queuestatus"
+	"github.com/dsa-uts/dsa-project/database/model/queuetype"
+	"github.com/dsa-uts/dsa-project/database/model/requeststatus"
+	"github.com/labstack/echo/v4"
+)
+
+// enqueueVerification registers a verification of the problem, and submits a job
+// running its reference solution through all build and judge tasks.
+// resourceDir is the directory of the resource files of the problem.
+// If generateOutputs is true, missing expected outputs of judge tasks are generated into resourceDir.
+func (h *Handler) enqueueVerification(ctx context.Context, problem *model.Problem, resourceDir string, generateOutputs bool) error {
+	requestTime := time.Now()
+
+	verification := model.ProblemVerification{
+		LectureID:      problem.LectureID,
+		ProblemID:      problem.ProblemID,
+		ProblemVersion: problem.Version,
+		TS:             requestTime,
+		ResultID:       requeststatus.WJ,
+		Log: model.RequestLog{
+			ResultID: requeststatus.WJ,
+		},
+	}
+	if err := h.requestStore.RegisterProblemVerification(ctx, &verification); err != nil {
+		return fmt.Errorf("failed to register verification: %w", err)
+	}
+
+	// ---------------------------------------------------------------------------
+	// resultDir: upload/verification/{lectureID}/{problemID}/{YYYY-MM-DD-HH-mm-ss}/result
+	// ---------------------------------------------------------------------------
+	resultDir := filepath.Join(VERIFICATION_DIR,
+		strconv.FormatInt(problem.LectureID, 10),
+		strconv.FormatInt(problem.ProblemID, 10),
+		requestTime.Format("2006-01-02-15-04-05"),
+		"result")
+
+	job := model.JobQueue{
+		RequestType: queuetype.Verification,
+		RequestID:   verification.ID,
+		Status:      queuestatus.Pending,
+		CreatedAt:   time.Now(),
+		Detail: model.MakeJobDetail(
+			problem.Detail,
+			resourceDir,
+			filepath.Join(resourceDir, problem.Detail.ReferenceSolutionPath), // the reference solution is submitted as user files
+			resultDir,
+			problem.Detail.BuildTasks,
+			problem.Detail.JudgeTasks,
+		),
+	}
+	job.Detail.GenerateOutputs = generateOutputs
+
+	if err := h.jobQueueStore.InsertJob(ctx, &job); err != nil {
+		if err := h.requestStore.UpdateProblemVerificationStatus(ctx, verification.ID, requeststatus.IE); err != nil {
+			return fmt.Errorf("failed to update verification status: %w", err)
+		}
+		return fmt.Errorf("failed to register job: %w", err)
+	}
+
+	return nil
+}
+
+type VerificationOutput struct {
+	LectureID       int64             `json:"lecture_id"`
+	ProblemID       int64             `json:"problem_id"`
+	Version         int64             `json:"version"`         // latest version of the problem, which may not be current until it is verified
+	Status          string            `json:"status"`          // "unverified", "verifying", "verified", "broken", "generating" or "review"
+	VerificationID  int64             `json:"verification_id"` // 0 if the problem has never been verified
+	TS              int64             `json:"ts"`
+	ResultID        int64             `json:"result_id"`
+	FailedBuildLogs []DetailedTaskLog `json:"failed_build_logs"`
+	FailedJudgeLogs []DetailedTaskLog `json:"failed_judge_logs"`
+	GeneratedFiles  []util.FileData   `json:"generated_files"` // expected outputs generated from the reference solution, to be reviewed
+}
+
+// GetVerificationResult godoc
+//
+//	@Summary		Get the verification result of a problem
+//	@Description	Get the status of the reference solution verification of a problem, the tasks failed by the reference solution, and the expected outputs generated from it.
+//	@Tags			Update
+//	@Produce		json
+//	@Param			lectureid	path		int	true	"Lecture ID"
+//	@Param			problemid	path		int	true	"Problem ID"
+//	@Success		200			{object}	VerificationOutput
+//	@Failure		400			{object}	response.Error	"Invalid request"
+//	@Failure		404			{object}	response.Error	"Problem not found"
+//	@Failure		500			{object}	response.Error	"Internal server error"
+//	@Security		OAuth2Password[grading]
+//	@Router			/problem/crud/verification/{lectureid}/{problemid} [get]
+func (h *Handler) GetVerificationResult(c echo.Context) error {
+	var req LectureIDProblemID
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, response.NewError("invalid request: "+err.Error()))
+	}
+
+	ctx := context.Background()
+
+	// Students keep seeing the current version while the latest one is verified
+	problem, err := h.problemStore.GetLatestProblemVersion(ctx, req.LectureID, req.ProblemID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, response.NewError("Problem not found"))
+	}
+
+	output := VerificationOutput{
+		LectureID:       problem.LectureID,
+		ProblemID:       problem.ProblemID,
+		Version:         problem.Version,
+		Status:          string(problem.Status),
+		FailedBuildLogs: []DetailedTaskLog{},
+		FailedJudgeLogs: []DetailedTaskLog{},
+		GeneratedFiles:  []util.FileData{},
+	}
+
+	if problem.Detail.ReferenceSolutionPath == "" {
+		// Nothing to verify
+		return c.JSON(http.StatusOK, output)
+	}
+
+	verification, err := h.requestStore.GetLatestProblemVerification(ctx, req.LectureID, req.ProblemID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("Failed to get verification result"))
+	}
+
+	// The logs refer to the tasks and files of the version verified
+	verifiedVersion, err := h.problemStore.GetProblemVersion(ctx, req.LectureID, req.ProblemID, verification.ProblemVersion)
+	if err != nil || verifiedVersion.ResourceLocation == nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("Failed to get problem info"))
+	}
+	resourceDir := verifiedVersion.ResourceLocation.Path
+	detail := verifiedVersion.Detail
+
+	output.VerificationID = verification.ID
+	output.TS = verification.TS.Unix()
+	output.ResultID = int64(verification.ResultID)
+
+	buildTaskDict := make(map[int64]model.TestCase)
+	for _, task := range detail.BuildTasks {
+		buildTaskDict[task.ID] = task
+	}
+
+	judgeTaskDict := make(map[int64]model.TestCase)
+	for _, task := range detail.JudgeTasks {
+		judgeTaskDict[task.ID] = task
+	}
+
+	for _, buildResult := range verification.Log.BuildResults {
+		if buildResult.ResultID == requeststatus.AC {
+			continue
+		}
+
+		corresponding_task, exists := buildTaskDict[buildResult.TestCaseID]
+		if !exists {
+			return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("Inconsistent data: build task not found"))
+		}
+
+		detailedTaskLog, err := makeDetailedTaskLog(buildResult, corresponding_task, resourceDir, displayLimitBytes(detail))
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("Failed to create detailed task log: "+err.Error()))
+		}
+
+		output.FailedBuildLogs = append(output.FailedBuildLogs, detailedTaskLog)
+	}
+
+	for _, judgeResult := range verification.Log.JudgeResults {
+		if judgeResult.ResultID == requeststatus.AC {
+			continue
+		}
+
+		corresponding_task, exists := judgeTaskDict[judgeResult.TestCaseID]
+		if !exists {
+			return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("Inconsistent data: judge task not found"))
+		}
+
+		detailedTaskLog, err := makeDetailedTaskLog(judgeResult, corresponding_task, resourceDir, displayLimitBytes(detail))
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("Failed to create detailed task log: "+err.Error()))
+		}
+
+		output.FailedJudgeLogs = append(output.FailedJudgeLogs, detailedTaskLog)
+	}
+
+	for _, judgeResult := range verification.Log.JudgeResults {
+		for _, generated := range judgeResult.Generated {
+			fileData, _, err := util.FetchFileWithLimit(filepath.Join(resourceDir, generated), displayLimitBytes(detail))
+			if err != nil {
+				return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("Failed to read generated file: "+generated))
+			}
+			fileData.Name = generated
+
+			output.GeneratedFiles = append(output.GeneratedFiles, *fileData)
+		}
+	}
+
+	return c.JSON(http.StatusOK, output)
+}
+
+// PublishProblem godoc
+//
+//	@Summary		Publish a problem with generated outputs
+//	@Description	Make the latest version of a problem current and visible to students, after its expected outputs generated from the reference solution are reviewed.
+//	@Tags			Update
+//	@Produce		json
+//	@Param			lectureid	path		int	true	"Lecture ID"
+//	@Param			problemid	path		int	true	"Problem ID"
+//	@Success		200			{object}	response.Success
+//	@Failure		400			{object}	response.Error	"Invalid request"
+//	@Failure		404			{object}	response.Error	"Problem not found"
+//	@Failure		409			{object}	response.Error	"Problem is not waiting for review"
+//	@Failure		500			{object}	response.Error	"Internal server error"
+//	@Security		OAuth2Password[grading]
+//	@Router			/problem/crud/publish/{lectureid}/{problemid} [post]
+func (h *Handler) PublishProblem(c echo.Context) error {
+	var req LectureIDProblemID
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, response.NewError("invalid request: "+err.Error()))
+	}
+
+	ctx := context.Background()
+
+	// The version waiting for review is the latest one, and becomes current once it is published
+	problem, err := h.problemStore.GetLatestProblemVersion(ctx, req.LectureID, req.ProblemID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, response.NewError("Problem not found"))
+	}
+
+	if problem.Status != problemstatus.Review {
+		return echo.NewHTTPError(http.StatusConflict, response.NewError("Problem is not waiting for review: "+string(problem.Status)))
+	}
+
+	if err := h.problemStore.UpdateProblemStatus(ctx, req.LectureID, req.ProblemID, problem.Version, problemstatus.Verified); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("Failed to update problem status"))
+	}
+
+	return c.JSON(http.StatusOK, response.NewSuccess("Problem published successfully"))
+}
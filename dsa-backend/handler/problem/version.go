@@ -0,0 +1,321 @@
+package problem
+
+import (
+	"bytes"
+	"context"
+	"dsa-backend/handler/problem/util"
+	"dsa-backend/handler/response"
+	"encoding/json"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/dsa-uts/dsa-project/database/model"
+	"github.com/dsa-uts/dsa-project/database/model/problemstatus"
+	"github.com/labstack/echo/v4"
+)
+
+type ProblemVersionOutput struct {
+	Version      int64  `json:"version"`
+	RegisteredAt int64  `json:"registered_at"`
+	Title        string `json:"title"`
+	Status       string `json:"status"`  // "unverified", "verifying", "verified", "broken", "generating" or "review"
+	Current      bool   `json:"current"` // whether the version is used for new submissions
+}
+
+// ListProblemVersions godoc
+//
+//	@Summary		List versions of a problem
+//	@Description	List all uploaded versions of a problem, the latest first.
+//	@Tags			Update
+//	@Produce		json
+//	@Param			lectureid	path		int	true	"Lecture ID"
+//	@Param			problemid	path		int	true	"Problem ID"
+//	@Success		200			{array}		ProblemVersionOutput
+//	@Failure		400			{object}	response.Error	"Invalid request"
+//	@Failure		404			{object}	response.Error	"Problem not found"
+//	@Failure		500			{object}	response.Error	"Internal server error"
+//	@Security		OAuth2Password[grading]
+//	@Router			/problem/crud/versions/{lectureid}/{problemid} [get]
+func (h *Handler) ListProblemVersions(c echo.Context) error {
+	var req LectureIDProblemID
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, response.NewError("invalid request: "+err.Error()))
+	}
+
+	ctx := context.Background()
+
+	problem, err := h.problemStore.GetProblemByID(ctx, req.LectureID, req.ProblemID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, response.NewError("Problem not found"))
+	}
+
+	versions, err := h.problemStore.GetProblemVersions(ctx, req.LectureID, req.ProblemID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("Failed to get problem versions"))
+	}
+
+	output := make([]ProblemVersionOutput, 0, len(versions))
+	for _, version := range versions {
+		output = append(output, ProblemVersionOutput{
+			Version:      version.Version,
+			RegisteredAt: version.RegisteredAt.Unix(),
+			Title:        version.Title,
+			Status:       string(version.Status),
+			Current:      version.Version == problem.Version,
+		})
+	}
+
+	return c.JSON(http.StatusOK, output)
+}
+
+type VersionDiffRequest struct {
+	LectureID int64 `param:"lectureid"`
+	ProblemID int64 `param:"problemid"`
+	From      int64 `query:"from"`
+	To        int64 `query:"to"`
+}
+
+type FileDiff struct {
+	Path   string         `json:"path"`   // relative to the resource directory
+	Status string         `json:"status"` // "added", "removed" or "modified"
+	Old    *util.FileData `json:"old"`    // nil if added
+	New    *util.FileData `json:"new"`    // nil if removed
+}
+
+type VersionDiffOutput struct {
+	From          int64      `json:"from"`
+	To            int64      `json:"to"`
+	DetailChanged bool       `json:"detail_changed"` // whether the settings in init.json differ
+	Files         []FileDiff `json:"files"`
+}
+
+// DiffProblemVersions godoc
+//
+//	@Summary		Compare two versions of a problem
+//	@Description	List resource files added, removed or modified between two versions of a problem, with their contents.
+//	@Tags			Update
+//	@Produce		json
+//	@Param			lectureid	path		int	true	"Lecture ID"
+//	@Param			problemid	path		int	true	"Problem ID"
+//	@Param			from		query		int	true	"Older version"
+//	@Param			to			query		int	true	"Newer version"
+//	@Success		200			{object}	VersionDiffOutput
+//	@Failure		400			{object}	response.Error	"Invalid request"
+//	@Failure		404			{object}	response.Error	"Version not found"
+//	@Failure		500			{object}	response.Error	"Internal server error"
+//	@Security		OAuth2Password[grading]
+//	@Router			/problem/crud/diff/{lectureid}/{problemid} [get]
+func (h *Handler) DiffProblemVersions(c echo.Context) error {
+	var req VersionDiffRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, response.NewError("invalid request: "+err.Error()))
+	}
+
+	ctx := context.Background()
+
+	from, err := h.problemStore.GetProblemVersion(ctx, req.LectureID, req.ProblemID, req.From)
+	if err != nil || from.ResourceLocation == nil {
+		return echo.NewHTTPError(http.StatusNotFound, response.NewError("Version not found"))
+	}
+	to, err := h.problemStore.GetProblemVersion(ctx, req.LectureID, req.ProblemID, req.To)
+	if err != nil || to.ResourceLocation == nil {
+		return echo.NewHTTPError(http.StatusNotFound, response.NewError("Version not found"))
+	}
+
+	fromFiles, err := listResourceFiles(from.ResourceLocation.Path)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("Failed to read resource files"))
+	}
+	toFiles, err := listResourceFiles(to.ResourceLocation.Path)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("Failed to read resource files"))
+	}
+
+	fromDetail, err := json.Marshal(from.Detail)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("Failed to compare problem settings"))
+	}
+	toDetail, err := json.Marshal(to.Detail)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("Failed to compare problem settings"))
+	}
+
+	output := VersionDiffOutput{
+		From:          from.Version,
+		To:            to.Version,
+		DetailChanged: !bytes.Equal(fromDetail, toDetail) || from.Title != to.Title,
+		Files:         []FileDiff{},
+	}
+
+	paths := append(slices.Clone(fromFiles), toFiles...)
+	slices.Sort(paths)
+	paths = slices.Compact(paths)
+
+	displayLimit := displayLimitBytes(to.Detail)
+	for _, path := range paths {
+		oldPath := filepath.Join(from.ResourceLocation.Path, path)
+		newPath := filepath.Join(to.ResourceLocation.Path, path)
+
+		diff := FileDiff{Path: path}
+		switch {
+		case !slices.Contains(fromFiles, path):
+			diff.Status = "added"
+		case !slices.Contains(toFiles, path):
+			diff.Status = "removed"
+		default:
+			same, err := sameFileContents(oldPath, newPath)
+			if err != nil {
+				return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("Failed to compare resource files"))
+			}
+			if same {
+				continue
+			}
+			diff.Status = "modified"
+		}
+
+		if diff.Status != "added" {
+			fileData, _, err := util.FetchFileWithLimit(oldPath, displayLimit)
+			if err != nil {
+				return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("Failed to read resource file: "+path))
+			}
+			fileData.Name = path
+			diff.Old = fileData
+		}
+		if diff.Status != "removed" {
+			fileData, _, err := util.FetchFileWithLimit(newPath, displayLimit)
+			if err != nil {
+				return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("Failed to read resource file: "+path))
+			}
+			fileData.Name = path
+			diff.New = fileData
+		}
+
+		output.Files = append(output.Files, diff)
+	}
+
+	return c.JSON(http.StatusOK, output)
+}
+
+// Returns the paths of all regular files under dir, relative to dir.
+func listResourceFiles(dir string) ([]string, error) {
+	files := []string{}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		files = append(files, rel)
+		return nil
+	})
+	return files, err
+}
+
+func sameFileContents(a, b string) (bool, error) {
+	dataA, err := os.ReadFile(a)
+	if err != nil {
+		return false, err
+	}
+	dataB, err := os.ReadFile(b)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(dataA, dataB), nil
+}
+
+type RollbackRequest struct {
+	LectureID int64 `param:"lectureid"`
+	ProblemID int64 `param:"problemid"`
+	Version   int64 `param:"version"`
+}
+
+// RollbackProblem godoc
+//
+//	@Summary		Roll back a problem to a previous version
+//	@Description	Register a copy of a previous version of a problem as its new version. The reference solution is verified again, if any.
+//	@Tags			Update
+//	@Produce		json
+//	@Param			lectureid	path		int	true	"Lecture ID"
+//	@Param			problemid	path		int	true	"Problem ID"
+//	@Param			version		path		int	true	"Version to roll back to"
+//	@Success		200			{object}	response.Success
+//	@Failure		400			{object}	response.Error	"Invalid request"
+//	@Failure		404			{object}	response.Error	"Version not found"
+//	@Failure		409			{object}	response.Error	"Version is already current"
+//	@Failure		500			{object}	response.Error	"Internal server error"
+//	@Security		OAuth2Password[grading]
+//	@Router			/problem/crud/rollback/{lectureid}/{problemid}/{version} [post]
+func (h *Handler) RollbackProblem(c echo.Context) error {
+	var req RollbackRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, response.NewError("invalid request: "+err.Error()))
+	}
+
+	ctx := context.Background()
+
+	current, err := h.problemStore.GetProblemByID(ctx, req.LectureID, req.ProblemID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, response.NewError("Problem not found"))
+	}
+	if current.Version == req.Version {
+		return echo.NewHTTPError(http.StatusConflict, response.NewError("Version is already current"))
+	}
+
+	target, err := h.problemStore.GetProblemVersion(ctx, req.LectureID, req.ProblemID, req.Version)
+	if err != nil || target.ResourceLocation == nil {
+		return echo.NewHTTPError(http.StatusNotFound, response.NewError("Version not found"))
+	}
+
+	// Expected outputs generated for the target version are kept in its resource directory,
+	// so they are verified like the other expected outputs.
+	// Visible problems keep their current version until the target version is verified.
+	status := problemstatus.Unverified
+	if target.Detail.ReferenceSolutionPath != "" {
+		status = problemstatus.Verifying
+	}
+
+	problem := &model.Problem{
+		LectureID:          target.LectureID,
+		ProblemID:          target.ProblemID,
+		RegisteredAt:       time.Now(),
+		Title:              target.Title,
+		ResourceLocationID: target.ResourceLocationID,
+		Detail:             target.Detail,
+		Status:             status,
+	}
+
+	if err := h.problemStore.AddProblemVersion(ctx, problem); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("Failed to register problem version: "+err.Error()))
+	}
+
+	if err := h.resultCacheStore.DeleteResultCacheOfProblem(ctx, problem.LectureID, problem.ProblemID); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("Failed to invalidate cached results: "+err.Error()))
+	}
+
+	// Files linked from the description were registered when the version was uploaded,
+	// unless it was uploaded before they were served
+	if err := h.registerFileReferences(ctx, problem.LectureID, problem.ProblemID, target.ResourceLocation.Path, target.Detail.DescriptionPath); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("Failed to register files referenced from the description: "+err.Error()))
+	}
+
+	if status == problemstatus.Verifying {
+		if err := h.enqueueVerification(ctx, problem, target.ResourceLocation.Path, false); err != nil {
+			if err := h.problemStore.UpdateProblemStatus(ctx, problem.LectureID, problem.ProblemID, problem.Version, problemstatus.Broken); err != nil {
+				return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("Failed to update problem status: "+err.Error()))
+			}
+			return echo.NewHTTPError(http.StatusInternalServerError, response.NewError("Failed to register verification job: "+err.Error()))
+		}
+		return c.JSON(http.StatusOK, response.NewSuccess("Problem rolled back successfully, verifying the reference solution"))
+	}
+
+	return c.JSON(http.StatusOK, response.NewSuccess("Problem rolled back successfully"))
+}
@@ -0,0 +1,141 @@
+package problem
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// yamlToJSON converts init.yaml into the equivalent init.json, so that both are checked and decoded in the same way.
+// Keys must be strings, and values are converted to the JSON types of the same meaning.
+// Aliases (*name) are rejected.
+func yamlToJSON(data []byte) ([]byte, error) {
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+	if document.Kind == 0 {
+		return nil, fmt.Errorf("document is empty")
+	}
+
+	value, err := yamlNodeToValue(&document)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(value)
+}
+
+func yamlNodeToValue(node *yaml.Node) (any, error) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			return nil, nil
+		}
+		return yamlNodeToValue(node.Content[0])
+	case yaml.AliasNode:
+		// Aliases are not expanded, since a self-referencing or deeply nested one
+		// would expand without bound
+		return nil, fmt.Errorf("line %d: aliases are not supported: *%s", node.Line, node.Value)
+	case yaml.MappingNode:
+		object := map[string]any{}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if key.Kind != yaml.ScalarNode || key.ShortTag() == "!!merge" {
+				return nil, fmt.Errorf("line %d: keys must be strings", key.Line)
+			}
+			if _, exists := object[key.Value]; exists {
+				return nil, fmt.Errorf("line %d: duplicate key %q", key.Line, key.Value)
+			}
+			v, err := yamlNodeToValue(value)
+			if err != nil {
+				return nil, err
+			}
+			object[key.Value] = v
+		}
+		return object, nil
+	case yaml.SequenceNode:
+		array := []any{}
+		for _, item := range node.Content {
+			v, err := yamlNodeToValue(item)
+			if err != nil {
+				return nil, err
+			}
+			array = append(array, v)
+		}
+		return array, nil
+	case yaml.ScalarNode:
+		switch node.ShortTag() {
+		case "!!null":
+			return nil, nil
+		case "!!bool":
+			var b bool
+			if err := node.Decode(&b); err != nil {
+				return nil, fmt.Errorf("line %d: %w", node.Line, err)
+			}
+			return b, nil
+		case "!!int":
+			var n int64
+			if err := node.Decode(&n); err != nil {
+				return nil, fmt.Errorf("line %d: %w", node.Line, err)
+			}
+			return json.Number(strconv.FormatInt(n, 10)), nil
+		case "!!float":
+			var f float64
+			if err := node.Decode(&f); err != nil {
+				return nil, fmt.Errorf("line %d: %w", node.Line, err)
+			}
+			if math.IsNaN(f) || math.IsInf(f, 0) {
+				return nil, fmt.Errorf("line %d: %s is not a valid number", node.Line, node.Value)
+			}
+			return json.Number(strconv.FormatFloat(f, 'g', -1, 64)), nil
+		default:
+			// Timestamps and other tags are kept as written
+			return node.Value, nil
+		}
+	}
+	return nil, fmt.Errorf("line %d: unsupported YAML node", node.Line)
+}
+
+// marshalConfigYAML encodes the config as init.yaml, with the keys in the same order as init.json.
+// Multi-line strings such as descriptions and commands are written as literal blocks.
+func marshalConfigYAML(config AssignmentConfig) ([]byte, error) {
+	data, err := json.Marshal(config)
+	if err != nil {
+		return nil, err
+	}
+
+	// JSON is valid YAML, so decoding it into a node keeps the order of the keys
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+	setBlockStyle(&document)
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&document); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Replaces the flow style of JSON with the block style of YAML.
+// Strings are quoted by the encoder only where needed, e.g., "true" or "123".
+func setBlockStyle(node *yaml.Node) {
+	node.Style = 0
+	if node.Kind == yaml.ScalarNode && node.ShortTag() == "!!str" && strings.Contains(node.Value, "\n") {
+		node.Style = yaml.LiteralStyle
+	}
+	for _, child := range node.Content {
+		setBlockStyle(child)
+	}
+}
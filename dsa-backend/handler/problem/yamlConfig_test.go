@@ -0,0 +1,68 @@
+package problem
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestYAMLToJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr string
+	}{
+		{
+			name:  "scalars",
+			input: "a: 1\nb: 1.5\nc: true\nd: null\ne: text\n",
+			want:  `{"a":1,"b":1.5,"c":true,"d":null,"e":"text"}`,
+		},
+		{
+			name:  "anchor without alias",
+			input: "a: &x [1, 2]\n",
+			want:  `{"a":[1,2]}`,
+		},
+		{
+			name:    "duplicate key",
+			input:   "a: 1\na: 2\n",
+			wantErr: "duplicate key",
+		},
+		{
+			name:    "self-referencing alias",
+			input:   "a: &x [1, *x]\n",
+			wantErr: "aliases are not supported",
+		},
+		{
+			name: "nested aliases",
+			input: `a: &a ["x", "x", "x", "x", "x", "x", "x", "x", "x"]
+b: &b [*a, *a, *a, *a, *a, *a, *a, *a, *a]
+c: &c [*b, *b, *b, *b, *b, *b, *b, *b, *b]
+d: &d [*c, *c, *c, *c, *c, *c, *c, *c, *c]
+e: &e [*d, *d, *d, *d, *d, *d, *d, *d, *d]
+f: &f [*e, *e, *e, *e, *e, *e, *e, *e, *e]
+g: &g [*f, *f, *f, *f, *f, *f, *f, *f, *f]
+h: &h [*g, *g, *g, *g, *g, *g, *g, *g, *g]
+i: &i [*h, *h, *h, *h, *h, *h, *h, *h, *h]
+`,
+			wantErr: "aliases are not supported",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := yamlToJSON([]byte(tt.input))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("yamlToJSON() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("yamlToJSON() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("yamlToJSON() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
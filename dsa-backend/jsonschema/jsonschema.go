@@ -0,0 +1,434 @@
+// Package jsonschema validates JSON documents against a JSON Schema (draft-07).
+// Only the keywords used by the problem config schema are supported:
+// type, enum, properties, required, additionalProperties, propertyNames, items,
+// minItems, maxItems, minimum, maximum, pattern and local $ref to definitions.
+// Annotations such as title, description and default are ignored.
+// Schemas using other keywords are rejected by Parse, so that they are never silently ignored.
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type Schema struct {
+	root     map[string]any
+	patterns map[string]*regexp.Regexp // compiled at Parse
+}
+
+// Error is a violation of the schema found in a document.
+type Error struct {
+	Path    string `json:"path"` // location in the document, e.g., "judge[2].stdout", or "" for the document itself
+	Message string `json:"message"`
+}
+
+func (e Error) Error() string {
+	if e.Path == "" {
+		return e.Message
+	}
+	return e.Path + ": " + e.Message
+}
+
+// Keywords supported by the validator.
+var keywords = []string{
+	"$ref", "type", "enum", "properties", "required", "additionalProperties", "propertyNames",
+	"items", "minItems", "maxItems", "minimum", "maximum", "pattern", "definitions",
+}
+
+// Keywords which do not affect validation.
+var annotations = []string{"$schema", "$id", "$comment", "title", "description", "default", "examples"}
+
+var types = []string{"null", "boolean", "object", "array", "number", "integer", "string"}
+
+// Parse parses a schema.
+// Keywords which are not supported, patterns which are not valid regular expressions and references
+// which cannot be resolved are reported here, rather than silently ignored when validating documents.
+func Parse(data []byte) (*Schema, error) {
+	var root map[string]any
+	if err := decode(data, &root); err != nil {
+		return nil, fmt.Errorf("failed to parse schema: %w", err)
+	}
+	s := &Schema{root: root, patterns: map[string]*regexp.Regexp{}}
+	if err := s.check(root, "#"); err != nil {
+		return nil, fmt.Errorf("failed to parse schema: %w", err)
+	}
+	return s, nil
+}
+
+// Checks the schema and its subschemas, whose location is given as a JSON pointer, and compiles their patterns.
+func (s *Schema) check(schema map[string]any, location string) error {
+	keys := make([]string, 0, len(schema))
+	for key := range schema {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if slices.Contains(annotations, key) {
+			continue
+		}
+		if !slices.Contains(keywords, key) {
+			return fmt.Errorf("unsupported keyword at %s/%s", location, key)
+		}
+		// Keywords next to $ref are ignored in draft-07
+		if _, ok := schema["$ref"]; ok && key != "$ref" {
+			return fmt.Errorf("keyword next to $ref at %s/%s", location, key)
+		}
+	}
+
+	if ref, ok := schema["$ref"]; ok {
+		ref, _ := ref.(string)
+		if _, err := s.resolve(ref); err != nil {
+			return fmt.Errorf("invalid reference at %s/$ref: %w", location, err)
+		}
+	}
+
+	if t, ok := schema["type"]; ok {
+		names := schemaTypes(t)
+		if len(names) == 0 {
+			return fmt.Errorf("invalid type at %s/type", location)
+		}
+		for _, name := range names {
+			if !slices.Contains(types, name) {
+				return fmt.Errorf("unknown type %q at %s/type", name, location)
+			}
+		}
+	}
+
+	if pattern, ok := schema["pattern"]; ok {
+		pattern, ok := pattern.(string)
+		if !ok {
+			return fmt.Errorf("invalid pattern at %s/pattern: must be a string", location)
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("invalid pattern at %s/pattern: %w", location, err)
+		}
+		s.patterns[pattern] = re
+	}
+
+	for _, key := range []string{"additionalProperties", "propertyNames", "items"} {
+		switch subschema := schema[key].(type) {
+		case nil:
+		case map[string]any:
+			if err := s.check(subschema, location+"/"+key); err != nil {
+				return err
+			}
+		case bool:
+			if key != "additionalProperties" {
+				return fmt.Errorf("unsupported boolean schema at %s/%s", location, key)
+			}
+		default:
+			// e.g., items as an array of schemas for tuples
+			return fmt.Errorf("unsupported value at %s/%s", location, key)
+		}
+	}
+	for _, key := range []string{"properties", "definitions"} {
+		value, ok := schema[key]
+		if !ok {
+			continue
+		}
+		subschemas, ok := value.(map[string]any)
+		if !ok {
+			return fmt.Errorf("invalid value at %s/%s: must be an object", location, key)
+		}
+		names := make([]string, 0, len(subschemas))
+		for name := range subschemas {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			subschema, ok := subschemas[name].(map[string]any)
+			if !ok {
+				return fmt.Errorf("invalid schema at %s/%s/%s: must be an object", location, key, name)
+			}
+			if err := s.check(subschema, location+"/"+key+"/"+name); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ValidateJSON validates a JSON document, and reports all violations found.
+// A document which is not valid JSON is reported as a single violation.
+func (s *Schema) ValidateJSON(data []byte) []Error {
+	var value any
+	if err := decode(data, &value); err != nil {
+		return []Error{{Message: "invalid JSON: " + err.Error()}}
+	}
+	return s.Validate(value)
+}
+
+// Validate validates a document decoded into maps, slices, strings, bools, nil and json.Number,
+// as decoded by encoding/json with UseNumber, and reports all violations found.
+func (s *Schema) Validate(value any) []Error {
+	errs := []Error{}
+	s.validate(s.root, value, "", &errs)
+	return errs
+}
+
+func decode(data []byte, v any) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(v); err != nil {
+		return err
+	}
+	if decoder.More() {
+		return fmt.Errorf("unexpected data after the top-level value")
+	}
+	return nil
+}
+
+func (s *Schema) validate(schema map[string]any, value any, path string, errs *[]Error) {
+	report := func(format string, args ...any) {
+		*errs = append(*errs, Error{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if ref, ok := schema["$ref"].(string); ok {
+		resolved, err := s.resolve(ref)
+		if err != nil {
+			report("%v", err)
+			return
+		}
+		schema = resolved
+	}
+
+	if types := schemaTypes(schema["type"]); len(types) > 0 {
+		actual := typeOf(value)
+		if !slices.Contains(types, actual) && !(actual == "integer" && slices.Contains(types, "number")) {
+			report("must be %s, but is %s", strings.Join(types, " or "), actual)
+			// The other keywords would only repeat the same mistake
+			return
+		}
+	}
+
+	if enum, ok := schema["enum"].([]any); ok && !slices.ContainsFunc(enum, func(e any) bool { return equal(e, value) }) {
+		options := make([]string, len(enum))
+		for i, e := range enum {
+			options[i] = format(e)
+		}
+		report("must be one of %s, but is %s", strings.Join(options, ", "), format(value))
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		s.validateObject(schema, v, path, errs)
+	case []any:
+		if n, ok := number(schema["minItems"]); ok && float64(len(v)) < n {
+			report("must have at least %s items, but has %d", format(schema["minItems"]), len(v))
+		}
+		if n, ok := number(schema["maxItems"]); ok && float64(len(v)) > n {
+			report("must have at most %s items, but has %d", format(schema["maxItems"]), len(v))
+		}
+		if items, ok := schema["items"].(map[string]any); ok {
+			for i, item := range v {
+				s.validate(items, item, fmt.Sprintf("%s[%d]", path, i), errs)
+			}
+		}
+	case json.Number:
+		n, _ := v.Float64()
+		if minimum, ok := number(schema["minimum"]); ok && n < minimum {
+			report("must be at least %s, but is %s", format(schema["minimum"]), v)
+		}
+		if maximum, ok := number(schema["maximum"]); ok && n > maximum {
+			report("must be at most %s, but is %s", format(schema["maximum"]), v)
+		}
+	case string:
+		if pattern, ok := schema["pattern"].(string); ok {
+			re, err := s.compile(pattern)
+			if err != nil {
+				report("%v", err)
+			} else if !re.MatchString(v) {
+				report("must match %s, but is %q", pattern, v)
+			}
+		}
+	}
+}
+
+func (s *Schema) validateObject(schema map[string]any, object map[string]any, path string, errs *[]Error) {
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	properties, _ := schema["properties"].(map[string]any)
+
+	if required, ok := schema["required"].([]any); ok {
+		for _, r := range required {
+			if key, ok := r.(string); ok {
+				if _, exists := object[key]; !exists {
+					*errs = append(*errs, Error{Path: join(path, key), Message: "is required"})
+				}
+			}
+		}
+	}
+
+	for _, key := range keys {
+		keyPath := join(path, key)
+
+		if names, ok := schema["propertyNames"].(map[string]any); ok {
+			s.validate(names, key, keyPath, errs)
+		}
+
+		if property, ok := properties[key].(map[string]any); ok {
+			s.validate(property, object[key], keyPath, errs)
+			continue
+		}
+		switch additional := schema["additionalProperties"].(type) {
+		case bool:
+			if !additional {
+				*errs = append(*errs, Error{Path: keyPath, Message: "is not a known key" + suggestion(key, properties)})
+			}
+		case map[string]any:
+			s.validate(additional, object[key], keyPath, errs)
+		}
+	}
+}
+
+// Returns the compiled pattern.
+// Patterns are compiled at Parse, except for those only reachable through references to unusual locations.
+func (s *Schema) compile(pattern string) (*regexp.Regexp, error) {
+	if re, ok := s.patterns[pattern]; ok {
+		return re, nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern in schema: %w", err)
+	}
+	return re, nil
+}
+
+// Resolves a reference to a definition in the schema, e.g., "#/definitions/testCase".
+func (s *Schema) resolve(ref string) (map[string]any, error) {
+	if !strings.HasPrefix(ref, "#/") {
+		return nil, fmt.Errorf("unsupported reference in schema: %s", ref)
+	}
+	var current any = s.root
+	for _, token := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+		object, ok := current.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("unresolvable reference in schema: %s", ref)
+		}
+		current = object[strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")]
+	}
+	resolved, ok := current.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("unresolvable reference in schema: %s", ref)
+	}
+	return resolved, nil
+}
+
+func schemaTypes(t any) []string {
+	switch t := t.(type) {
+	case string:
+		return []string{t}
+	case []any:
+		types := []string{}
+		for _, e := range t {
+			if s, ok := e.(string); ok {
+				types = append(types, s)
+			}
+		}
+		return types
+	}
+	return nil
+}
+
+// Returns the JSON Schema type of a decoded value.
+func typeOf(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	case json.Number:
+		// Numbers such as 1.0 are integers in JSON Schema, but cannot be decoded into integer fields
+		if _, err := v.Int64(); err == nil {
+			return "integer"
+		}
+		return "number"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+func number(value any) (float64, bool) {
+	n, ok := value.(json.Number)
+	if !ok {
+		return 0, false
+	}
+	f, err := n.Float64()
+	return f, err == nil
+}
+
+func equal(a, b any) bool {
+	if na, ok := number(a); ok {
+		nb, ok := number(b)
+		return ok && na == nb
+	}
+	return format(a) == format(b)
+}
+
+func format(value any) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
+
+func join(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// Suggests a known key close to a mistyped one, e.g., "stdout" for "stdot".
+func suggestion(key string, properties map[string]any) string {
+	best, bestDistance := "", 3 // only suggest keys with a few typos
+	for name := range properties {
+		if d := distance(strings.ToLower(key), name); d < bestDistance || (d == bestDistance && best != "" && name < best) {
+			best, bestDistance = name, d
+		}
+	}
+	if best == "" {
+		return ""
+	}
+	return ", did you mean " + strconv.Quote(best) + "?"
+}
+
+// Levenshtein distance between two strings
+func distance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
@@ -0,0 +1,261 @@
+package jsonschema
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testSchema = `{
+	"type": "object",
+	"required": ["title", "judge"],
+	"additionalProperties": false,
+	"properties": {
+		"title": {"type": "string", "pattern": "^[A-Za-z ]+$"},
+		"time_ms": {"type": "integer", "minimum": 1, "maximum": 10000},
+		"ratio": {"type": "number", "minimum": 0, "maximum": 1},
+		"policy": {"type": "string", "enum": ["fail", "warn"]},
+		"sizes": {"type": "array", "minItems": 2, "maxItems": 3, "items": {"type": "integer"}},
+		"env": {
+			"type": "object",
+			"propertyNames": {"pattern": "^[A-Z_]+$"},
+			"additionalProperties": {"type": "string"}
+		},
+		"judge": {"type": "array", "items": {"$ref": "#/definitions/testCase"}}
+	},
+	"definitions": {
+		"testCase": {
+			"type": "object",
+			"required": ["command"],
+			"additionalProperties": false,
+			"properties": {
+				"command": {"type": "string"},
+				"stdout": {"type": ["string", "null"]}
+			}
+		}
+	}
+}`
+
+func TestValidateJSON(t *testing.T) {
+	schema, err := Parse([]byte(testSchema))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		document string
+		want     []Error
+	}{
+		{
+			name:     "valid",
+			document: `{"title": "Sort", "time_ms": 1000, "ratio": 0.5, "policy": "warn", "sizes": [1, 2], "env": {"LANG": "C"}, "judge": [{"command": "./a.out", "stdout": null}]}`,
+			want:     []Error{},
+		},
+		{
+			name:     "invalid JSON",
+			document: `{"title": "Sort",}`,
+			want:     []Error{{Message: "invalid JSON: invalid character '}' looking for beginning of object key string"}},
+		},
+		{
+			name:     "required",
+			document: `{}`,
+			want:     []Error{{Path: "title", Message: "is required"}, {Path: "judge", Message: "is required"}},
+		},
+		{
+			name:     "unknown key with suggestion",
+			document: `{"title": "Sort", "judge": [], "tile": "x"}`,
+			want:     []Error{{Path: "tile", Message: `is not a known key, did you mean "title"?`}},
+		},
+		{
+			name:     "unknown key without suggestion",
+			document: `{"title": "Sort", "judge": [], "unrelated": 1}`,
+			want:     []Error{{Path: "unrelated", Message: "is not a known key"}},
+		},
+		{
+			name:     "additionalProperties schema",
+			document: `{"title": "Sort", "judge": [], "env": {"LANG": 1}}`,
+			want:     []Error{{Path: "env.LANG", Message: "must be string, but is integer"}},
+		},
+		{
+			name:     "propertyNames",
+			document: `{"title": "Sort", "judge": [], "env": {"lang": "C"}}`,
+			want:     []Error{{Path: "env.lang", Message: `must match ^[A-Z_]+$, but is "lang"`}},
+		},
+		{
+			name:     "pattern",
+			document: `{"title": "Sort 2", "judge": []}`,
+			want:     []Error{{Path: "title", Message: `must match ^[A-Za-z ]+$, but is "Sort 2"`}},
+		},
+		{
+			name:     "enum",
+			document: `{"title": "Sort", "judge": [], "policy": "ignore"}`,
+			want:     []Error{{Path: "policy", Message: `must be one of "fail", "warn", but is "ignore"`}},
+		},
+		{
+			name:     "integer is a number",
+			document: `{"title": "Sort", "judge": [], "ratio": 1}`,
+			want:     []Error{},
+		},
+		{
+			name:     "number is not an integer",
+			document: `{"title": "Sort", "judge": [], "time_ms": 1.5}`,
+			want:     []Error{{Path: "time_ms", Message: "must be integer, but is number"}},
+		},
+		{
+			name:     "integer with fraction is not an integer",
+			document: `{"title": "Sort", "judge": [], "time_ms": 1.0}`,
+			want:     []Error{{Path: "time_ms", Message: "must be integer, but is number"}},
+		},
+		{
+			name:     "minimum",
+			document: `{"title": "Sort", "judge": [], "time_ms": 0, "ratio": -0.5}`,
+			want: []Error{
+				{Path: "ratio", Message: "must be at least 0, but is -0.5"},
+				{Path: "time_ms", Message: "must be at least 1, but is 0"},
+			},
+		},
+		{
+			name:     "maximum",
+			document: `{"title": "Sort", "judge": [], "time_ms": 10001, "ratio": 1.5}`,
+			want: []Error{
+				{Path: "ratio", Message: "must be at most 1, but is 1.5"},
+				{Path: "time_ms", Message: "must be at most 10000, but is 10001"},
+			},
+		},
+		{
+			name:     "minItems and items",
+			document: `{"title": "Sort", "judge": [], "sizes": ["1"]}`,
+			want: []Error{
+				{Path: "sizes", Message: "must have at least 2 items, but has 1"},
+				{Path: "sizes[0]", Message: "must be integer, but is string"},
+			},
+		},
+		{
+			name:     "maxItems",
+			document: `{"title": "Sort", "judge": [], "sizes": [1, 2, 3, 4]}`,
+			want:     []Error{{Path: "sizes", Message: "must have at most 3 items, but has 4"}},
+		},
+		{
+			name:     "$ref",
+			document: `{"title": "Sort", "judge": [{"stdout": 1}, {"command": "./a.out", "stdot": "out.txt"}]}`,
+			want: []Error{
+				{Path: "judge[0].command", Message: "is required"},
+				{Path: "judge[0].stdout", Message: "must be string or null, but is integer"},
+				{Path: "judge[1].stdot", Message: `is not a known key, did you mean "stdout"?`},
+			},
+		},
+		{
+			name:     "type mismatch stops other keywords",
+			document: `{"title": "Sort", "judge": [], "policy": 1}`,
+			want:     []Error{{Path: "policy", Message: "must be string, but is integer"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := schema.ValidateJSON([]byte(tt.document))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ValidateJSON() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		schema  string
+		wantErr string
+	}{
+		{
+			name:   "valid patterns",
+			schema: `{"properties": {"a": {"pattern": "^a+$"}}, "definitions": {"b": {"items": {"pattern": "b*"}}}}`,
+		},
+		{
+			name:    "invalid JSON",
+			schema:  `{"type": }`,
+			wantErr: "failed to parse schema",
+		},
+		{
+			name:    "invalid pattern in properties",
+			schema:  `{"properties": {"a": {"pattern": "(a"}}}`,
+			wantErr: "invalid pattern at #/properties/a/pattern",
+		},
+		{
+			name:    "invalid pattern in propertyNames",
+			schema:  `{"additionalProperties": {"propertyNames": {"pattern": "[a"}}}`,
+			wantErr: "invalid pattern at #/additionalProperties/propertyNames/pattern",
+		},
+		{
+			name:    "invalid pattern in definitions",
+			schema:  `{"definitions": {"b": {"items": {"pattern": "*"}}}}`,
+			wantErr: "invalid pattern at #/definitions/b/items/pattern",
+		},
+		{
+			name:   "pattern as a property name",
+			schema: `{"properties": {"pattern": {"type": "string"}}}`,
+		},
+		{
+			name:   "annotations",
+			schema: `{"$schema": "http://json-schema.org/draft-07/schema#", "title": "a", "properties": {"a": {"description": "b", "default": 1}}}`,
+		},
+		{
+			name:    "unsupported keyword",
+			schema:  `{"properties": {"a": {"type": "string", "minLength": 1}}}`,
+			wantErr: "unsupported keyword at #/properties/a/minLength",
+		},
+		{
+			name:    "unsupported combinator",
+			schema:  `{"definitions": {"b": {"oneOf": [{"type": "string"}, {"type": "integer"}]}}}`,
+			wantErr: "unsupported keyword at #/definitions/b/oneOf",
+		},
+		{
+			name:    "keyword next to $ref",
+			schema:  `{"properties": {"a": {"$ref": "#/definitions/b", "minimum": 1}}, "definitions": {"b": {"type": "integer"}}}`,
+			wantErr: "keyword next to $ref at #/properties/a/minimum",
+		},
+		{
+			name:    "unresolvable reference",
+			schema:  `{"items": {"$ref": "#/definitions/missing"}}`,
+			wantErr: "invalid reference at #/items/$ref",
+		},
+		{
+			name:    "unknown type",
+			schema:  `{"properties": {"a": {"type": ["string", "int"]}}}`,
+			wantErr: `unknown type "int" at #/properties/a/type`,
+		},
+		{
+			name:    "tuple items",
+			schema:  `{"items": [{"type": "string"}]}`,
+			wantErr: "unsupported value at #/items",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.schema))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Parse() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Parse() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseConfigSchema(t *testing.T) {
+	// The schema of problem configs must only use supported keywords
+	data, err := os.ReadFile("../../resource/schema.json")
+	if err != nil {
+		t.Fatalf("failed to read schema: %v", err)
+	}
+	if _, err := Parse(data); err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+}
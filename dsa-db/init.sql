@@ -56,7 +56,8 @@ CREATE TABLE IF NOT EXISTS Lecture (
     id SERIAL PRIMARY KEY,
     title VARCHAR(255) NOT NULL,
     start_date TIMESTAMP(0) WITH TIME ZONE NOT NULL,
-    deadline TIMESTAMP(0) WITH TIME ZONE NOT NULL
+    deadline TIMESTAMP(0) WITH TIME ZONE NOT NULL,
+    late_policy JSONB -- NULL if late submissions are not penalized
 );
 
 CREATE TABLE IF NOT EXISTS Problem (
@@ -66,10 +67,47 @@ CREATE TABLE IF NOT EXISTS Problem (
     title VARCHAR(255) NOT NULL,
     resource_location_id INTEGER NOT NULL REFERENCES FileLocation(id),
     detail JSONB NOT NULL,
+    status VARCHAR(255) NOT NULL DEFAULT 'unverified',
+    version INTEGER NOT NULL DEFAULT 1,
+    deadline TIMESTAMP(0) WITH TIME ZONE, -- NULL for the deadline of the lecture
+    late_policy JSONB, -- NULL for the late policy of the lecture
     PRIMARY KEY (lecture_id, problem_id),
     FOREIGN KEY (lecture_id) REFERENCES Lecture(id) ON DELETE CASCADE
 );
 
+-- Individual extensions of deadlines for students, e.g., for approved accommodations or medical leave.
+CREATE TABLE IF NOT EXISTS DeadlineExtension (
+    id SERIAL PRIMARY KEY,
+    usercode INTEGER NOT NULL,
+    lecture_id INTEGER NOT NULL,
+    problem_id INTEGER, -- NULL for all problems of the lecture
+    deadline TIMESTAMP(0) WITH TIME ZONE NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    granted_usercode INTEGER NOT NULL,
+    granted_at TIMESTAMP(0) WITH TIME ZONE NOT NULL,
+    UNIQUE NULLS NOT DISTINCT (usercode, lecture_id, problem_id),
+    FOREIGN KEY (usercode) REFERENCES UserList(id) ON DELETE CASCADE,
+    FOREIGN KEY (lecture_id) REFERENCES Lecture(id) ON DELETE CASCADE,
+    -- Not checked if problem_id is NULL
+    FOREIGN KEY (lecture_id, problem_id) REFERENCES Problem(lecture_id, problem_id) ON DELETE CASCADE,
+    FOREIGN KEY (granted_usercode) REFERENCES UserList(id) ON DELETE CASCADE
+);
+
+-- Every uploaded revision of a problem. Problem holds a copy of the current one.
+CREATE TABLE IF NOT EXISTS ProblemVersion (
+    lecture_id INTEGER NOT NULL,
+    problem_id INTEGER NOT NULL,
+    version INTEGER NOT NULL,
+    registered_at TIMESTAMP(0) WITH TIME ZONE NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    resource_location_id INTEGER NOT NULL REFERENCES FileLocation(id),
+    detail JSONB NOT NULL,
+    status VARCHAR(255) NOT NULL DEFAULT 'unverified',
+    resource_digest VARCHAR(64),
+    PRIMARY KEY (lecture_id, problem_id, version),
+    FOREIGN KEY (lecture_id, problem_id) REFERENCES Problem(lecture_id, problem_id) ON DELETE CASCADE
+);
+
 CREATE TABLE IF NOT EXISTS FileReference (
     id SERIAL PRIMARY KEY,
     lecture_id INTEGER NOT NULL,
@@ -92,6 +130,7 @@ CREATE TABLE IF NOT EXISTS ValidationRequest (
     usercode INTEGER NOT NULL,
     lecture_id INTEGER NOT NULL,
     problem_id INTEGER NOT NULL,
+    problem_version INTEGER NOT NULL DEFAULT 1,
     upload_dir_id INTEGER NOT NULL REFERENCES FileLocation(id),
     result INTEGER NOT NULL REFERENCES ResultValues(value),
     log JSONB NOT NULL,
@@ -107,6 +146,7 @@ CREATE TABLE IF NOT EXISTS GradingRequest (
     id SERIAL NOT NULL UNIQUE,
     ts TIMESTAMP(0) WITH TIME ZONE NOT NULL,
     request_usercode INTEGER NOT NULL,
+    problem_version INTEGER NOT NULL DEFAULT 1,
     upload_dir_id INTEGER NOT NULL REFERENCES FileLocation(id),
     result INTEGER NOT NULL REFERENCES ResultValues(value),
     log JSONB NOT NULL,
@@ -118,6 +158,44 @@ CREATE TABLE IF NOT EXISTS GradingRequest (
 
 CREATE INDEX idx_grading_request ON GradingRequest (id);
 
+-- A rejudge of existing grading requests, e.g., after test data are fixed.
+CREATE TABLE IF NOT EXISTS Rejudge (
+    id SERIAL PRIMARY KEY,
+    ts TIMESTAMP(0) WITH TIME ZONE NOT NULL,
+    request_usercode INTEGER NOT NULL,
+    lecture_id INTEGER NOT NULL,
+    problem_id INTEGER, -- NULL for all problems of the lecture
+    request_id INTEGER, -- NULL for all grading requests of the problems
+    results JSONB NOT NULL, -- verdicts to rejudge, empty for all
+    total INTEGER NOT NULL,
+    FOREIGN KEY (lecture_id) REFERENCES Lecture(id) ON DELETE CASCADE,
+    FOREIGN KEY (request_usercode) REFERENCES UserList(id) ON DELETE CASCADE
+);
+
+-- Results of grading requests replaced by rejudges.
+CREATE TABLE IF NOT EXISTS GradingRequestHistory (
+    id SERIAL PRIMARY KEY,
+    request_id INTEGER NOT NULL REFERENCES GradingRequest(id) ON DELETE CASCADE,
+    rejudge_id INTEGER NOT NULL REFERENCES Rejudge(id) ON DELETE CASCADE,
+    archived_at TIMESTAMP(0) WITH TIME ZONE NOT NULL,
+    problem_version INTEGER NOT NULL,
+    result INTEGER NOT NULL REFERENCES ResultValues(value),
+    log JSONB NOT NULL
+);
+
+CREATE INDEX idx_grading_request_history ON GradingRequestHistory (rejudge_id);
+
+CREATE TABLE IF NOT EXISTS ProblemVerification (
+    id SERIAL PRIMARY KEY,
+    lecture_id INTEGER NOT NULL,
+    problem_id INTEGER NOT NULL,
+    problem_version INTEGER NOT NULL DEFAULT 1,
+    ts TIMESTAMP(0) WITH TIME ZONE NOT NULL,
+    result INTEGER NOT NULL REFERENCES ResultValues(value),
+    log JSONB NOT NULL,
+    FOREIGN KEY (lecture_id, problem_id) REFERENCES Problem(lecture_id, problem_id) ON DELETE CASCADE
+);
+
 CREATE TABLE IF NOT EXISTS JobQueue (
     id SERIAL PRIMARY KEY,
     request_type VARCHAR(255) NOT NULL,
@@ -136,6 +214,24 @@ CREATE TABLE IF NOT EXISTS ResultQueue (
     FOREIGN KEY (job_id) REFERENCES JobQueue(id) ON DELETE CASCADE
 );
 
+-- Digests of the sandbox images of judge servers. The one registered last is current,
+-- and only results cached with it are reused.
+CREATE TABLE IF NOT EXISTS SandboxImage (
+    digest VARCHAR(64) PRIMARY KEY,
+    registered_at TIMESTAMP(0) WITH TIME ZONE NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS ResultCache (
+    content_hash VARCHAR(64) PRIMARY KEY,
+    image_digest VARCHAR(64) NOT NULL,
+    lecture_id INTEGER NOT NULL,
+    problem_id INTEGER NOT NULL,
+    created_at TIMESTAMP(0) WITH TIME ZONE NOT NULL,
+    result INTEGER NOT NULL REFERENCES ResultValues(value),
+    log JSONB NOT NULL,
+    FOREIGN KEY (lecture_id, problem_id) REFERENCES Problem(lecture_id, problem_id) ON DELETE CASCADE
+);
+
 -- setting of grant
 GRANT CONNECT ON DATABASE dsa_db TO dsa_app;
 GRANT USAGE ON SCHEMA public TO dsa_app;
@@ -21,6 +21,7 @@ import GradingMenu from './pages/grading/GradingMenu';
 import GradingResultsListing from './pages/grading/GradingResultsListing';
 import GradingDetail from './pages/grading/GradingDetail';
 import SingleGradingUpload from './pages/grading/SingleGradingUpload';
+import DeadlineExtensions from './pages/grading/DeadlineExtensions';
 
 const queryClient = new QueryClient();
 
@@ -50,6 +51,7 @@ function App() {
             <Route path="upload/single" element={<SingleGradingUpload />} />
             <Route path="results" element={<GradingResultsListing />} />
             <Route path="detail/:lectureid/:userid" element={<GradingDetail />} />
+            <Route path="extensions" element={<DeadlineExtensions />} />
           </Route>
           <Route path="/" element={<Navigate to="/login" />} />
           <Route path="*" element={<NotFoundPage />} />
@@ -0,0 +1,97 @@
+import React from "react";
+import type { BenchmarkDetail, BenchmarkPoint } from "../types/BenchmarkDetail";
+import ResultBadge from "./ResultBadge";
+
+interface BenchmarkTableProps {
+  logs: BenchmarkDetail[];
+}
+
+const CHART_WIDTH = 320;
+const CHART_HEIGHT = 160;
+
+// Plots execution time against input size
+const BenchmarkChart: React.FC<{ points: BenchmarkPoint[] }> = ({ points }) => {
+  if (points.length < 2) {
+    return null;
+  }
+
+  const maxN = Math.max(...points.map((point) => point.n));
+  const maxTime = Math.max(1, ...points.map((point) => point.time_ms));
+  const coordinates = points.map((point) => ({
+    x: (point.n / maxN) * CHART_WIDTH,
+    y: CHART_HEIGHT - (point.time_ms / maxTime) * CHART_HEIGHT,
+  }));
+
+  return (
+    <svg
+      viewBox={`-8 -8 ${CHART_WIDTH + 16} ${CHART_HEIGHT + 16}`}
+      className="w-80 h-40 bg-white border border-gray-300 rounded"
+    >
+      <polyline
+        points={coordinates.map((c) => `${c.x},${c.y}`).join(" ")}
+        fill="none"
+        stroke="#3b82f6"
+        strokeWidth={2}
+      />
+      {coordinates.map((c, index) => (
+        <circle key={index} cx={c.x} cy={c.y} r={3} fill="#3b82f6" />
+      ))}
+    </svg>
+  );
+};
+
+const BenchmarkTable: React.FC<BenchmarkTableProps> = ({ logs }) => {
+  return (
+    <div className="w-full space-y-6">
+      {logs.map((log) => (
+        <div key={log.task_id} className="space-y-2">
+          <div className="flex items-center gap-4">
+            <ResultBadge resultID={log.result_id} />
+            <span className="font-semibold">{log.title}</span>
+            <span className="text-sm text-gray-600">{log.description}</span>
+          </div>
+          {log.error && (
+            <div className="text-sm text-red-600">{log.error}</div>
+          )}
+          <div className="text-sm">
+            <span className="font-semibold">推定された計算量: </span>
+            <span>{log.estimated_class || "(推定できませんでした)"}</span>
+            {log.expected_classes.length > 0 && (
+              <span className="ml-4 text-gray-600">想定: {log.expected_classes.join(", ")}</span>
+            )}
+          </div>
+          <div className="flex gap-8 items-start">
+            <table className="text-sm border-collapse">
+              <thead>
+                <tr className="border-b border-gray-300">
+                  <th className="px-2 text-right">n</th>
+                  <th className="px-2 text-center">結果</th>
+                  <th className="px-2 text-right">実行時間</th>
+                  <th className="px-2 text-right">メモリ</th>
+                </tr>
+              </thead>
+              <tbody>
+                {log.points.map((point) => (
+                  <tr key={point.n} className="border-b border-gray-200">
+                    <td className="px-2 text-right">{point.n}</td>
+                    <td className="px-2 text-center"><ResultBadge resultID={point.result_id} /></td>
+                    <td className="px-2 text-right">{point.time_ms} ms</td>
+                    <td className="px-2 text-right">{point.memory_kb} KiB</td>
+                  </tr>
+                ))}
+              </tbody>
+            </table>
+            <BenchmarkChart points={log.points} />
+          </div>
+          {log.fits.length > 0 && (
+            <div className="text-xs text-gray-600">
+              残差: {log.fits.map((fit) => `${fit.class}: ${fit.residual.toFixed(3)}`).join(" / ")}
+            </div>
+          )}
+        </div>
+      ))}
+    </div>
+  );
+};
+
+export default BenchmarkTable;
@@ -52,6 +52,7 @@ const DetailedTaskLogTable: React.FC<DetailedTaskLogTableProps> = ({ logs }) =>
             <th className="p-2 text-left">説明</th>
             <th className="p-2 text-center w-24">結果</th>
             <th className="p-2 text-right w-24">実行時間</th>
+            <th className="p-2 text-right w-24">CPU時間</th>
             <th className="p-2 text-right w-32">メモリ</th>
           </tr>
         </thead>
@@ -69,17 +70,26 @@ const DetailedTaskLogTable: React.FC<DetailedTaskLogTableProps> = ({ logs }) =>
                     <ChevronDown className={`w-4 h-4 transition-transform ${expandedRows.has(index) ? "rotate-180" : ""}`} />
                   </button>
                 </td>
-                <td className="p-2">{log.description}</td>
+                <td className="p-2">
+                  {log.description}
+                  {log.message_on_fail && (
+                    <div className="text-sm text-red-700 whitespace-pre-wrap">{log.message_on_fail}</div>
+                  )}
+                </td>
                 <td className="p-2 text-center">
                   <ResultBadge resultID={log.result_id} />
+                  {log.flaky && (
+                    <span className="ml-1 text-xs text-orange-700 bg-orange-50 border border-orange-300 rounded px-1">flaky</span>
+                  )}
                 </td>
                 <td className="p-2 text-right">{log.time_ms} ms</td>
+                <td className="p-2 text-right">{log.cpu_time_ms} ms</td>
                 <td className="p-2 text-right">{log.memory_kb} KiB</td>
               </tr>
 
               {expandedRows.has(index) && (
                 <tr>
-                  <td colSpan={5} className="bg-gray-50 p-4 border-b border-gray-200">
+                  <td colSpan={6} className="bg-gray-50 p-4 border-b border-gray-200">
                     <div className="space-y-4">
                       {/* Test Case Info */}
                       <div className="grid grid-cols-1 gap-2 text-sm">
@@ -99,6 +109,147 @@ const DetailedTaskLogTable: React.FC<DetailedTaskLogTableProps> = ({ logs }) =>
                         </div>
                       </div>
 
+                      {/* Repeated Runs */}
+                      {log.runs.length > 0 && (
+                        <div>
+                          <h4 className="font-semibold">
+                            各回の実行結果
+                            {log.flaky && <span className="ml-2 text-sm text-orange-700">(判定が実行ごとに異なります)</span>}
+                          </h4>
+                          <table className="text-sm border-collapse">
+                            <thead>
+                              <tr className="border-b border-gray-300">
+                                <th className="px-2 text-left">#</th>
+                                <th className="px-2 text-center">結果</th>
+                                <th className="px-2 text-right">実行時間</th>
+                                <th className="px-2 text-right">CPU時間</th>
+                                <th className="px-2 text-right">メモリ</th>
+                                <th className="px-2 text-right">Exit code</th>
+                              </tr>
+                            </thead>
+                            <tbody>
+                              {log.runs.map((run, runIndex) => (
+                                <tr key={runIndex} className="border-b border-gray-200">
+                                  <td className="px-2">{runIndex + 1}</td>
+                                  <td className="px-2 text-center"><ResultBadge resultID={run.result_id} /></td>
+                                  <td className="px-2 text-right">{run.time_ms} ms</td>
+                                  <td className="px-2 text-right">{run.cpu_time_ms} ms</td>
+                                  <td className="px-2 text-right">{run.memory_kb} KiB</td>
+                                  <td className="px-2 text-right">{run.exit_code}</td>
+                                </tr>
+                              ))}
+                            </tbody>
+                          </table>
+                        </div>
+                      )}
+
+                      {/* CPU Time of Threads */}
+                      {log.threads.length > 1 && (
+                        <div>
+                          <h4 className="font-semibold">
+                            スレッドごとのCPU時間
+                            {log.time_ms > 0 && (
+                              <span className="ml-2 text-sm font-normal text-gray-600">
+                                (CPU時間 / 実行時間 = {(log.cpu_time_ms / log.time_ms).toFixed(2)})
+                              </span>
+                            )}
+                          </h4>
+                          <table className="text-sm border-collapse">
+                            <thead>
+                              <tr className="border-b border-gray-300">
+                                <th className="px-2 text-left">TID</th>
+                                <th className="px-2 text-left">プログラム</th>
+                                <th className="px-2 text-right">CPU時間</th>
+                              </tr>
+                            </thead>
+                            <tbody>
+                              {log.threads.map((thread) => (
+                                <tr key={thread.tid} className="border-b border-gray-200">
+                                  <td className="px-2">{thread.tid}</td>
+                                  <td className="px-2 font-mono">{thread.name}</td>
+                                  <td className="px-2 text-right">{thread.cpu_time_ms} ms</td>
+                                </tr>
+                              ))}
+                            </tbody>
+                          </table>
+                        </div>
+                      )}
+
+                      {/* Valgrind Memcheck */}
+                      {log.memcheck.length > 0 && (
+                        <div>
+                          <h4 className="font-semibold">Valgrind によるメモリチェック</h4>
+                          <ul className="text-sm space-y-1">
+                            {log.memcheck.map((finding, findingIndex) => (
+                              <li
+                                key={findingIndex}
+                                className={`border rounded p-2 ${finding.leak ? "bg-orange-50 border-orange-300" : "bg-red-50 border-red-300"}`}
+                              >
+                                <span className="font-mono text-xs mr-2">{finding.kind}</span>
+                                <span>{finding.message}</span>
+                                {finding.location && (
+                                  <span className="ml-2 font-mono text-xs text-gray-600">at {finding.location}</span>
+                                )}
+                              </li>
+                            ))}
+                          </ul>
+                        </div>
+                      )}
+
+                      {/* Processes of Multi-process Task */}
+                      {log.processes.length > 0 && (
+                        <div>
+                          <h4 className="font-semibold">各プロセスの実行結果</h4>
+                          <p className="text-sm text-gray-600">上記のコマンドは、これらのプロセスの結果を検査します。CPU時間とメモリはコンテナ全体の使用量です。</p>
+                          <table className="text-sm border-collapse">
+                            <thead>
+                              <tr className="border-b border-gray-300">
+                                <th className="px-2 text-left">名前</th>
+                                <th className="px-2 text-left">コマンド</th>
+                                <th className="px-2 text-center">結果</th>
+                                <th className="px-2 text-right">実行時間</th>
+                                <th className="px-2 text-right">CPU時間</th>
+                                <th className="px-2 text-right">メモリ</th>
+                                <th className="px-2 text-right">Exit code</th>
+                              </tr>
+                            </thead>
+                            <tbody>
+                              {log.processes.map((process, processIndex) => (
+                                <tr key={processIndex} className="border-b border-gray-200">
+                                  <td className="px-2">
+                                    {process.name}
+                                    {process.background && <span className="ml-1 text-xs text-gray-600">(background)</span>}
+                                    {!process.ready && <span className="ml-1 text-xs text-red-700">(起動確認に失敗)</span>}
+                                  </td>
+                                  <td className="px-2 font-mono">{process.command}</td>
+                                  <td className="px-2 text-center"><ResultBadge resultID={process.result_id} /></td>
+                                  <td className="px-2 text-right">{process.time_ms} ms</td>
+                                  <td className="px-2 text-right">{process.cpu_time_ms} ms</td>
+                                  <td className="px-2 text-right">{process.memory_kb} KiB</td>
+                                  <td className="px-2 text-right">{process.exit_code}</td>
+                                </tr>
+                              ))}
+                            </tbody>
+                          </table>
+                          {log.processes.map((process, processIndex) => (
+                            <details key={processIndex} className="mt-2">
+                              <summary className="cursor-pointer text-sm">
+                                {process.name} の出力
+                                {process.truncated && <span className="ml-2 text-orange-700">(途中まで)</span>}
+                              </summary>
+                              <div className="grid grid-cols-2 gap-2 mt-1">
+                                <pre className="text-sm font-mono whitespace-pre-wrap bg-white border border-gray-300 rounded p-2 max-h-40 overflow-auto">
+                                  {process.stdout || "(No stdout)"}
+                                </pre>
+                                <pre className="text-sm font-mono whitespace-pre-wrap bg-white border border-gray-300 rounded p-2 max-h-40 overflow-auto">
+                                  {process.stderr || "(No stderr)"}
+                                </pre>
+                              </div>
+                            </details>
+                          ))}
+                        </div>
+                      )}
+
                       {/* Standard Input */}
                       <div>
                         <h4 className="font-semibold">標準入力 (stdin)</h4>
@@ -111,6 +262,11 @@ const DetailedTaskLogTable: React.FC<DetailedTaskLogTableProps> = ({ logs }) =>
 
                       {/* Standard Output */}
                       <div>
+                        {log.truncated && (
+                          <div className="text-sm text-orange-700 bg-orange-50 border border-orange-300 rounded p-2 mb-2">
+                            出力が長すぎるため、途中までのみ保存・表示されています。
+                          </div>
+                        )}
                         <div className="flex items-center gap-4 mb-2">
                           <h4 className="font-semibold">標準出力 (stdout)</h4>
                           {log.expected_stdout && (
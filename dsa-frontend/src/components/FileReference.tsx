@@ -0,0 +1,56 @@
+import React, { useEffect, useState } from "react";
+import { saveAs } from "file-saver";
+import { addAuthorizationHeader } from "../auth/hooks";
+import { axiosClient } from "../api/axiosClient";
+
+// Links in problem descriptions to files in the problem package are rewritten to this endpoint,
+// which requires the Authorization header, so that they cannot be used as they are.
+const FILE_REFERENCE_PREFIX = "/problem/fetch/fileref/";
+
+export const isFileReference = (url: unknown): url is string => {
+  return typeof url === "string" && url.startsWith(FILE_REFERENCE_PREFIX);
+};
+
+const fetchFileReference = async (url: string): Promise<Blob> => {
+  const config = addAuthorizationHeader({ responseType: 'blob' });
+  const result = await axiosClient.get<Blob>(url, config);
+  return result.data;
+};
+
+// Image in a problem description, loaded with the Authorization header
+export const FileReferenceImage: React.FC<React.ImgHTMLAttributes<HTMLImageElement> & { src: string }> = ({ src, ...rest }) => {
+  const [objectUrl, setObjectUrl] = useState<string | null>(null);
+
+  useEffect(() => {
+    let url: string | null = null;
+    let cancelled = false;
+    fetchFileReference(src)
+      .then((blob) => {
+        if (cancelled) return;
+        url = URL.createObjectURL(blob);
+        setObjectUrl(url);
+      })
+      .catch((error) => console.error("Error loading image:", error));
+
+    return () => {
+      cancelled = true;
+      if (url) URL.revokeObjectURL(url);
+    };
+  }, [src]);
+
+  if (!objectUrl) {
+    return <span className="text-gray-400 text-sm">{rest.alt ?? "Loading..."}</span>;
+  }
+  return <img src={objectUrl} {...rest} />;
+};
+
+// Downloads an attachment of a problem description, loaded with the Authorization header
+export const downloadFileReference = async (url: string, filename: string) => {
+  try {
+    const blob = await fetchFileReference(url);
+    saveAs(blob, filename);
+  } catch (error) {
+    console.error("Error downloading file:", error);
+    alert("Failed to download file. Please try again.");
+  }
+};
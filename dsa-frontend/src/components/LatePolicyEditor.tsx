@@ -0,0 +1,67 @@
+import React from "react";
+import type { LatePolicy } from "../types/LatePolicy";
+
+interface LatePolicyEditorProps {
+  value: LatePolicy | null;
+  onChange: (policy: LatePolicy | null) => void;
+  noneLabel: string; // label of null, e.g., "減点なし"
+}
+
+// Compact form of a late policy, used in the lecture and problem settings
+const LatePolicyEditor: React.FC<LatePolicyEditorProps> = ({ value, onChange, noneLabel }) => {
+  const handleKindChange = (kind: string) => {
+    if (kind === "") {
+      onChange(null);
+      return;
+    }
+    onChange({
+      kind: kind as LatePolicy["kind"],
+      grace_minutes: value?.grace_minutes ?? 0,
+      penalty_per_day: kind === "hard" ? undefined : (value?.penalty_per_day ?? 0.1),
+    });
+  };
+
+  return (
+    <div className="flex flex-wrap items-center gap-2 text-sm" onClick={(e) => e.stopPropagation()}>
+      <select
+        value={value?.kind ?? ""}
+        onChange={(e) => handleKindChange(e.target.value)}
+        className="border border-gray-300 rounded px-2 py-1"
+      >
+        <option value="">{noneLabel}</option>
+        <option value="hard">締切後は0点</option>
+        <option value="linear">経過時間に比例して減点</option>
+        <option value="stepped">1日ごとに減点</option>
+      </select>
+      {value && value.kind !== "hard" && (
+        <label className="flex items-center gap-1">
+          1日あたり
+          <input
+            type="number"
+            min={1}
+            max={100}
+            value={Math.round((value.penalty_per_day ?? 0) * 100)}
+            onChange={(e) => onChange({ ...value, penalty_per_day: Number(e.target.value) / 100 })}
+            className="border border-gray-300 rounded px-2 py-1 w-16"
+          />
+          %
+        </label>
+      )}
+      {value && (
+        <label className="flex items-center gap-1">
+          猶予
+          <input
+            type="number"
+            min={0}
+            value={value.grace_minutes ?? 0}
+            onChange={(e) => onChange({ ...value, grace_minutes: Number(e.target.value) })}
+            className="border border-gray-300 rounded px-2 py-1 w-16"
+          />
+          分
+        </label>
+      )}
+    </div>
+  );
+};
+
+export default LatePolicyEditor;
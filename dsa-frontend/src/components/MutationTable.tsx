@@ -0,0 +1,95 @@
+import React, { useState } from "react";
+import type { MutationDetail, MutationRun } from "../types/MutationDetail";
+import ResultBadge from "./ResultBadge";
+
+interface MutationTableProps {
+  logs: MutationDetail[];
+}
+
+const MutationRunRow: React.FC<{ run: MutationRun; isReference: boolean }> = ({ run, isReference }) => {
+  const [expanded, setExpanded] = useState<boolean>(false);
+
+  // The test suite should pass on the reference implementation, and fail on mutants
+  const status = isReference
+    ? (run.result_id === 0 ? { text: "合格", className: "text-green-600" } : { text: "不合格", className: "text-red-600" })
+    : (run.killed ? { text: "検出", className: "text-green-600" } : { text: "見逃し", className: "text-red-600" });
+
+  return (
+    <>
+      <tr onClick={() => setExpanded(!expanded)} className="border-b border-gray-200 hover:bg-gray-50 cursor-pointer">
+        <td className="px-2 font-mono">{isReference ? `${run.name} (模範実装)` : run.name}</td>
+        <td className="px-2 text-center"><ResultBadge resultID={run.result_id} /></td>
+        <td className={`px-2 text-center ${status.className}`}>{status.text}</td>
+        <td className="px-2 text-right">{run.time_ms} ms</td>
+        <td className="px-2 text-right">{run.exit_code}</td>
+      </tr>
+      {expanded && (
+        <tr>
+          <td colSpan={5} className="bg-gray-50 p-2 border-b border-gray-200">
+            <div className="grid grid-cols-2 gap-2">
+              <div>
+                <div className="text-xs text-gray-600 mb-1">標準出力 (stdout)</div>
+                <pre className="bg-white border border-gray-300 rounded p-2 max-h-40 overflow-auto text-sm font-mono whitespace-pre-wrap">{run.stdout}</pre>
+              </div>
+              <div>
+                <div className="text-xs text-gray-600 mb-1">標準エラー出力 (stderr)</div>
+                <pre className="bg-white border border-gray-300 rounded p-2 max-h-40 overflow-auto text-sm font-mono whitespace-pre-wrap">{run.stderr}</pre>
+              </div>
+            </div>
+          </td>
+        </tr>
+      )}
+    </>
+  );
+};
+
+const MutationTable: React.FC<MutationTableProps> = ({ logs }) => {
+  return (
+    <div className="w-full space-y-6">
+      {logs.map((log) => (
+        <div key={log.task_id} className="space-y-2">
+          <div className="flex items-center gap-4">
+            <ResultBadge resultID={log.result_id} />
+            <span className="font-semibold">{log.title}</span>
+            <span className="text-sm text-gray-600">{log.description}</span>
+          </div>
+          <div className="text-sm">
+            <span className="font-semibold">スコア: </span>
+            <span>
+              {log.mutants.length > 0
+                ? `${Math.round(log.score * 100)}% (${log.mutants.length - log.survived.length} / ${log.mutants.length})`
+                : "(模範実装でテストが通らないため評価できません)"}
+            </span>
+            {log.min_score > 0 && (
+              <span className="ml-4 text-gray-600">合格基準: {Math.round(log.min_score * 100)}%</span>
+            )}
+          </div>
+          {log.survived.length > 0 && (
+            <div className="text-sm text-orange-700">
+              見逃したミュータント: <span className="font-mono">{log.survived.join(", ")}</span>
+            </div>
+          )}
+          <table className="text-sm border-collapse">
+            <thead>
+              <tr className="border-b border-gray-300">
+                <th className="px-2 text-left">実装</th>
+                <th className="px-2 text-center">結果</th>
+                <th className="px-2 text-center">判定</th>
+                <th className="px-2 text-right">実行時間</th>
+                <th className="px-2 text-right">Exit code</th>
+              </tr>
+            </thead>
+            <tbody>
+              <MutationRunRow run={log.reference} isReference={true} />
+              {log.mutants.map((mutant, index) => (
+                <MutationRunRow key={index} run={mutant} isReference={false} />
+              ))}
+            </tbody>
+          </table>
+        </div>
+      ))}
+    </div>
+  );
+};
+
+export default MutationTable;
@@ -12,6 +12,7 @@ import 'katex/dist/katex.min.css';
 import remarkMath from 'remark-math';
 import rehypeKatex from 'rehype-katex';
 import rehypeRaw from 'rehype-raw';
+import { downloadFileReference, FileReferenceImage, isFileReference } from "../components/FileReference";
 
 interface ProblemDetail {
   lecture_id: number;
@@ -19,6 +20,8 @@ interface ProblemDetail {
   title: string;
   description: string;
   time_ms: number;
+  cpu_time_ms: number;
+  cpu_cores: number;
   memory_mb: number;
   required_files: string[];
   test_files: CompressedFileData[];
@@ -51,6 +54,17 @@ const renderProblemDetail = (detail: ProblemDetail, handleOnSubmit: (files: File
                 </svg>
                 <span className="text-gray-600">{detail.time_ms} ms</span>
               </div>
+              {detail.cpu_cores > 1 && (
+                <div className="flex items-center space-x-2">
+                  <span className="text-gray-600">{detail.cpu_cores} cores</span>
+                </div>
+              )}
+              {detail.cpu_time_ms > 0 && (
+                <div className="flex items-center space-x-2">
+                  <span className="text-gray-400">CPU</span>
+                  <span className="text-gray-600">{detail.cpu_time_ms} ms</span>
+                </div>
+              )}
               <div className="flex items-center space-x-2">
                 <svg className="w-4 h-4 text-gray-400" fill="none" stroke="currentColor" viewBox="0 0 24 24">
                   <path strokeLinecap="round" strokeLinejoin="round" strokeWidth={2} d="M9 3v2m6-2v2M9 19v2m6-2v2M5 9H3m2 6H3m18-6h-2m2 6h-2M7 19h10a2 2 0 002-2V7a2 2 0 00-2-2H7a2 2 0 00-2 2v10a2 2 0 002 2z" />
@@ -131,8 +145,25 @@ const renderProblemDetail = (detail: ProblemDetail, handleOnSubmit: (files: File
                         </code>
                       );
                     },
+                    // Images in the problem package are served only with the Authorization header
+                    img: ({ src, node, ...rest }) => (
+                      isFileReference(src)
+                        ? <FileReferenceImage src={src} {...rest} />
+                        : <img src={src} {...rest} />
+                    ),
                     // Customization of link
-                    a: ({ children, href }) => (
+                    a: ({ children, href }) => isFileReference(href) ? (
+                      <a
+                        href="#"
+                        onClick={(e) => {
+                          e.preventDefault();
+                          downloadFileReference(href, typeof children === "string" ? children : "attachment");
+                        }}
+                        className="text-blue-600 no-underline hover:underline"
+                      >
+                        {children}
+                      </a>
+                    ) : (
                       <a
                         href={href}
                         target="_blank"
@@ -1,5 +1,5 @@
 import type React from "react";
-import type { DetailedTaskLog } from "../types/DetailedTaskLog";
+import type { DetailedTaskLog, ProcessLog } from "../types/DetailedTaskLog";
 import { decompressFileData, decompressString, type CompressedFileData, type FileData } from "../types/FileData";
 import { Link, useParams } from "react-router";
 import { useAuthQuery } from "../auth/hooks";
@@ -21,20 +21,36 @@ interface APIResponse {
   submission_ts: number;
   result_id: number;
   time_ms: number;
+  cpu_time_ms: number;
   memory_kb: number;
+  cached: boolean;
   uploaded_files: CompressedFileData[];
   test_files: CompressedFileData[];
   build_logs: DetailedTaskLog[];
   judge_logs: DetailedTaskLog[];
 }
 
+async function decompressProcessLog(log: ProcessLog): Promise<ProcessLog> {
+  const [stdout, stderr] = await Promise.all([
+    decompressString(log.stdout),
+    decompressString(log.stderr),
+  ]);
+
+  return {
+    ...log,
+    stdout: stdout || "",
+    stderr: stderr || "",
+  };
+}
+
 async function decompressTaskLog(log: DetailedTaskLog): Promise<DetailedTaskLog> {
-  const [stdin, stdout, stderr, expectedStdout, expectedStderr] = await Promise.all([
+  const [stdin, stdout, stderr, expectedStdout, expectedStderr, processes] = await Promise.all([
     decompressString(log.stdin),
     decompressString(log.stdout),
     decompressString(log.stderr),
     decompressString(log.expected_stdout),
     decompressString(log.expected_stderr),
+    Promise.all(log.processes.map(decompressProcessLog)),
   ]);
 
   return {
@@ -44,6 +60,7 @@ async function decompressTaskLog(log: DetailedTaskLog): Promise<DetailedTaskLog>
     stderr: stderr || "",
     expected_stdout: expectedStdout === null ? null : expectedStdout,
     expected_stderr: expectedStderr === null ? null : expectedStderr,
+    processes,
   };
 }
 
@@ -152,12 +169,19 @@ const ValidationDetail: React.FC = () => {
               <td className="px-4 py-2 font-semibold bg-gray-100">結果</td>
               <td className="px-4 py-2">
                 <ResultBadge resultID={data.result_id} />
+                {data.cached && (
+                  <span className="ml-2 text-xs text-gray-700 bg-gray-50 border border-gray-300 rounded px-1" title="同一内容の過去の採点結果を再利用しています">cached</span>
+                )}
               </td>
             </tr>
             <tr className="border-b">
               <td className="px-4 py-2 font-semibold bg-gray-100">実行時間</td>
               <td className="px-4 py-2">{data.time_ms} ms</td>
             </tr>
+            <tr className="border-b">
+              <td className="px-4 py-2 font-semibold bg-gray-100">CPU時間</td>
+              <td className="px-4 py-2">{data.cpu_time_ms} ms</td>
+            </tr>
             <tr className="border-b">
               <td className="px-4 py-2 font-semibold bg-gray-100">メモリ</td>
               <td className="px-4 py-2">{data.memory_kb} KiB</td>
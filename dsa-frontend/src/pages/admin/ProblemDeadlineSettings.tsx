@@ -0,0 +1,85 @@
+import React, { useState } from "react";
+import { Check } from "lucide-react";
+import { addAuthorizationHeader } from "../../auth/hooks";
+import { axiosClient, type SuccessResponse } from "../../api/axiosClient";
+import { formatTimestamp } from "../../util/timestamp";
+import LatePolicyEditor from "../../components/LatePolicyEditor";
+import { describeLatePolicy, type LatePolicy } from "../../types/LatePolicy";
+
+interface ProblemDeadlineSettingsProps {
+  lectureId: number;
+  problemId: number;
+  lectureDeadline: number;
+  lectureLatePolicy: LatePolicy | null;
+  problemDeadline: number | null; // null for the deadline of the lecture
+  problemLatePolicy: LatePolicy | null; // null for the late policy of the lecture
+  onSaved: () => void;
+}
+
+// Sets the deadline and the late policy of a problem, which override the ones of the lecture
+const ProblemDeadlineSettings: React.FC<ProblemDeadlineSettingsProps> = ({
+  lectureId, problemId, lectureDeadline, lectureLatePolicy, problemDeadline, problemLatePolicy, onSaved,
+}) => {
+  const [deadline, setDeadline] = useState<number | null>(problemDeadline);
+  const [latePolicy, setLatePolicy] = useState<LatePolicy | null>(problemLatePolicy);
+
+  const handleSave = async () => {
+    try {
+      const config = addAuthorizationHeader({});
+      const result = await axiosClient.patch<SuccessResponse>(
+        `/problem/crud/deadline/${lectureId}/${problemId}`,
+        { deadline, late_policy: latePolicy },
+        config,
+      );
+
+      if (result.data.message) {
+        console.log("Deadline updated successfully:", result.data.message);
+        onSaved();
+      }
+    } catch (error) {
+      console.error("Error updating deadline:", error);
+      alert(`Failed to update deadline.\n${error instanceof Error ? error.message : ""}`);
+    }
+  }
+
+  return (
+    <div className="space-y-2 text-sm">
+      <div className="flex flex-wrap items-center gap-2">
+        <span className="font-medium w-24">締切</span>
+        <label className="flex items-center gap-1">
+          <input
+            type="checkbox"
+            checked={deadline === null}
+            onChange={(e) => setDeadline(e.target.checked ? null : lectureDeadline)}
+          />
+          講義の締切を使用 ({formatTimestamp(lectureDeadline)})
+        </label>
+        {deadline !== null && (
+          <input
+            type="datetime-local"
+            value={formatTimestamp(deadline)}
+            onChange={(e) => setDeadline(Math.floor(Date.parse(e.target.value) / 1000))}
+            className="border border-gray-300 rounded px-2 py-1"
+          />
+        )}
+      </div>
+      <div className="flex flex-wrap items-center gap-2">
+        <span className="font-medium w-24">遅延提出</span>
+        <LatePolicyEditor
+          value={latePolicy}
+          onChange={setLatePolicy}
+          noneLabel={`講義の設定を使用 (${describeLatePolicy(lectureLatePolicy)})`}
+        />
+      </div>
+      <button
+        onClick={handleSave}
+        className="bg-green-500 text-white px-3 py-1 rounded hover:bg-green-600 transition-colors flex items-center gap-1"
+      >
+        <Check className="w-4 h-4" />
+        Save
+      </button>
+    </div>
+  );
+};
+
+export default ProblemDeadlineSettings;
@@ -1,14 +1,45 @@
 import React, { useState } from "react";
 import { addAuthorizationHeader, useAuthQuery } from "../../auth/hooks";
 import { axiosClient, type SuccessResponse } from "../../api/axiosClient";
-import { Check, ChevronDown, ChevronUp, Edit, Plus, Trash2, Upload, X } from "lucide-react";
+import { Check, ChevronDown, ChevronUp, Clock, Copy, Edit, History, ListChecks, Plus, Trash2, Upload, X } from "lucide-react";
 import { formatTimestamp } from "../../util/timestamp";
+import ProblemVersionHistory from "./ProblemVersionHistory";
+import ProblemDeadlineSettings from "./ProblemDeadlineSettings";
+import LatePolicyEditor from "../../components/LatePolicyEditor";
+import { describeLatePolicy, type LatePolicy } from "../../types/LatePolicy";
 
 interface Problem {
   lecture_id: number;
   problem_id: number;
   registered_at: number;
   title: string;
+  status: "unverified" | "verifying" | "verified" | "broken" | "generating" | "review";
+  version: number;
+  deadline: number; // deadline of the problem, or of the lecture if not set
+  late_policy: LatePolicy | null;
+  problem_deadline: number | null; // set on the problem itself
+  problem_late_policy: LatePolicy | null; // set on the problem itself
+}
+
+// Label of the verification status of the reference solution
+const statusLabel: { [key in Problem["status"]]: { text: string; className: string } | null } = {
+  unverified: null,
+  verifying: { text: "検証中", className: "bg-yellow-100 text-yellow-800" },
+  verified: { text: "検証済み", className: "bg-green-100 text-green-800" },
+  broken: { text: "模範解答が不合格", className: "bg-red-100 text-red-800" },
+  generating: { text: "期待出力を生成中", className: "bg-yellow-100 text-yellow-800" },
+  review: { text: "期待出力の確認待ち", className: "bg-blue-100 text-blue-800" },
+};
+
+interface LintIssue {
+  path: string;
+  message: string;
+}
+
+interface LintOutput {
+  valid: boolean;
+  title: string;
+  issues: LintIssue[];
 }
 
 interface Lecture {
@@ -16,6 +47,7 @@ interface Lecture {
   title: string;
   start_date: number;
   deadline: number;
+  late_policy: LatePolicy | null;
   problems: Problem[];
 }
 
@@ -24,6 +56,7 @@ interface LectureEntryProps {
   title: string;
   start_date: number;
   deadline: number;
+  late_policy: LatePolicy | null;
 }
 
 const ProblemRegistration: React.FC = () => {
@@ -37,14 +70,27 @@ const ProblemRegistration: React.FC = () => {
     file: null,
   });
 
+  // Result of checking the chosen ZIP file without registering it
+  const [lintResult, setLintResult] = useState<LintOutput | null>(null);
+
+  // Problem whose versions are shown, as "lectureId-problemId"
+  const [historyOfProblem, setHistoryOfProblem] = useState<string | null>(null);
+
+  // Problem whose deadline settings are shown, as "lectureId-problemId"
+  const [deadlineOfProblem, setDeadlineOfProblem] = useState<string | null>(null);
+
   const [isAddingLecture, setIsAddingLecture] = useState<boolean>(false);
-  const [newLectureData, setNewLectureData] = useState<{ id: string; title: string; start_date: number; deadline: number }>({
+  const [newLectureData, setNewLectureData] = useState<{ id: string; title: string; start_date: number; deadline: number; late_policy: LatePolicy | null }>({
     id: '',
     title: '',
     start_date: Math.floor(Date.now() / 1000),
     deadline: Math.floor(Date.now() / 1000) + 7 * 24 * 60 * 60,
+    late_policy: null,
   });
 
+  // Lecture whose problems are copied into the new lecture, if it is a clone
+  const [cloneSourceId, setCloneSourceId] = useState<number | null>(null);
+
   const [lastFetchTime, setLastFetchTime] = useState<number>(Date.now());
 
   const lectureDataQuery = useAuthQuery<Lecture[]>({
@@ -118,10 +164,10 @@ const ProblemRegistration: React.FC = () => {
       return;
     }
 
-    // Check if problemId already exists in the lecture
+    // Uploading to an existing problem registers a new version of it
     const lecture = lectureData?.find(l => l.lecture_id === lectureId);
-    if (lecture?.problems.some(p => p.problem_id === problemId)) {
-      alert("A problem with this Problem ID already exists in the lecture.");
+    const existing = lecture?.problems.find(p => p.problem_id === problemId);
+    if (existing && !confirm(`Problem ${problemId} already exists (v${existing.version}). Upload as a new version?`)) {
       return;
     }
 
@@ -130,9 +176,7 @@ const ProblemRegistration: React.FC = () => {
     if (success) {
       setAddingProblemToLecture(null);
       setNewProblemData({ problemId: '', file: null });
-    }
-    else {
-      alert("Failed to add problem entry. Please try again.");
+      setLintResult(null);
     }
   }
 
@@ -159,16 +203,21 @@ const ProblemRegistration: React.FC = () => {
       return;
     }
 
-    const success = await handleAddLectureEntry({
+    const entry = {
       id: lectureId,
       title: newLectureData.title,
       start_date: newLectureData.start_date,
       deadline: newLectureData.deadline,
-    });
+      late_policy: newLectureData.late_policy,
+    };
+    const success = cloneSourceId !== null
+      ? await handleCloneLectureEntry(cloneSourceId, entry)
+      : await handleAddLectureEntry(entry);
 
     if (success) {
       setIsAddingLecture(false);
-      setNewLectureData({ id: '', title: '', start_date: Math.floor(Date.now() / 1000), deadline: Math.floor(Date.now() / 1000) + 7 * 24 * 60 * 60 });
+      setCloneSourceId(null);
+      setNewLectureData({ id: '', title: '', start_date: Math.floor(Date.now() / 1000), deadline: Math.floor(Date.now() / 1000) + 7 * 24 * 60 * 60, late_policy: null });
     } else {
       alert("Failed to add lecture entry. Please try again.");
     }
@@ -244,6 +293,31 @@ const ProblemRegistration: React.FC = () => {
     }
   }
 
+  const handlePublishProblem = async (lectureId: number, problemId: number) => {
+    if (!confirm("Have you reviewed the generated expected outputs? The problem will be visible to students.")) {
+      return;
+    }
+
+    try {
+      const config = addAuthorizationHeader({});
+      const result = await axiosClient.post<SuccessResponse>(
+        `/problem/crud/publish/${lectureId}/${problemId}`,
+        {},
+        config,
+      );
+
+      if (result.data.message) {
+        console.log("Problem published successfully:", result.data.message);
+
+        setLastFetchTime(Date.now());
+        // lectureData will be refetched due to lastFetchTime change
+      }
+    } catch (error) {
+      console.error("Error publishing problem:", error);
+      alert("Failed to publish problem. Please try again.");
+    }
+  }
+
   const handleAddProblemEntry = async (lectureId: number, problemId: number, zipFile: File) => {
     try {
       const formData = new FormData();
@@ -270,11 +344,40 @@ const ProblemRegistration: React.FC = () => {
       }
     } catch (error) {
       console.error("Error adding problem entry:", error);
-      alert("Failed to add problem entry. Please try again.");
+      // The message lists all problems found in init.json or init.yaml
+      alert(`Failed to add problem entry.\n${error instanceof Error ? error.message : ""}`);
       return false;
     }
   }
 
+  const handleLintProblemPackage = async (zipFile: File | null) => {
+    if (!zipFile) {
+      alert("Please select a ZIP file to check.");
+      return;
+    }
+
+    try {
+      const formData = new FormData();
+      formData.append("file", zipFile);
+
+      const config = addAuthorizationHeader({
+        headers: {
+          'Content-Type': 'multipart/form-data',
+        },
+      });
+
+      const result = await axiosClient.post<LintOutput>(
+        `/problem/crud/lint`,
+        formData,
+        config,
+      );
+      setLintResult(result.data);
+    } catch (error) {
+      console.error("Error checking problem package:", error);
+      alert("Failed to check problem package. Please try again.");
+    }
+  }
+
   const handleAddLectureEntry = async (entry: LectureEntryProps) => {
     try {
       const config = addAuthorizationHeader({});
@@ -298,6 +401,29 @@ const ProblemRegistration: React.FC = () => {
     }
   }
 
+  const handleCloneLectureEntry = async (sourceId: number, entry: LectureEntryProps) => {
+    try {
+      const config = addAuthorizationHeader({});
+      const result = await axiosClient.post<SuccessResponse>(
+        `/problem/crud/clone/${sourceId}`,
+        entry,
+        config,
+      );
+
+      if (result.data.message) {
+        console.log("Lecture entry cloned successfully:", result.data.message);
+
+        setLastFetchTime(Date.now());
+        // lectureData will be refetched due to lastFetchTime change
+        return true;
+      }
+    } catch (error) {
+      console.error("Error cloning lecture entry:", error);
+      alert("Failed to clone lecture entry. Please try again.");
+      return false;
+    }
+  }
+
   if (isPending) {
     return (
       <div className="min-h-screen flex items-center justify-center">
@@ -314,7 +440,9 @@ const ProblemRegistration: React.FC = () => {
     );
   }
 
-  const renderProblemRows = (lectureId: number, problems: Problem[]) => {
+  const renderProblemRows = (lecture: Lecture) => {
+    const lectureId = lecture.lecture_id;
+    const problems = lecture.problems;
     return (
       <table className="w-full">
         <thead className="bg-white border-b border-gray-200">
@@ -332,10 +460,53 @@ const ProblemRegistration: React.FC = () => {
         </thead>
         <tbody className="bg-white divide-y divide-gray-200">
           {problems.map((problem) => (
-            <tr key={problem.problem_id}>
+            <React.Fragment key={problem.problem_id}>
+            <tr>
               <td className="px-4 py-2 text-sm">{problem.problem_id}</td>
-              <td className="px-4 py-2 text-sm">{problem.title}</td>
+              <td className="px-4 py-2 text-sm">
+                {problem.title}
+                <span className="ml-2 text-xs text-gray-500">v{problem.version}</span>
+                {statusLabel[problem.status] && (
+                  <span className={`ml-2 px-2 py-0.5 rounded text-xs ${statusLabel[problem.status]!.className}`}>
+                    {statusLabel[problem.status]!.text}
+                  </span>
+                )}
+                {(problem.problem_deadline !== null || problem.problem_late_policy !== null) && (
+                  <div className="text-xs text-gray-500">
+                    締切: {formatTimestamp(problem.deadline)} / {describeLatePolicy(problem.late_policy)}
+                  </div>
+                )}
+              </td>
               <td className="px-4 py-2 text-sm text-right">
+                {problem.status === "review" && (
+                  <button
+                    onClick={() => handlePublishProblem(problem.lecture_id, problem.problem_id)}
+                    className="bg-blue-500 text-white px-3 py-1 rounded hover:bg-blue-600 transition-colors flex items-center gap-1 ml-auto mb-1"
+                  >
+                    <Check className="w-4 h-4" />
+                    Publish
+                  </button>
+                )}
+                <button
+                  onClick={() => {
+                    const key = `${problem.lecture_id}-${problem.problem_id}`;
+                    setHistoryOfProblem(historyOfProblem === key ? null : key);
+                  }}
+                  className="bg-gray-200 px-3 py-1 rounded hover:bg-gray-300 transition-colors flex items-center gap-1 ml-auto mb-1"
+                >
+                  <History className="w-4 h-4" />
+                  History
+                </button>
+                <button
+                  onClick={() => {
+                    const key = `${problem.lecture_id}-${problem.problem_id}`;
+                    setDeadlineOfProblem(deadlineOfProblem === key ? null : key);
+                  }}
+                  className="bg-gray-200 px-3 py-1 rounded hover:bg-gray-300 transition-colors flex items-center gap-1 ml-auto mb-1"
+                >
+                  <Clock className="w-4 h-4" />
+                  Deadline
+                </button>
                 <button
                   onClick={() => handleDeleteProblem(problem.lecture_id, problem.problem_id)}
                   className="bg-red-500 text-white px-3 py-1 rounded hover:bg-red-600 transition-colors flex items-center gap-1 ml-auto"
@@ -345,6 +516,36 @@ const ProblemRegistration: React.FC = () => {
                 </button>
               </td>
             </tr>
+            {historyOfProblem === `${problem.lecture_id}-${problem.problem_id}` && (
+              <tr>
+                <td colSpan={3} className="px-4 py-2 bg-gray-50">
+                  <ProblemVersionHistory
+                    lectureId={problem.lecture_id}
+                    problemId={problem.problem_id}
+                    onRollback={() => setLastFetchTime(Date.now())}
+                  />
+                </td>
+              </tr>
+            )}
+            {deadlineOfProblem === `${problem.lecture_id}-${problem.problem_id}` && (
+              <tr>
+                <td colSpan={3} className="px-4 py-2 bg-gray-50">
+                  <ProblemDeadlineSettings
+                    lectureId={problem.lecture_id}
+                    problemId={problem.problem_id}
+                    lectureDeadline={lecture.deadline}
+                    lectureLatePolicy={lecture.late_policy}
+                    problemDeadline={problem.problem_deadline}
+                    problemLatePolicy={problem.problem_late_policy}
+                    onSaved={() => {
+                      setDeadlineOfProblem(null);
+                      setLastFetchTime(Date.now());
+                    }}
+                  />
+                </td>
+              </tr>
+            )}
+            </React.Fragment>
           ))}
           {addingProblemToLecture === lectureId ? (
             <tr>
@@ -362,7 +563,10 @@ const ProblemRegistration: React.FC = () => {
                   <input
                     type="file"
                     accept=".zip"
-                    onChange={(e) => setNewProblemData(prev => ({ ...prev, file: e.target.files?.[0] || null }))}
+                    onChange={(e) => {
+                      setNewProblemData(prev => ({ ...prev, file: e.target.files?.[0] || null }));
+                      setLintResult(null);
+                    }}
                     className="hidden"
                   />
                   <span className="bg-gray-200 px-3 py-1 rounded hover:bg-gray-300 transition-colors flex items-center gap-2">
@@ -370,9 +574,30 @@ const ProblemRegistration: React.FC = () => {
                     {newProblemData.file ? newProblemData.file.name : "Choose ZIP file"}
                   </span>
                 </label>
+                {lintResult && (
+                  lintResult.valid ? (
+                    <div className="mt-2 text-xs text-green-700">問題は見つかりませんでした ({lintResult.title})</div>
+                  ) : (
+                    <ul className="mt-2 text-xs text-red-700 list-disc pl-4 space-y-0.5">
+                      {lintResult.issues.map((issue, i) => (
+                        <li key={i}>
+                          {issue.path && <span className="font-mono mr-1">{issue.path}:</span>}
+                          {issue.message}
+                        </li>
+                      ))}
+                    </ul>
+                  )
+                )}
               </td>
               <td className="px-4 py-2 text-sm text-right">
                 <div className="flex justify-end gap-2">
+                  <button
+                    onClick={() => handleLintProblemPackage(newProblemData.file)}
+                    className="bg-gray-200 px-3 py-1 rounded hover:bg-gray-300 transition-colors flex items-center gap-1"
+                  >
+                    <ListChecks className="w-4 h-4" />
+                    Check
+                  </button>
                   <button
                     onClick={() => handleAddProblem(lectureId)}
                     className="bg-green-500 text-white px-3 py-1 rounded hover:bg-green-600 transition-colors flex items-center gap-1"
@@ -384,6 +609,7 @@ const ProblemRegistration: React.FC = () => {
                     onClick={() => {
                       setAddingProblemToLecture(null);
                       setNewProblemData({ problemId: '', file: null });
+                      setLintResult(null);
                     }}
                     className="bg-gray-500 text-white px-3 py-1 rounded hover:bg-gray-600 transition-colors flex items-center gap-1"
                   >
@@ -486,15 +712,27 @@ const ProblemRegistration: React.FC = () => {
                   </td>
                   <td className="px-6 py-4 whitespace-nowrap text-sm">
                     {editingLecture === lecture.lecture_id ? (
-                      <input
-                        type="datetime-local"
-                        value={formatTimestamp(editFormData?.deadline || 0)}
-                        onChange={(e) => setEditFormData(prev => prev ? { ...prev, deadline: Math.floor(Date.parse(e.target.value) / 1000) } : null)}
-                        className="border border-gray-300 rounded px-2 py-1"
-                        onClick={(e) => e.stopPropagation()}
-                      />
+                      <>
+                        <input
+                          type="datetime-local"
+                          value={formatTimestamp(editFormData?.deadline || 0)}
+                          onChange={(e) => setEditFormData(prev => prev ? { ...prev, deadline: Math.floor(Date.parse(e.target.value) / 1000) } : null)}
+                          className="border border-gray-300 rounded px-2 py-1"
+                          onClick={(e) => e.stopPropagation()}
+                        />
+                        <div className="mt-1">
+                          <LatePolicyEditor
+                            value={editFormData?.late_policy ?? null}
+                            onChange={(policy) => setEditFormData(prev => prev ? { ...prev, late_policy: policy } : null)}
+                            noneLabel="減点なし"
+                          />
+                        </div>
+                      </>
                     ) : (
-                      formatTimestamp(lecture.deadline)
+                      <>
+                        {formatTimestamp(lecture.deadline)}
+                        <div className="text-xs text-gray-500">{describeLatePolicy(lecture.late_policy)}</div>
+                      </>
                     )}
                   </td>
                   <td className="px-6 py-4 whitespace-nowrap text-sm text-right actions-cell">
@@ -534,6 +772,7 @@ const ProblemRegistration: React.FC = () => {
                                 title: lecture.title,
                                 start_date: lecture.start_date,
                                 deadline: lecture.deadline,
+                                late_policy: lecture.late_policy,
                               });
                             }}
                             className="bg-blue-500 text-white px-3 py-1 rounded hover:bg-blue-600 transition-colors flex items-center gap-1"
@@ -541,6 +780,24 @@ const ProblemRegistration: React.FC = () => {
                             <Edit className="w-4 h-4" />
                             Edit
                           </button>
+                          <button
+                            onClick={(e) => {
+                              e.stopPropagation();
+                              setIsAddingLecture(true);
+                              setCloneSourceId(lecture.lecture_id);
+                              setNewLectureData({
+                                id: '',
+                                title: lecture.title,
+                                start_date: lecture.start_date,
+                                deadline: lecture.deadline,
+                                late_policy: lecture.late_policy,
+                              });
+                            }}
+                            className="bg-gray-200 px-3 py-1 rounded hover:bg-gray-300 transition-colors flex items-center gap-1"
+                          >
+                            <Copy className="w-4 h-4" />
+                            Clone
+                          </button>
                           <button
                             onClick={(e) => {
                               e.stopPropagation();
@@ -560,7 +817,7 @@ const ProblemRegistration: React.FC = () => {
                   <tr>
                     <td colSpan={5} className="px-4 py-2 bg-gray-50">
                       <div className="ml-8">
-                        {renderProblemRows(lecture.lecture_id, lecture.problems)}
+                        {renderProblemRows(lecture)}
                       </div>
                     </td>
                   </tr>
@@ -586,6 +843,9 @@ const ProblemRegistration: React.FC = () => {
                     onChange={(e) => setNewLectureData(prev => ({ ...prev, title: e.target.value }))}
                     className="border border-gray-300 rounded px-2 py-1 w-full"
                   />
+                  {cloneSourceId !== null && (
+                    <div className="mt-1 text-xs text-gray-500">講義 {cloneSourceId} の課題をコピーします (提出は含まれません)</div>
+                  )}
                 </td>
                 <td className="px-6 py-4 whitespace-nowrap text-sm">
                   <input
@@ -602,6 +862,16 @@ const ProblemRegistration: React.FC = () => {
                     onChange={(e) => setNewLectureData(prev => ({ ...prev, deadline: Math.floor(Date.parse(e.target.value) / 1000) }))}
                     className="border border-gray-300 rounded px-2 py-1"
                   />
+                  {/* A clone takes over the late policy of the source lecture */}
+                  {cloneSourceId === null && (
+                    <div className="mt-1">
+                      <LatePolicyEditor
+                        value={newLectureData.late_policy}
+                        onChange={(policy) => setNewLectureData(prev => ({ ...prev, late_policy: policy }))}
+                        noneLabel="減点なし"
+                      />
+                    </div>
+                  )}
                 </td>
                 <td className="px-6 py-4 whitespace-nowrap text-sm text-right">
                   <div className="flex justify-end gap-2">
@@ -615,7 +885,8 @@ const ProblemRegistration: React.FC = () => {
                     <button
                       onClick={() => {
                         setIsAddingLecture(false);
-                        setNewLectureData({ id: '', title: '', start_date: Math.floor(Date.now() / 1000), deadline: Math.floor(Date.now() / 1000) + 7 * 24 * 60 * 60 });
+                        setCloneSourceId(null);
+                        setNewLectureData({ id: '', title: '', start_date: Math.floor(Date.now() / 1000), deadline: Math.floor(Date.now() / 1000) + 7 * 24 * 60 * 60, late_policy: null });
                       }}
                       className="bg-gray-500 text-white px-3 py-1 rounded hover:bg-gray-600 transition-colors flex items-center gap-1"
                     >
@@ -0,0 +1,263 @@
+import React, { useState } from "react";
+import { addAuthorizationHeader, useAuthQuery } from "../../auth/hooks";
+import { axiosClient, type SuccessResponse } from "../../api/axiosClient";
+import { decompressString, type CompressedFileData } from "../../types/FileData";
+import { formatTimestamp } from "../../util/timestamp";
+import { Check, Download, GitCompare, RotateCcw } from "lucide-react";
+import { saveAs } from "file-saver";
+
+interface ProblemVersion {
+  version: number;
+  registered_at: number;
+  title: string;
+  status: string;
+  current: boolean;
+}
+
+interface FileDiff {
+  path: string;
+  status: "added" | "removed" | "modified";
+  old: CompressedFileData | null;
+  new: CompressedFileData | null;
+}
+
+interface VersionDiff {
+  from: number;
+  to: number;
+  detail_changed: boolean;
+  files: FileDiff[];
+}
+
+interface DecompressedFileDiff {
+  path: string;
+  status: FileDiff["status"];
+  old: string | null;
+  new: string | null;
+}
+
+const diffStatusLabel: { [key in FileDiff["status"]]: { text: string; className: string } } = {
+  added: { text: "追加", className: "bg-green-100 text-green-800" },
+  removed: { text: "削除", className: "bg-red-100 text-red-800" },
+  modified: { text: "変更", className: "bg-yellow-100 text-yellow-800" },
+};
+
+interface ProblemVersionHistoryProps {
+  lectureId: number;
+  problemId: number;
+  onRollback: () => void;
+}
+
+// Lists the versions of a problem, and compares, rolls back to or publishes one of them
+const ProblemVersionHistory: React.FC<ProblemVersionHistoryProps> = ({ lectureId, problemId, onRollback }) => {
+  const [lastFetchTime, setLastFetchTime] = useState<number>(Date.now());
+  const [diff, setDiff] = useState<{ from: number; to: number; detailChanged: boolean; files: DecompressedFileDiff[] } | null>(null);
+  const [exportFormat, setExportFormat] = useState<'json' | 'yaml'>('json');
+
+  const versionsQuery = useAuthQuery<ProblemVersion[]>({
+    queryKey: ['problemVersions', lectureId.toString(), problemId.toString(), lastFetchTime.toString()],
+    endpoint: `/problem/crud/versions/${lectureId}/${problemId}`,
+  });
+
+  const handleDiff = async (from: number, to: number) => {
+    try {
+      const config = addAuthorizationHeader({ params: { from, to } });
+      const result = await axiosClient.get<VersionDiff>(
+        `/problem/crud/diff/${lectureId}/${problemId}`,
+        config,
+      );
+
+      const files = await Promise.all(result.data.files.map(async (file) => ({
+        path: file.path,
+        status: file.status,
+        old: file.old ? await decompressString(file.old.data) : null,
+        new: file.new ? await decompressString(file.new.data) : null,
+      })));
+      setDiff({ from, to, detailChanged: result.data.detail_changed, files });
+    } catch (error) {
+      console.error("Error comparing problem versions:", error);
+      alert("Failed to compare problem versions. Please try again.");
+    }
+  }
+
+  const handleExport = async (version: number) => {
+    try {
+      const config = addAuthorizationHeader({ params: { version, format: exportFormat }, responseType: 'blob' });
+      const result = await axiosClient.get<Blob>(
+        `/problem/crud/export/${lectureId}/${problemId}`,
+        config,
+      );
+      saveAs(result.data, `problem-${lectureId}-${problemId}-v${version}.zip`);
+    } catch (error) {
+      console.error("Error exporting problem:", error);
+      alert("Failed to export problem. Please try again.");
+    }
+  }
+
+  const handleRollback = async (version: number) => {
+    if (!confirm(`Roll back to version ${version}? It is registered as a new version, and new submissions are judged with it once its reference solution is verified.`)) {
+      return;
+    }
+
+    try {
+      const config = addAuthorizationHeader({});
+      const result = await axiosClient.post<SuccessResponse>(
+        `/problem/crud/rollback/${lectureId}/${problemId}/${version}`,
+        {},
+        config,
+      );
+
+      if (result.data.message) {
+        console.log("Problem rolled back successfully:", result.data.message);
+
+        setDiff(null);
+        setLastFetchTime(Date.now());
+        onRollback();
+      }
+    } catch (error) {
+      console.error("Error rolling back problem:", error);
+      alert("Failed to roll back problem. Please try again.");
+    }
+  }
+
+  // New versions of a visible problem become current once their generated expected outputs are reviewed
+  const handlePublish = async () => {
+    if (!confirm("Have you reviewed the generated expected outputs? New submissions are judged with this version.")) {
+      return;
+    }
+
+    try {
+      const config = addAuthorizationHeader({});
+      const result = await axiosClient.post<SuccessResponse>(
+        `/problem/crud/publish/${lectureId}/${problemId}`,
+        {},
+        config,
+      );
+
+      if (result.data.message) {
+        console.log("Problem published successfully:", result.data.message);
+
+        setLastFetchTime(Date.now());
+        onRollback();
+      }
+    } catch (error) {
+      console.error("Error publishing problem:", error);
+      alert("Failed to publish problem. Please try again.");
+    }
+  }
+
+  if (versionsQuery.isPending) {
+    return <div className="text-gray-500 text-sm">Loading...</div>;
+  }
+
+  if (versionsQuery.error) {
+    return <div className="text-red-500 text-sm">Error loading versions: {versionsQuery.error.message}</div>;
+  }
+
+  const versions = versionsQuery.data ?? [];
+  const current = versions.find(v => v.current);
+
+  return (
+    <div className="space-y-2">
+      <div className="flex justify-end items-center gap-2 text-sm">
+        <label htmlFor="export-format" className="text-gray-500">エクスポートする設定ファイル</label>
+        <select
+          id="export-format"
+          value={exportFormat}
+          onChange={(e) => setExportFormat(e.target.value as 'json' | 'yaml')}
+          className="border border-gray-300 rounded px-2 py-0.5"
+        >
+          <option value="json">init.json</option>
+          <option value="yaml">init.yaml</option>
+        </select>
+      </div>
+      <table className="w-full text-sm">
+        <thead className="border-b border-gray-200">
+          <tr>
+            <th className="px-2 py-1 text-left text-xs font-medium text-gray-500">バージョン</th>
+            <th className="px-2 py-1 text-left text-xs font-medium text-gray-500">登録日時</th>
+            <th className="px-2 py-1 text-left text-xs font-medium text-gray-500">タイトル</th>
+            <th className="px-2 py-1 text-left text-xs font-medium text-gray-500">状態</th>
+            <th className="px-2 py-1"></th>
+          </tr>
+        </thead>
+        <tbody className="divide-y divide-gray-100">
+          {versions.map((version) => (
+            <tr key={version.version}>
+              <td className="px-2 py-1">
+                v{version.version}
+                {version.current && <span className="ml-2 px-2 py-0.5 rounded text-xs bg-blue-100 text-blue-800">現在</span>}
+              </td>
+              <td className="px-2 py-1">{formatTimestamp(version.registered_at)}</td>
+              <td className="px-2 py-1">{version.title}</td>
+              <td className="px-2 py-1">{version.status}</td>
+              <td className="px-2 py-1">
+                <div className="flex justify-end gap-2">
+                  <button
+                    onClick={() => handleExport(version.version)}
+                    className="bg-gray-200 px-2 py-0.5 rounded hover:bg-gray-300 transition-colors flex items-center gap-1"
+                  >
+                    <Download className="w-4 h-4" />
+                    Export
+                  </button>
+                  {version === versions[0] && !version.current && version.status === "review" && (
+                    <button
+                      onClick={handlePublish}
+                      className="bg-blue-500 text-white px-2 py-0.5 rounded hover:bg-blue-600 transition-colors flex items-center gap-1"
+                    >
+                      <Check className="w-4 h-4" />
+                      Publish
+                    </button>
+                  )}
+                  {current && !version.current && (
+                    <>
+                      <button
+                        onClick={() => handleDiff(version.version, current.version)}
+                        className="bg-gray-200 px-2 py-0.5 rounded hover:bg-gray-300 transition-colors flex items-center gap-1"
+                      >
+                        <GitCompare className="w-4 h-4" />
+                        Diff
+                      </button>
+                      <button
+                        onClick={() => handleRollback(version.version)}
+                        className="bg-orange-500 text-white px-2 py-0.5 rounded hover:bg-orange-600 transition-colors flex items-center gap-1"
+                      >
+                        <RotateCcw className="w-4 h-4" />
+                        Rollback
+                      </button>
+                    </>
+                  )}
+                </div>
+              </td>
+            </tr>
+          ))}
+        </tbody>
+      </table>
+
+      {diff && (
+        <div className="border border-gray-200 rounded p-2 space-y-2">
+          <div className="text-sm font-medium">
+            v{diff.from} → v{diff.to}
+            {diff.detailChanged && <span className="ml-2 text-xs text-yellow-800">init.json の設定が変更されています</span>}
+          </div>
+          {diff.files.length === 0 && <div className="text-sm text-gray-500">ファイルの差分はありません</div>}
+          {diff.files.map((file) => (
+            <div key={file.path}>
+              <div className="text-sm">
+                <span className={`mr-2 px-2 py-0.5 rounded text-xs ${diffStatusLabel[file.status].className}`}>
+                  {diffStatusLabel[file.status].text}
+                </span>
+                {file.path}
+              </div>
+              <div className="grid grid-cols-2 gap-2 mt-1">
+                <pre className="bg-gray-50 p-2 text-xs overflow-auto max-h-64 whitespace-pre-wrap">{file.old ?? ""}</pre>
+                <pre className="bg-gray-50 p-2 text-xs overflow-auto max-h-64 whitespace-pre-wrap">{file.new ?? ""}</pre>
+              </div>
+            </div>
+          ))}
+        </div>
+      )}
+    </div>
+  );
+}
+
+export default ProblemVersionHistory;
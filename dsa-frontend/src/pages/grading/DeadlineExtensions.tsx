@@ -0,0 +1,282 @@
+import { useEffect, useState } from "react";
+import { useSearchParams } from "react-router";
+import { Plus, Trash2 } from "lucide-react";
+import { addAuthorizationHeader, useAuthQuery } from "../../auth/hooks";
+import { axiosClient, type SuccessResponse } from "../../api/axiosClient";
+import { formatTimestamp } from "../../util/timestamp";
+
+interface ProblemInfo {
+  lecture_id: number;
+  problem_id: number;
+  title: string;
+  deadline: number; // deadline of the problem, or of the lecture if not set
+}
+
+interface LectureInfo {
+  lecture_id: number;
+  title: string;
+  deadline: number;
+  problems: ProblemInfo[];
+}
+
+interface DeadlineExtension {
+  id: number;
+  user_id: string;
+  user_name: string;
+  lecture_id: number;
+  problem_id: number | null; // null for all problems of the lecture
+  deadline: number;
+  reason: string;
+  granted_user_id: string;
+  granted_at: number;
+}
+
+// url: /grading/extensions?lectureid=xxx
+const DeadlineExtensions: React.FC = () => {
+  const [searchParams, setSearchParams] = useSearchParams();
+  const [lectureId, setLectureId] = useState<number | null>(null);
+  const [extensions, setExtensions] = useState<DeadlineExtension[]>([]);
+  const [newExtension, setNewExtension] = useState<{ user_id: string; problem_id: number | null; deadline: number; reason: string }>({
+    user_id: '',
+    problem_id: null,
+    deadline: Math.floor(Date.now() / 1000) + 7 * 24 * 60 * 60,
+    reason: '',
+  });
+
+  const { data: lectureList, isLoading: lectureListLoading, isError: lectureListError } = useAuthQuery<LectureInfo[]>({
+    queryKey: ['lectureList'],
+    endpoint: '/problem/fetch/list',
+    options: {
+      queryOptions: {
+        retry: 2,
+      }
+    }
+  });
+
+  const fetchExtensions = async (lectureId: number) => {
+    try {
+      const config = addAuthorizationHeader({});
+      const response = await axiosClient.get<DeadlineExtension[]>(`/problem/crud/extension/${lectureId}`, config);
+      setExtensions(response.data);
+    } catch (error) {
+      console.error("Error fetching deadline extensions:", error);
+      setExtensions([]);
+    }
+  };
+
+  // Initialize lectureId from URL params on mount
+  useEffect(() => {
+    const id = parseInt(searchParams.get("lectureid") ?? '');
+    if (!isNaN(id)) {
+      setLectureId(id);
+      fetchExtensions(id);
+    }
+  }, []);
+
+  // Update URL when lectureId changes
+  useEffect(() => {
+    if (lectureId !== null) {
+      setSearchParams({ lectureid: lectureId.toString() });
+    }
+  }, [lectureId]);
+
+  const handleLectureChange = (e: React.ChangeEvent<HTMLSelectElement>) => {
+    const selectedId = parseInt(e.target.value);
+    if (!isNaN(selectedId)) {
+      setLectureId(selectedId);
+      setNewExtension(prev => ({ ...prev, problem_id: null }));
+      fetchExtensions(selectedId);
+    }
+  };
+
+  const handleGrant = async () => {
+    if (lectureId === null) return;
+    if (newExtension.user_id.trim() === '') {
+      alert("Please enter the user ID.");
+      return;
+    }
+
+    try {
+      const config = addAuthorizationHeader({});
+      await axiosClient.put<DeadlineExtension>(
+        `/problem/crud/extension/${lectureId}`,
+        { ...newExtension, user_id: newExtension.user_id.trim() },
+        config,
+      );
+      setNewExtension(prev => ({ ...prev, user_id: '', reason: '' }));
+      await fetchExtensions(lectureId);
+    } catch (error) {
+      console.error("Error granting deadline extension:", error);
+      alert(`Failed to grant deadline extension.\n${error instanceof Error ? error.message : ""}`);
+    }
+  };
+
+  const handleRevoke = async (extension: DeadlineExtension) => {
+    if (lectureId === null) return;
+    if (!confirm(`Revoke the deadline extension of ${extension.user_id}?`)) {
+      return;
+    }
+
+    try {
+      const config = addAuthorizationHeader({});
+      await axiosClient.delete<SuccessResponse>(`/problem/crud/extension/${lectureId}/${extension.id}`, config);
+      await fetchExtensions(lectureId);
+    } catch (error) {
+      console.error("Error revoking deadline extension:", error);
+      alert(`Failed to revoke deadline extension.\n${error instanceof Error ? error.message : ""}`);
+    }
+  };
+
+  if (lectureListLoading) {
+    return (
+      <div className="min-h-screen flex items-center justify-center">
+        <div className="text-gray-500">Loading lectures...</div>
+      </div>
+    );
+  }
+
+  if (lectureListError || !lectureList) {
+    return (
+      <div className="min-h-screen flex items-center justify-center">
+        <div className="text-red-500">Error loading lecture list.</div>
+      </div>
+    );
+  }
+
+  const currentLecture = lectureList.find(lecture => lecture.lecture_id === lectureId);
+
+  // Usual deadline of the target of an extension
+  const usualDeadline = (problemId: number | null): number | undefined => {
+    if (problemId === null) return currentLecture?.deadline;
+    return currentLecture?.problems.find(problem => problem.problem_id === problemId)?.deadline;
+  };
+
+  const problemTitle = (problemId: number | null): string => {
+    if (problemId === null) return "全ての課題";
+    return currentLecture?.problems.find(problem => problem.problem_id === problemId)?.title ?? `課題 ${problemId}`;
+  };
+
+  return (
+    <div className="container mx-auto px-8 py-6">
+      <h1 className="text-3xl font-semibold mb-6">Deadline Extensions</h1>
+
+      {/* Dropdown Selection */}
+      <div className="mb-6">
+        <select
+          value={lectureId || ''}
+          onChange={handleLectureChange}
+          className="px-4 py-2 border border-gray-300 rounded-md shadow-sm focus:outline-none focus:ring-2 focus:ring-blue-500 focus:border-blue-500 bg-white"
+        >
+          <option value="" disabled>Select a lecture</option>
+          {lectureList.map(lecture => (
+            <option key={lecture.lecture_id} value={lecture.lecture_id}>
+              {lecture.lecture_id}: {lecture.title}
+            </option>
+          ))}
+        </select>
+      </div>
+
+      {currentLecture && (
+        <>
+          {/* Grant */}
+          <div className="mb-6 flex flex-wrap items-center gap-3 text-sm">
+            <span className="font-medium">締切の延長:</span>
+            <input
+              type="text"
+              placeholder="学籍番号"
+              value={newExtension.user_id}
+              onChange={(e) => setNewExtension(prev => ({ ...prev, user_id: e.target.value }))}
+              className="px-2 py-1 border border-gray-300 rounded-md w-32"
+            />
+            <select
+              value={newExtension.problem_id ?? ''}
+              onChange={(e) => setNewExtension(prev => ({ ...prev, problem_id: e.target.value === '' ? null : parseInt(e.target.value) }))}
+              className="px-2 py-1 border border-gray-300 rounded-md bg-white"
+            >
+              <option value="">全ての課題</option>
+              {currentLecture.problems.map(problem => (
+                <option key={problem.problem_id} value={problem.problem_id}>{problem.title}</option>
+              ))}
+            </select>
+            <input
+              type="datetime-local"
+              value={formatTimestamp(newExtension.deadline)}
+              onChange={(e) => setNewExtension(prev => ({ ...prev, deadline: Math.floor(Date.parse(e.target.value) / 1000) }))}
+              className="px-2 py-1 border border-gray-300 rounded-md"
+            />
+            <input
+              type="text"
+              placeholder="理由 (例: 診断書あり)"
+              value={newExtension.reason}
+              onChange={(e) => setNewExtension(prev => ({ ...prev, reason: e.target.value }))}
+              className="px-2 py-1 border border-gray-300 rounded-md w-64"
+            />
+            <button
+              onClick={handleGrant}
+              className="bg-green-500 text-white px-3 py-1 rounded hover:bg-green-600 transition-colors flex items-center gap-1"
+            >
+              <Plus className="w-4 h-4" />
+              延長
+            </button>
+          </div>
+          <p className="mb-4 text-sm text-gray-500">
+            同じ学生・課題の延長は上書きされます。延長後の締切が通常の締切より前の場合、通常の締切が適用されます。
+          </p>
+
+          {/* Extensions Table */}
+          <div className="bg-white rounded-lg shadow overflow-x-auto">
+            <table className="min-w-full divide-y divide-gray-200">
+              <thead className="bg-gray-50">
+                <tr>
+                  <th className="px-6 py-3 text-left text-xs font-semibold text-gray-500 uppercase tracking-wider">名前 (学籍番号)</th>
+                  <th className="px-6 py-3 text-left text-xs font-semibold text-gray-500 uppercase tracking-wider">課題</th>
+                  <th className="px-6 py-3 text-left text-xs font-semibold text-gray-500 uppercase tracking-wider">延長後の締切</th>
+                  <th className="px-6 py-3 text-left text-xs font-semibold text-gray-500 uppercase tracking-wider">理由</th>
+                  <th className="px-6 py-3 text-left text-xs font-semibold text-gray-500 uppercase tracking-wider">登録</th>
+                  <th className="px-6 py-3"></th>
+                </tr>
+              </thead>
+              <tbody className="bg-white divide-y divide-gray-200">
+                {extensions.length === 0 ? (
+                  <tr>
+                    <td colSpan={6} className="px-6 py-4 text-center text-sm text-gray-400">延長はありません</td>
+                  </tr>
+                ) : extensions.map(extension => {
+                  const usual = usualDeadline(extension.problem_id);
+
+                  return (
+                    <tr key={extension.id}>
+                      <td className="px-6 py-4 whitespace-nowrap text-sm text-gray-900">{extension.user_name} ({extension.user_id})</td>
+                      <td className="px-6 py-4 whitespace-nowrap text-sm text-gray-900">{problemTitle(extension.problem_id)}</td>
+                      <td className="px-6 py-4 whitespace-nowrap text-sm text-gray-900">
+                        {formatTimestamp(extension.deadline)}
+                        {usual !== undefined && (
+                          <div className="text-xs text-gray-500">通常: {formatTimestamp(usual)}</div>
+                        )}
+                      </td>
+                      <td className="px-6 py-4 text-sm text-gray-600">{extension.reason}</td>
+                      <td className="px-6 py-4 whitespace-nowrap text-xs text-gray-500">
+                        {extension.granted_user_id}<br />{formatTimestamp(extension.granted_at)}
+                      </td>
+                      <td className="px-6 py-4 text-right">
+                        <button
+                          onClick={() => handleRevoke(extension)}
+                          className="text-red-600 hover:text-red-800"
+                          title="Revoke"
+                        >
+                          <Trash2 className="w-4 h-4" />
+                        </button>
+                      </td>
+                    </tr>
+                  );
+                })}
+              </tbody>
+            </table>
+          </div>
+        </>
+      )}
+    </div>
+  );
+};
+
+export default DeadlineExtensions;
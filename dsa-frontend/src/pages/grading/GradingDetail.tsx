@@ -1,5 +1,5 @@
 import { Link, useParams, useSearchParams } from "react-router";
-import type { DetailedTaskLog } from "../../types/DetailedTaskLog";
+import type { DetailedTaskLog, ProcessLog } from "../../types/DetailedTaskLog";
 import { decompressFileData, decompressString, type CompressedFileData, type FileData } from "../../types/FileData";
 import { useEffect, useMemo, useRef, useState, type JSX } from "react";
 import { useAuthQuery } from "../../auth/hooks";
@@ -7,6 +7,10 @@ import ResultBadge from "../../components/ResultBadge";
 import { formatTimestamp } from "../../util/timestamp";
 import FileViewer from "../../components/FileViewer";
 import DetailedTaskLogTable from "../../components/DetailedTaskLogTable";
+import BenchmarkTable from "../../components/BenchmarkTable";
+import type { BenchmarkDetail } from "../../types/BenchmarkDetail";
+import MutationTable from "../../components/MutationTable";
+import type { MutationDetail, MutationRun } from "../../types/MutationDetail";
 
 interface CompressedFileGroup {
   id: number;
@@ -33,6 +37,8 @@ interface ProblemInfo {
   problem_id: number;
   registered_at: number;
   title: string;
+  deadline: number; // deadline of the problem, or of the lecture if not set, extended for the student
+  extended: boolean; // the deadline is extended for the student
 }
 
 interface LectureInfo {
@@ -53,9 +59,13 @@ interface GradingDetailPerProblem {
   result_id: number;
   file_group_id: number;
   time_ms: number;
+  cpu_time_ms: number;
   memory_kb: number;
+  cached: boolean;
   build_logs: DetailedTaskLog[];
   judge_logs: DetailedTaskLog[];
+  benchmark_logs: BenchmarkDetail[];
+  mutation_logs: MutationDetail[];
 }
 
 interface APIResponse {
@@ -68,13 +78,27 @@ interface APIResponse {
   detail_list: GradingDetailPerProblem[];
 }
 
+async function decompressProcessLog(log: ProcessLog): Promise<ProcessLog> {
+  const [stdout, stderr] = await Promise.all([
+    decompressString(log.stdout),
+    decompressString(log.stderr),
+  ]);
+
+  return {
+    ...log,
+    stdout: stdout || "",
+    stderr: stderr || "",
+  };
+}
+
 async function decompressTaskLog(log: DetailedTaskLog): Promise<DetailedTaskLog> {
-  const [stdin, stdout, stderr, expectedStdout, expectedStderr] = await Promise.all([
+  const [stdin, stdout, stderr, expectedStdout, expectedStderr, processes] = await Promise.all([
     decompressString(log.stdin),
     decompressString(log.stdout),
     decompressString(log.stderr),
     decompressString(log.expected_stdout),
     decompressString(log.expected_stderr),
+    Promise.all(log.processes.map(decompressProcessLog)),
   ]);
 
   return {
@@ -84,6 +108,7 @@ async function decompressTaskLog(log: DetailedTaskLog): Promise<DetailedTaskLog>
     stderr: stderr || "",
     expected_stdout: expectedStdout === null ? null : expectedStdout,
     expected_stderr: expectedStderr === null ? null : expectedStderr,
+    processes,
   };
 }
 
@@ -103,16 +128,44 @@ async function decompressTestFilesPerProblem(entry: CompressedTestFilesPerProble
   };
 }
 
+async function decompressMutationRun(run: MutationRun): Promise<MutationRun> {
+  const [stdout, stderr] = await Promise.all([
+    decompressString(run.stdout),
+    decompressString(run.stderr),
+  ]);
+
+  return {
+    ...run,
+    stdout: stdout || "",
+    stderr: stderr || "",
+  };
+}
+
+async function decompressMutationDetail(log: MutationDetail): Promise<MutationDetail> {
+  const [reference, mutants] = await Promise.all([
+    decompressMutationRun(log.reference),
+    Promise.all(log.mutants.map(decompressMutationRun)),
+  ]);
+
+  return {
+    ...log,
+    reference,
+    mutants,
+  };
+}
+
 async function decompressGradingDetailPerProblem(detail: GradingDetailPerProblem): Promise<GradingDetailPerProblem> {
-  const [buildLogs, judgeLogs] = await Promise.all([
+  const [buildLogs, judgeLogs, mutationLogs] = await Promise.all([
     Promise.all(detail.build_logs.map(decompressTaskLog)),
     Promise.all(detail.judge_logs.map(decompressTaskLog)),
+    Promise.all(detail.mutation_logs.map(decompressMutationDetail)),
   ]);
 
   return {
     ...detail,
     build_logs: buildLogs,
     judge_logs: judgeLogs,
+    mutation_logs: mutationLogs,
   };
 }
 
@@ -159,12 +212,19 @@ const renderDetail = (detail: GradingDetailPerProblem, fileGroup: FileGroup, tes
               <td className="px-4 py-2 font-semibold bg-gray-100">結果</td>
               <td className="px-4 py-2">
                 <ResultBadge resultID={detail.result_id} />
+                {detail.cached && (
+                  <span className="ml-2 text-xs text-gray-700 bg-gray-50 border border-gray-300 rounded px-1" title="同一内容の過去の採点結果を再利用しています">cached</span>
+                )}
               </td>
             </tr>
             <tr className="border-b">
               <td className="px-4 py-2 font-semibold bg-gray-100">実行時間</td>
               <td className="px-4 py-2">{detail.time_ms} ms</td>
             </tr>
+            <tr className="border-b">
+              <td className="px-4 py-2 font-semibold bg-gray-100">CPU時間</td>
+              <td className="px-4 py-2">{detail.cpu_time_ms} ms</td>
+            </tr>
             <tr className="border-b">
               <td className="px-4 py-2 font-semibold bg-gray-100">メモリ</td>
               <td className="px-4 py-2">{detail.memory_kb} KiB</td>
@@ -201,6 +261,26 @@ const renderDetail = (detail: GradingDetailPerProblem, fileGroup: FileGroup, tes
         </div>
       )}
 
+      {/* Benchmark Tasks */}
+      {detail.benchmark_logs.length > 0 && (
+        <div className="mb-8 bg-white rounded-lg shadow">
+          <h2 className="text-xl font-semibold p-4 border-b">Benchmark Tasks</h2>
+          <div className="p-4">
+            <BenchmarkTable logs={detail.benchmark_logs} />
+          </div>
+        </div>
+      )}
+
+      {/* Mutation Tasks */}
+      {detail.mutation_logs.length > 0 && (
+        <div className="mb-8 bg-white rounded-lg shadow">
+          <h2 className="text-xl font-semibold p-4 border-b">Mutation Testing</h2>
+          <div className="p-4">
+            <MutationTable logs={detail.mutation_logs} />
+          </div>
+        </div>
+      )}
+
       {/* Test Files */}
       {testFiles.files.length > 0 && (
         <div className="mb-8 bg-white rounded-lg shadow">
@@ -367,6 +447,9 @@ const GradingDetail: React.FC = () => {
 
                 return <th key={problem.problem_id} className={`px-6 py-3 text-center text-xs font-medium text-gray-500 uppercase tracking-wider ${isLast ? '' : 'border-r'}`}>
                   {problem.title}
+                  {problem.extended && (
+                    <div className="normal-case text-blue-600" title={`締切: ${formatTimestamp(problem.deadline)}`}>締切延長あり</div>
+                  )}
                 </th>;
               })}
             </tr>
@@ -389,7 +472,7 @@ const GradingDetail: React.FC = () => {
                     ) : (
                       <div className="space-y-2">
                         {results.map(result => {
-                          const isDelay = result.submission_ts > currentLecture.deadline;
+                          const isDelay = result.submission_ts > problem.deadline;
 
                           return <div
                             key={result.id}
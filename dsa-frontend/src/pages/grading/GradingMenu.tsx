@@ -16,6 +16,9 @@ const GradingMenu: React.FC = () => {
         <li className="text-blue-600 text-xl hover:underline">
           <Link to="/grading/results" className="ml-4">Grading Results (採点結果一覧)</Link>
         </li>
+        <li className="text-blue-600 text-xl hover:underline">
+          <Link to="/grading/extensions" className="ml-4">Deadline Extensions (締切の個別延長)</Link>
+        </li>
       </ul>
     </div>
   )
@@ -27,6 +27,10 @@ interface GradingResult {
   submission_ts: number;
   time_ms: number;
   memory_kb: number;
+  deadline: number; // deadline of the problem, extended for the student
+  extended: boolean; // the deadline is extended for the student
+  late_seconds: number; // 0 if submitted in time
+  penalty: number; // fraction of the score deducted by the late policy
 }
 
 interface UserInfo {
@@ -40,6 +44,14 @@ interface APIResponse {
   detail: UserInfo[];
 }
 
+interface RejudgeOutput {
+  id: number;
+  total: number;
+}
+
+// Verdicts which can be selected to limit a rejudge
+const rejudgeVerdicts = ["WA", "RE", "TLE", "MLE", "OLE", "CE", "IE", "FN"];
+
 // url: /grading/results?lectureid=xxx
 const GradingResultsListing: React.FC = () => {
   const [searchParams, setSearchParams] = useSearchParams();
@@ -56,6 +68,8 @@ const GradingResultsListing: React.FC = () => {
     }
   });
   const [apiResponse, setApiResponse] = useState<APIResponse | null>(null);
+  const [rejudgeProblemId, setRejudgeProblemId] = useState<number | null>(null); // null for all problems
+  const [rejudgeVerdictFilter, setRejudgeVerdictFilter] = useState<Set<string>>(new Set());
 
   const fetchGradingResults = async (lectureId: number) => {
     try {
@@ -144,6 +158,44 @@ const GradingResultsListing: React.FC = () => {
     }
   }, [lectureId]);
 
+  const handleRejudge = async () => {
+    if (lectureId === null) return;
+
+    const target = rejudgeProblemId === null ? "all problems" : `problem ${rejudgeProblemId}`;
+    const verdicts = rejudgeVerdictFilter.size === 0 ? "all verdicts" : Array.from(rejudgeVerdictFilter).join(", ");
+    if (!confirm(`Rejudge grading requests of ${target} (${verdicts})? The current results are kept in the history.`)) {
+      return;
+    }
+
+    try {
+      const formData = new FormData();
+      formData.append("results", Array.from(rejudgeVerdictFilter).join(","));
+
+      const config = addAuthorizationHeader({});
+      const endpoint = rejudgeProblemId === null
+        ? `/problem/judge/rejudge/lecture/${lectureId}`
+        : `/problem/judge/rejudge/problem/${lectureId}/${rejudgeProblemId}`;
+      const result = await axiosClient.post<RejudgeOutput>(endpoint, formData, config);
+
+      console.log(`Rejudge ${result.data.id} registered: ${result.data.total} requests`);
+      // Results being judged are polled
+      await fetchGradingResults(lectureId);
+    } catch (error) {
+      console.error("Error requesting rejudge:", error);
+      alert("Failed to rejudge. There may be no grading requests to rejudge.");
+    }
+  };
+
+  const toggleRejudgeVerdict = (verdict: string) => {
+    const newFilter = new Set(rejudgeVerdictFilter);
+    if (newFilter.has(verdict)) {
+      newFilter.delete(verdict);
+    } else {
+      newFilter.add(verdict);
+    }
+    setRejudgeVerdictFilter(newFilter);
+  };
+
   const handleLectureChange = (e: React.ChangeEvent<HTMLSelectElement>) => {
     const selectedId = parseInt(e.target.value);
     if (!isNaN(selectedId)) {
@@ -206,6 +258,40 @@ const GradingResultsListing: React.FC = () => {
         </select>
       </div>
 
+      {/* Rejudge */}
+      {currentLecture && (
+        <div className="mb-6 flex flex-wrap items-center gap-3 text-sm">
+          <span className="font-medium">再ジャッジ:</span>
+          <select
+            value={rejudgeProblemId ?? ''}
+            onChange={(e) => setRejudgeProblemId(e.target.value === '' ? null : parseInt(e.target.value))}
+            className="px-2 py-1 border border-gray-300 rounded-md bg-white"
+          >
+            <option value="">全ての課題</option>
+            {currentLecture.problems.map(problem => (
+              <option key={problem.problem_id} value={problem.problem_id}>{problem.title}</option>
+            ))}
+          </select>
+          {rejudgeVerdicts.map(verdict => (
+            <label key={verdict} className="flex items-center gap-1">
+              <input
+                type="checkbox"
+                checked={rejudgeVerdictFilter.has(verdict)}
+                onChange={() => toggleRejudgeVerdict(verdict)}
+              />
+              {verdict}
+            </label>
+          ))}
+          <span className="text-gray-500">(未選択の場合は全ての結果)</span>
+          <button
+            onClick={handleRejudge}
+            className="bg-orange-500 text-white px-3 py-1 rounded hover:bg-orange-600 transition-colors"
+          >
+            再ジャッジ
+          </button>
+        </div>
+      )}
+
       {/* Results Table */}
       {currentLecture && userData && (
         <div className="bg-white rounded-lg shadow overflow-x-auto">
@@ -253,10 +339,12 @@ const GradingResultsListing: React.FC = () => {
                                     <ResultBadge resultID={result.result_id} />
                                   </Link>
                                   <Link to={`${detailLink}?id=${result.id}`} className="hover:underline" target="_blank" rel="noopener noreferrer">
-                                    <span className={`text-xs ${result.submission_ts > currentLecture.deadline ? 'text-red-600' : 'text-gray-600'}`}>
+                                    <span className={`text-xs ${result.late_seconds > 0 ? 'text-red-600' : 'text-gray-600'}`}>
                                       {formatTimestamp(result.submission_ts)}
-                                      {result.submission_ts > currentLecture.deadline && (
-                                        <span className="ml-1">(Late)</span>
+                                      {result.late_seconds > 0 && (
+                                        <span className="ml-1" title={`締切${result.extended ? ' (延長)' : ''}: ${formatTimestamp(result.deadline)}`}>
+                                          (Late{result.penalty > 0 && `, -${Math.round(result.penalty * 100)}%`})
+                                        </span>
                                       )}
                                     </span>
                                   </Link>
@@ -0,0 +1,25 @@
+interface BenchmarkPoint {
+  n: number;
+  result_id: number;
+  time_ms: number;
+  memory_kb: number;
+}
+
+interface ComplexityFit {
+  class: string;
+  residual: number;
+}
+
+interface BenchmarkDetail {
+  task_id: number;
+  title: string;
+  description: string;
+  result_id: number;
+  estimated_class: string;
+  expected_classes: string[];
+  points: BenchmarkPoint[];
+  fits: ComplexityFit[];
+  error?: string; // why the benchmark is IE
+}
+
+export type { BenchmarkDetail, BenchmarkPoint, ComplexityFit };
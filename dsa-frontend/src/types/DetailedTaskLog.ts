@@ -1,9 +1,49 @@
+interface TaskRunLog {
+  result_id: number;
+  time_ms: number;
+  cpu_time_ms: number;
+  memory_kb: number;
+  exit_code: number;
+}
+
+interface MemcheckFinding {
+  kind: string;
+  message: string;
+  leak: boolean;
+  leaked_bytes: number;
+  location: string;
+}
+
+interface ThreadLog {
+  tid: number;
+  name: string;
+  cpu_time_ms: number;
+}
+
+interface ProcessLog {
+  name: string;
+  command: string;
+  result_id: number;
+  ready: boolean;
+  background: boolean;
+  time_ms: number;
+  cpu_time_ms: number;
+  memory_kb: number;
+  exit_code: number;
+  stdout: string;
+  stderr: string;
+  truncated: boolean;
+  threads: ThreadLog[];
+}
+
 interface DetailedTaskLog {
   test_case_id: string;
   description: string;
+  message_on_fail: string; // hint for the failure, empty if the task passed
   command: string;
   result_id: number;
   time_ms: number;
+  cpu_time_ms: number;
   memory_kb: number;
   exit_code: number;
   expected_exit_code: number;
@@ -13,6 +53,12 @@ interface DetailedTaskLog {
   stderr: string;
   expected_stdout: string | null;
   expected_stderr: string | null;
+  truncated: boolean;
+  flaky: boolean;
+  runs: TaskRunLog[];
+  memcheck: MemcheckFinding[];
+  processes: ProcessLog[];
+  threads: ThreadLog[];
 }
 
-export type { DetailedTaskLog };
+export type { DetailedTaskLog, MemcheckFinding, ProcessLog, TaskRunLog, ThreadLog };
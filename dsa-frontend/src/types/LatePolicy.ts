@@ -0,0 +1,21 @@
+interface LatePolicy {
+  kind: "hard" | "linear" | "stepped";
+  grace_minutes?: number;
+  penalty_per_day?: number; // fraction of the score deducted per day, e.g., 0.1
+}
+
+// Short description of the policy, e.g., "1日ごとに10%減点 (猶予30分)"
+const describeLatePolicy = (policy: LatePolicy | null): string => {
+  if (!policy) return "減点なし";
+
+  const penalty = `${Math.round((policy.penalty_per_day ?? 0) * 100)}%`;
+  const text = {
+    hard: "締切後は0点",
+    linear: `1日あたり${penalty}減点`,
+    stepped: `1日ごとに${penalty}減点`,
+  }[policy.kind];
+  return policy.grace_minutes ? `${text} (猶予${policy.grace_minutes}分)` : text;
+};
+
+export type { LatePolicy };
+export { describeLatePolicy };
@@ -0,0 +1,24 @@
+interface MutationRun {
+  name: string;
+  result_id: number;
+  killed: boolean;
+  time_ms: number;
+  memory_kb: number;
+  exit_code: number;
+  stdout: string;
+  stderr: string;
+}
+
+interface MutationDetail {
+  task_id: number;
+  title: string;
+  description: string;
+  result_id: number;
+  score: number;
+  min_score: number;
+  reference: MutationRun;
+  mutants: MutationRun[];
+  survived: string[];
+}
+
+export type { MutationDetail, MutationRun };
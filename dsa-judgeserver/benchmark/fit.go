@@ -0,0 +1,162 @@
+package benchmark
+
+import (
+	"errors"
+	"math"
+	"slices"
+
+	"github.com/dsa-uts/dsa-project/database/model"
+	"github.com/dsa-uts/dsa-project/database/model/complexity"
+)
+
+// Minimum number of measurements needed to estimate the complexity class.
+const MIN_POINTS = 3
+
+// If the residuals of two classes differ by less than this ratio,
+// the class growing more slowly is preferred, since measurements are noisy.
+const TIE_RATIO = 1.1
+
+// If the fitted time grows by less than this ratio of itself over the sizes,
+// the growth is regarded as noise, and the class is estimated as constant.
+const MIN_GROWTH_RATIO = 0.1
+
+// Times are measured in milliseconds, so shorter ones are regarded as this.
+const RESOLUTION_MS = 1.0
+
+var growth = map[complexity.Class]func(n float64) float64{
+	complexity.Constant:     func(n float64) float64 { return 1 },
+	complexity.Logarithmic:  func(n float64) float64 { return math.Log2(n) },
+	complexity.Linear:       func(n float64) float64 { return n },
+	complexity.Linearithmic: func(n float64) float64 { return n * math.Log2(n) },
+	complexity.Quadratic:    func(n float64) float64 { return n * n },
+	complexity.Cubic:        func(n float64) float64 { return n * n * n },
+}
+
+// Estimate fits the execution times to every complexity class by least squares of
+// time = a * f(n) + b with a >= 0, and returns the best class and the fits of all classes.
+// ns and times must have the same length, and ns must be positive.
+//
+// The intercept b is the start-up time of the program, so it is limited to 0 <= b <= min(times).
+// Otherwise a free intercept lets, e.g., O(n log n) fit linear times with an offset as well as O(n).
+// Errors are relative to the times, since noise of the measurements grows with them,
+// and the residual of a fit is the root mean square of them, adjusted by the number of parameters.
+func Estimate(ns []int64, times []float64) (complexity.Class, []model.ComplexityFit, error) {
+	if len(ns) != len(times) {
+		return "", nil, errors.New("number of sizes and times differ")
+	}
+	if len(ns) < MIN_POINTS {
+		return "", nil, errors.New("not enough measurements")
+	}
+
+	if slices.Max(times) <= 0 {
+		// Too fast to be measured, nothing to fit
+		fits := []model.ComplexityFit{}
+		for _, class := range complexity.Classes {
+			fits = append(fits, model.ComplexityFit{Class: class, Residual: 0})
+		}
+		return complexity.Constant, fits, nil
+	}
+
+	// Relative errors need positive times
+	ys := make([]float64, len(times))
+	for i, t := range times {
+		ys[i] = max(t, RESOLUTION_MS)
+	}
+
+	fits := []model.ComplexityFit{}
+	best := complexity.Constant
+	bestResidual := math.Inf(1)
+	var bestLine line
+
+	for _, class := range complexity.Classes {
+		xs := make([]float64, len(ns))
+		for i, n := range ns {
+			xs[i] = growth[class](float64(n))
+		}
+
+		l := fitLine(xs, ys, class != complexity.Constant)
+		params := 2
+		if class == complexity.Constant {
+			params = 1
+		}
+		residual := math.Sqrt(l.rss / float64(len(ys)-params))
+		fits = append(fits, model.ComplexityFit{Class: class, Residual: residual})
+
+		// Classes are in increasing order of growth, so a faster growing class
+		// must be clearly better to be chosen.
+		if residual*TIE_RATIO < bestResidual {
+			best = class
+			bestResidual = residual
+			bestLine = l
+		}
+	}
+
+	// A slight growth over the sizes is more likely noise than a logarithmic or faster growth
+	if best != complexity.Constant {
+		minN := float64(slices.Min(ns))
+		maxN := float64(slices.Max(ns))
+		start := bestLine.a*growth[best](minN) + bestLine.b
+		if bestLine.a*(growth[best](maxN)-growth[best](minN)) < MIN_GROWTH_RATIO*start {
+			best = complexity.Constant
+		}
+	}
+
+	return best, fits, nil
+}
+
+// Least squares fit y = a * x + b, and its relative residual sum of squares.
+type line struct {
+	a, b float64
+	rss  float64
+}
+
+// Fits y = a * x + b with a >= 0 and 0 <= b <= min(ys) by least squares of the relative errors,
+// i.e., every point is weighted by 1 / y^2. ys must be positive.
+// If sloped is false, a is fixed to 0, and b is not limited by min(ys).
+func fitLine(xs, ys []float64, sloped bool) line {
+	minY := slices.Min(ys)
+
+	// Sums of the weighted normal equations
+	sw, sx, sy, sxx, sxy := 0.0, 0.0, 0.0, 0.0, 0.0
+	for i := range xs {
+		w := 1 / (ys[i] * ys[i])
+		sw += w
+		sx += w * xs[i]
+		sy += w * ys[i]
+		sxx += w * xs[i] * xs[i]
+		sxy += w * xs[i] * ys[i]
+	}
+
+	best := line{rss: math.Inf(1)}
+	try := func(a, b float64) {
+		if a < 0 || b < 0 || (sloped && b > minY) {
+			return
+		}
+		rss := 0.0
+		for i := range xs {
+			d := (ys[i] - (a*xs[i] + b)) / ys[i]
+			rss += d * d
+		}
+		if rss < best.rss {
+			best = line{a: a, b: b, rss: rss}
+		}
+	}
+
+	// The error is convex, so the optimum is either the unconstrained one,
+	// or on a boundary of the constraints, where it is found with the other parameter free.
+	if !sloped {
+		try(0, sy/sw)
+		return best
+	}
+	try(0, min(sy/sw, minY))
+	if det := sw*sxx - sx*sx; det > 0 {
+		a := (sw*sxy - sx*sy) / det
+		try(a, (sy-a*sx)/sw)
+	}
+	for _, b := range []float64{0, minY} {
+		if sxx > 0 {
+			try(max((sxy-b*sx)/sxx, 0), b)
+		}
+	}
+	return best
+}
@@ -0,0 +1,105 @@
+package benchmark
+
+import (
+	"math"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/dsa-uts/dsa-project/database/model/complexity"
+)
+
+// Returns execution times of offset + scale * f(n) in milliseconds, rounded like the measured ones,
+// with multiplicative noise of at most the given ratio.
+func synthesize(ns []int64, class complexity.Class, scale, offset, noise float64, seed uint64) []float64 {
+	r := rand.New(rand.NewPCG(seed, seed))
+	times := make([]float64, len(ns))
+	for i, n := range ns {
+		t := offset + scale*growth[class](float64(n))
+		t *= 1 + noise*(2*r.Float64()-1)
+		times[i] = math.Round(t)
+	}
+	return times
+}
+
+func TestEstimate(t *testing.T) {
+	doubling := []int64{1000, 2000, 4000, 8000, 16000, 32000, 64000, 128000}
+	decimal := []int64{10000, 20000, 50000, 100000, 200000, 500000, 1000000}
+	few := []int64{100000, 300000, 1000000}
+
+	tests := []struct {
+		name   string
+		ns     []int64
+		class  complexity.Class
+		scale  float64 // time of f(n) = 1 in milliseconds
+		offset float64 // start-up time in milliseconds
+		noise  float64
+	}{
+		{"constant", doubling, complexity.Constant, 20, 0, 0},
+		{"constant with noise", doubling, complexity.Constant, 20, 30, 0.05},
+		{"logarithmic", decimal, complexity.Logarithmic, 10, 0, 0},
+		{"linear", doubling, complexity.Linear, 0.01, 0, 0},
+		{"linear with offset", doubling, complexity.Linear, 0.01, 50, 0},
+		{"linear with offset and noise", decimal, complexity.Linear, 0.001, 50, 0.05},
+		{"linear with few sizes", few, complexity.Linear, 0.001, 20, 0},
+		{"linearithmic", doubling, complexity.Linearithmic, 0.001, 0, 0},
+		{"linearithmic with offset", doubling, complexity.Linearithmic, 0.001, 50, 0},
+		{"linearithmic with offset and noise", decimal, complexity.Linearithmic, 0.0002, 50, 0.05},
+		{"quadratic", doubling, complexity.Quadratic, 0.0000005, 0, 0},
+		{"quadratic with offset and noise", doubling, complexity.Quadratic, 0.0000005, 50, 0.05},
+		{"cubic", []int64{100, 200, 400, 800, 1600}, complexity.Cubic, 0.000001, 10, 0.05},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Noise is random, so several seeds are tried
+			for seed := range uint64(20) {
+				times := synthesize(tt.ns, tt.class, tt.scale, tt.offset, tt.noise, seed)
+				got, fits, err := Estimate(tt.ns, times)
+				if err != nil {
+					t.Fatalf("Estimate() error = %v", err)
+				}
+				if got != tt.class {
+					t.Fatalf("Estimate(%v, %v) = %s, want %s (fits: %v)", tt.ns, times, got, tt.class, fits)
+				}
+				if len(fits) != len(complexity.Classes) {
+					t.Fatalf("Estimate() returned %d fits, want %d", len(fits), len(complexity.Classes))
+				}
+				if tt.noise == 0 {
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestEstimateErrors(t *testing.T) {
+	if _, _, err := Estimate([]int64{1, 2, 3}, []float64{1, 2}); err == nil {
+		t.Error("Estimate() with different lengths succeeded")
+	}
+	if _, _, err := Estimate([]int64{1, 2}, []float64{1, 2}); err == nil {
+		t.Error("Estimate() with too few measurements succeeded")
+	}
+}
+
+func TestEstimateZeroTime(t *testing.T) {
+	// Times shorter than the resolution are measured as 0 ms, which must not break relative errors
+	_, fits, err := Estimate([]int64{1000, 2000, 4000, 8000}, []float64{0, 10, 20, 40})
+	if err != nil {
+		t.Fatalf("Estimate() error = %v", err)
+	}
+	for _, fit := range fits {
+		if math.IsNaN(fit.Residual) || math.IsInf(fit.Residual, 0) {
+			t.Errorf("residual of %s = %v", fit.Class, fit.Residual)
+		}
+	}
+}
+
+func TestEstimateTooFast(t *testing.T) {
+	got, _, err := Estimate([]int64{1000, 2000, 4000}, []float64{0, 0, 0})
+	if err != nil {
+		t.Fatalf("Estimate() error = %v", err)
+	}
+	if got != complexity.Constant {
+		t.Errorf("Estimate() = %s, want %s", got, complexity.Constant)
+	}
+}
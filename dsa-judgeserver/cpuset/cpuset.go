@@ -0,0 +1,172 @@
+package cpuset
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/sys/unix"
+)
+
+// Pool hands out exclusive sets of CPU cores to jobs.
+// Requests are served in the order they arrive, so that a job asking for many cores
+// is not starved by smaller jobs taking the cores as soon as they are released.
+type Pool struct {
+	mu      sync.Mutex
+	cores   []int
+	free    map[int]bool
+	waiters []*waiter
+}
+
+type waiter struct {
+	n     int
+	ready chan []int // receives the allocated cores, buffered so that the pool never blocks
+}
+
+// NewPool creates a pool of the given cores.
+func NewPool(cores []int) (*Pool, error) {
+	if len(cores) == 0 {
+		return nil, fmt.Errorf("cpu pool must have at least one core")
+	}
+	free := make(map[int]bool, len(cores))
+	for _, core := range cores {
+		if free[core] {
+			return nil, fmt.Errorf("core %d appears more than once", core)
+		}
+		free[core] = true
+	}
+	return &Pool{
+		cores: slices.Sorted(slices.Values(cores)),
+		free:  free,
+	}, nil
+}
+
+// Size returns the number of cores in the pool.
+func (p *Pool) Size() int {
+	return len(p.cores)
+}
+
+// Acquire waits until n cores are available and allocates them exclusively.
+// The caller has responsibility to call Release with the returned cores.
+func (p *Pool) Acquire(ctx context.Context, n int) ([]int, error) {
+	if n <= 0 {
+		n = 1
+	}
+	if n > len(p.cores) {
+		return nil, fmt.Errorf("%d cores are requested, but only %d cores are available", n, len(p.cores))
+	}
+
+	p.mu.Lock()
+	w := &waiter{n: n, ready: make(chan []int, 1)}
+	p.waiters = append(p.waiters, w)
+	p.dispatch()
+	p.mu.Unlock()
+
+	select {
+	case cores := <-w.ready:
+		return cores, nil
+	case <-ctx.Done():
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		if i := slices.Index(p.waiters, w); i >= 0 {
+			p.waiters = slices.Delete(p.waiters, i, i+1)
+			// Later requests may fit now that this one is gone
+			p.dispatch()
+		} else {
+			// The cores were allocated at the same time as the cancellation
+			p.release(<-w.ready)
+		}
+		return nil, ctx.Err()
+	}
+}
+
+// Release returns the cores allocated by Acquire to the pool.
+func (p *Pool) Release(cores []int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.release(cores)
+}
+
+func (p *Pool) release(cores []int) {
+	for _, core := range cores {
+		p.free[core] = true
+	}
+	p.dispatch()
+}
+
+// Allocates cores to the waiters from the head of the queue, as long as they fit.
+// p.mu must be held.
+func (p *Pool) dispatch() {
+	for len(p.waiters) > 0 {
+		w := p.waiters[0]
+		cores := make([]int, 0, w.n)
+		for _, core := range p.cores {
+			if len(cores) == w.n {
+				break
+			}
+			if p.free[core] {
+				cores = append(cores, core)
+			}
+		}
+		if len(cores) < w.n {
+			return
+		}
+		for _, core := range cores {
+			p.free[core] = false
+		}
+		p.waiters = p.waiters[1:]
+		w.ready <- cores
+	}
+}
+
+// Parse parses a list of cores in the format of cpuset, e.g., "0-3,6".
+// An empty string means all cores available to this process, i.e., its CPU affinity.
+// Cores are not always numbered from 0, e.g., if the process is started with taskset or docker --cpuset-cpus.
+func Parse(s string) ([]int, error) {
+	if strings.TrimSpace(s) == "" {
+		var set unix.CPUSet
+		if err := unix.SchedGetaffinity(0, &set); err != nil {
+			return nil, fmt.Errorf("failed to get CPU affinity: %w", err)
+		}
+		cores := []int{}
+		for core := 0; len(cores) < set.Count(); core++ {
+			if set.IsSet(core) {
+				cores = append(cores, core)
+			}
+		}
+		return cores, nil
+	}
+
+	cores := []int{}
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		first, last, isRange := strings.Cut(part, "-")
+		from, err := strconv.Atoi(first)
+		if err != nil || from < 0 {
+			return nil, fmt.Errorf("invalid core %q in %q", part, s)
+		}
+		to := from
+		if isRange {
+			to, err = strconv.Atoi(last)
+			if err != nil || to < from {
+				return nil, fmt.Errorf("invalid range of cores %q in %q", part, s)
+			}
+		}
+		for core := from; core <= to; core++ {
+			cores = append(cores, core)
+		}
+	}
+	return cores, nil
+}
+
+// Format formats the cores as a value of CpusetCpus of Docker, e.g., "0,1,2".
+func Format(cores []int) string {
+	parts := make([]string, len(cores))
+	for i, core := range cores {
+		parts[i] = strconv.Itoa(core)
+	}
+	return strings.Join(parts, ",")
+}
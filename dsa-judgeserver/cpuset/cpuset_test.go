@@ -0,0 +1,241 @@
+package cpuset
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []int
+		wantErr bool
+	}{
+		{"single core", "2", []int{2}, false},
+		{"range", "0-3", []int{0, 1, 2, 3}, false},
+		{"range and cores", "0-2,6, 8", []int{0, 1, 2, 6, 8}, false},
+		{"range of one core", "4-4", []int{4}, false},
+		{"reversed range", "3-1", nil, true},
+		{"open range", "1-", nil, true},
+		{"negative core", "-1", nil, true},
+		{"not a number", "a", nil, true},
+		{"empty part", "0,,1", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if !tt.wantErr && !slices.Equal(got, tt.want) {
+				t.Errorf("Parse(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseAffinity(t *testing.T) {
+	// An empty string is the CPU affinity of the process, which has at least one core
+	cores, err := Parse(" ")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(cores) == 0 || !slices.IsSorted(cores) {
+		t.Errorf("Parse() = %v, want sorted cores", cores)
+	}
+}
+
+func TestNewPool(t *testing.T) {
+	if _, err := NewPool(nil); err == nil {
+		t.Error("NewPool() without cores succeeded")
+	}
+	if _, err := NewPool([]int{0, 1, 0}); err == nil {
+		t.Error("NewPool() with a duplicate core succeeded")
+	}
+
+	pool, err := NewPool([]int{4, 2})
+	if err != nil {
+		t.Fatalf("NewPool() error = %v", err)
+	}
+	if _, err := pool.Acquire(context.Background(), 3); err == nil {
+		t.Error("Acquire() of more cores than the pool succeeded")
+	}
+	cores, err := pool.Acquire(context.Background(), 0)
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+	if !slices.Equal(cores, []int{2}) {
+		t.Errorf("Acquire(0) = %v, want the lowest core [2]", cores)
+	}
+}
+
+type acquired struct {
+	cores []int
+	err   error
+}
+
+// Starts Acquire in a goroutine, and waits until it is queued in the pool.
+func acquireAsync(t *testing.T, ctx context.Context, pool *Pool, n int) <-chan acquired {
+	t.Helper()
+	pool.mu.Lock()
+	queued := len(pool.waiters)
+	pool.mu.Unlock()
+
+	done := make(chan acquired, 1)
+	go func() {
+		cores, err := pool.Acquire(ctx, n)
+		done <- acquired{cores, err}
+	}()
+
+	for deadline := time.Now().Add(5 * time.Second); ; {
+		pool.mu.Lock()
+		waiting := len(pool.waiters) > queued
+		pool.mu.Unlock()
+		if waiting {
+			return done
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Acquire() was not queued")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func receive(t *testing.T, done <-chan acquired) acquired {
+	t.Helper()
+	select {
+	case result := <-done:
+		return result
+	case <-time.After(5 * time.Second):
+		t.Fatal("Acquire() did not return")
+		return acquired{}
+	}
+}
+
+func assertWaiting(t *testing.T, done <-chan acquired) {
+	t.Helper()
+	select {
+	case result := <-done:
+		t.Fatalf("Acquire() returned %v, %v while it should wait", result.cores, result.err)
+	default:
+	}
+}
+
+// Every core must be free and no request must wait.
+func assertIdle(t *testing.T, pool *Pool) {
+	t.Helper()
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+	for _, core := range pool.cores {
+		if !pool.free[core] {
+			t.Errorf("core %d is not released", core)
+		}
+	}
+	if len(pool.waiters) > 0 {
+		t.Errorf("%d requests are still waiting", len(pool.waiters))
+	}
+}
+
+func TestAcquireFIFO(t *testing.T) {
+	ctx := context.Background()
+	pool, err := NewPool([]int{0, 1, 2, 3})
+	if err != nil {
+		t.Fatalf("NewPool() error = %v", err)
+	}
+
+	first, err := pool.Acquire(ctx, 3)
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+
+	// A large request at the head blocks smaller ones behind it, even though a core is free
+	large := acquireAsync(t, ctx, pool, 4)
+	small := acquireAsync(t, ctx, pool, 1)
+	assertWaiting(t, large)
+	assertWaiting(t, small)
+
+	pool.Release(first)
+	result := receive(t, large)
+	if result.err != nil || len(result.cores) != 4 {
+		t.Fatalf("Acquire(4) = %v, %v, want all cores", result.cores, result.err)
+	}
+	assertWaiting(t, small)
+
+	pool.Release(result.cores)
+	result = receive(t, small)
+	if result.err != nil || len(result.cores) != 1 {
+		t.Fatalf("Acquire(1) = %v, %v, want a core", result.cores, result.err)
+	}
+
+	pool.Release(result.cores)
+	assertIdle(t, pool)
+}
+
+func TestAcquireCancel(t *testing.T) {
+	pool, err := NewPool([]int{0, 1})
+	if err != nil {
+		t.Fatalf("NewPool() error = %v", err)
+	}
+
+	held, err := pool.Acquire(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+
+	// Cancelling the request at the head lets the requests behind it take the free cores
+	ctx, cancel := context.WithCancel(context.Background())
+	large := acquireAsync(t, ctx, pool, 2)
+	small := acquireAsync(t, context.Background(), pool, 1)
+	assertWaiting(t, small)
+
+	cancel()
+	if result := receive(t, large); !errors.Is(result.err, context.Canceled) {
+		t.Fatalf("Acquire() = %v, %v, want %v", result.cores, result.err, context.Canceled)
+	}
+	result := receive(t, small)
+	if result.err != nil || len(result.cores) != 1 {
+		t.Fatalf("Acquire(1) = %v, %v, want a core", result.cores, result.err)
+	}
+
+	pool.Release(held)
+	pool.Release(result.cores)
+	assertIdle(t, pool)
+}
+
+func TestAcquireCancelRacingDispatch(t *testing.T) {
+	pool, err := NewPool([]int{0, 1})
+	if err != nil {
+		t.Fatalf("NewPool() error = %v", err)
+	}
+
+	// Cores allocated at the same time as the cancellation must return to the pool.
+	// Which one wins is up to the scheduler, so it is tried many times.
+	for range 100 {
+		held, err := pool.Acquire(context.Background(), 2)
+		if err != nil {
+			t.Fatalf("Acquire() error = %v", err)
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := acquireAsync(t, ctx, pool, 2)
+
+		// Cancel while the pool is locked, so that the cores are allocated before the cancellation is handled
+		pool.mu.Lock()
+		cancel()
+		pool.release(held)
+		pool.mu.Unlock()
+
+		result := receive(t, done)
+		if result.err == nil {
+			pool.Release(result.cores)
+		} else if !errors.Is(result.err, context.Canceled) {
+			t.Fatalf("Acquire() error = %v, want %v", result.err, context.Canceled)
+		}
+		assertIdle(t, pool)
+	}
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"context"
+	"dsa-judgeserver/benchmark"
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+
+	"github.com/dsa-uts/dsa-project/database/model"
+	"github.com/dsa-uts/dsa-project/database/model/requeststatus"
+	"github.com/google/uuid"
+)
+
+// generatorError is a failure of the generator of a benchmark task.
+// It is a fault of the problem, not of the submission, so only the benchmark task is marked as IE.
+type generatorError struct {
+	message string
+}
+
+func (e *generatorError) Error() string {
+	return e.message
+}
+
+// Runs benchmark tasks of the job in a new judge container.
+// For each input size, the generator writes an input into the I/O directory,
+// and the program reads it from stdin. Larger sizes are skipped once a run fails.
+// Generators run as root from TRUSTED_DIR_IN_CONTAINER, so that the program cannot replace them.
+func (executor *JobExecutor) executeBenchmarkTasks(ctx context.Context, job *model.JobDetail, volumeName, ioVolumeName, cpuSet string) ([]model.BenchmarkLog, error) {
+	benchmark_container_name := fmt.Sprintf("benchmark-%s", uuid.New().String())
+
+	containerID, err := executor.startSandboxContainer(ctx, benchmark_container_name, "binary-runner", job, volumeName, ioVolumeName, cpuSet, PID_LIMIT, 128)
+	if err != nil {
+		return nil, err
+	}
+
+	defer executor.stopAndRemoveContainer(ctx, containerID)
+
+	if err := executor.prepareIODirectory(ctx, containerID, job, nil); err != nil {
+		return nil, err
+	}
+	if err := executor.prepareTrustedDirectory(ctx, containerID, job, ""); err != nil {
+		return nil, err
+	}
+
+	benchmarkLogs := []model.BenchmarkLog{}
+
+	for _, task := range job.BenchmarkTasks {
+		benchmarkLog := model.BenchmarkLog{
+			TaskID:   task.ID,
+			ResultID: requeststatus.AC,
+			Points:   []model.BenchmarkPoint{},
+			Fits:     []model.ComplexityFit{},
+		}
+
+		ns := []int64{}
+		times := []float64{}
+
+		for _, n := range task.Sizes {
+			point, err := executor.runBenchmarkPoint(ctx, containerID, job, task, n)
+			var genErr *generatorError
+			if errors.As(err, &genErr) {
+				benchmarkLog.ResultID = requeststatus.IE
+				benchmarkLog.Error = fmt.Sprintf("generator failed with n=%d: %s", n, genErr.message)
+				break
+			}
+			if err != nil {
+				benchmarkLog.ResultID = requeststatus.IE
+				benchmarkLogs = append(benchmarkLogs, benchmarkLog)
+				return benchmarkLogs, fmt.Errorf("failed to run benchmark task %s with n=%d: %w", task.Title, n, err)
+			}
+
+			benchmarkLog.Points = append(benchmarkLog.Points, point)
+			benchmarkLog.ResultID = benchmarkLog.ResultID.Max(point.ResultID)
+
+			if point.ResultID != requeststatus.AC {
+				// Larger inputs would fail as well
+				break
+			}
+			ns = append(ns, n)
+			times = append(times, float64(point.TimeMS))
+		}
+
+		if benchmarkLog.ResultID != requeststatus.IE && len(ns) >= benchmark.MIN_POINTS {
+			estimatedClass, fits, err := benchmark.Estimate(ns, times)
+			if err != nil {
+				benchmarkLog.ResultID = requeststatus.IE
+				benchmarkLogs = append(benchmarkLogs, benchmarkLog)
+				return benchmarkLogs, fmt.Errorf("failed to estimate complexity of benchmark task %s: %w", task.Title, err)
+			}
+			benchmarkLog.EstimatedClass = estimatedClass
+			benchmarkLog.Fits = fits
+
+			if len(task.Expected) > 0 && !slices.Contains(task.Expected, estimatedClass) {
+				benchmarkLog.ResultID = benchmarkLog.ResultID.Max(requeststatus.WA)
+			}
+		}
+
+		benchmarkLogs = append(benchmarkLogs, benchmarkLog)
+	}
+
+	return benchmarkLogs, nil
+}
+
+// Generates an input of size n, and runs the program of the benchmark task on it.
+// A *generatorError is returned if the generator fails, and other errors if the judge itself fails.
+func (executor *JobExecutor) runBenchmarkPoint(ctx context.Context, containerID string, job *model.JobDetail, task model.BenchmarkTask, n int64) (model.BenchmarkPoint, error) {
+	point := model.BenchmarkPoint{
+		N:        n,
+		ResultID: requeststatus.IE,
+	}
+
+	inputPath := path.Join(OUTPUT_DIR_IN_CONTAINER, fmt.Sprintf("benchmark_%d_input.txt", task.ID))
+	stdoutPath := path.Join(OUTPUT_DIR_IN_CONTAINER, fmt.Sprintf("benchmark_%d_stdout.txt", task.ID))
+	stderrPath := path.Join(OUTPUT_DIR_IN_CONTAINER, fmt.Sprintf("benchmark_%d_stderr.txt", task.ID))
+
+	removeFiles := func() error {
+		// Remove the input and outputs, so that they do not fill up the I/O volume
+		if res, err := executor.ExecuteSimpleCommand(ctx, containerID, []string{
+			"rm", "-f", inputPath, stdoutPath, stderrPath,
+		}); err != nil {
+			return fmt.Errorf("failed to remove benchmark files: %s, stderr: %s", err.Error(), res.Stderr)
+		}
+		return nil
+	}
+
+	// Processes left by the previous run must not interfere with the generator running as root
+	if err := executor.killGuestProcesses(ctx, containerID); err != nil {
+		return point, err
+	}
+
+	// Generate the input
+	generatorOutput, err := executor.execWatchdog(ctx, containerID, WatchdogInput{
+		Command:        fmt.Sprintf("%s %d", task.Generator, n),
+		StdoutPath:     inputPath,
+		StderrPath:     stderrPath,
+		TimeoutMS:      task.TimeMS,
+		MemoryMB:       job.MemoryMB,
+		UID:            UID_ROOT,
+		GID:            GID_ROOT,
+		StdoutMaxBytes: MAX_OUTPUT_LIMIT_BYTES,
+		StderrMaxBytes: outputLimitBytes(job),
+	}, TRUSTED_DIR_IN_CONTAINER)
+	if err != nil {
+		return point, err
+	}
+	if *generatorOutput.ExitCode != 0 || generatorOutput.TLE || generatorOutput.MLE || generatorOutput.OLE {
+		if err := removeFiles(); err != nil {
+			return point, err
+		}
+		return point, &generatorError{fmt.Sprintf("exit code %d (TLE: %t, MLE: %t, OLE: %t, the input must be at most %d bytes)",
+			*generatorOutput.ExitCode, generatorOutput.TLE, generatorOutput.MLE, generatorOutput.OLE, MAX_OUTPUT_LIMIT_BYTES)}
+	}
+
+	// Run the program. Its output is not checked, correctness is up to judge tasks.
+	output, err := executor.execWatchdog(ctx, containerID, WatchdogInput{
+		Command:        task.Command,
+		StdinPath:      inputPath,
+		StdoutPath:     stdoutPath,
+		StderrPath:     stderrPath,
+		TimeoutMS:      task.TimeMS,
+		MemoryMB:       job.MemoryMB,
+		UID:            UID_GUEST,
+		GID:            GID_GUEST,
+		StdoutMaxBytes: MAX_OUTPUT_LIMIT_BYTES,
+		StderrMaxBytes: outputLimitBytes(job),
+	}, "/home/guest")
+	if err != nil {
+		return point, err
+	}
+
+	var resultStatus requeststatus.State = requeststatus.AC
+	if output.OLE {
+		resultStatus = resultStatus.Max(requeststatus.OLE)
+	}
+	if output.MLE {
+		resultStatus = resultStatus.Max(requeststatus.MLE)
+	}
+	if output.TLE {
+		resultStatus = resultStatus.Max(requeststatus.TLE)
+	}
+	if *output.ExitCode != 0 {
+		resultStatus = resultStatus.Max(requeststatus.RE)
+	}
+
+	if err := removeFiles(); err != nil {
+		return point, err
+	}
+
+	point.ResultID = resultStatus
+	point.TimeMS = output.TimeMS
+	point.MemoryKB = output.MemoryKB
+	return point, nil
+}
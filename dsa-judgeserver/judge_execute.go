@@ -3,12 +3,19 @@ package main
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"dsa-judgeserver/cpuset"
 	"dsa-judgeserver/match"
+	"dsa-judgeserver/memcheck"
 	"dsa-judgeserver/util"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"maps"
 	"os"
+	"path"
 	"path/filepath"
+	"slices"
 	"time"
 
 	"github.com/docker/docker/api/types/container"
@@ -21,21 +28,57 @@ import (
 )
 
 type JobExecutor struct {
-	client *client.Client
+	client      *client.Client
+	cpuPool     *cpuset.Pool // cores assigned exclusively to the containers of each job
+	imageDigest string       // digest of the sandbox images, set by LoadImageDigest
 }
 
 const UPLOAD_DIR_IN_HOST = "upload/"
+const UID_ROOT = 0
+const GID_ROOT = 0
 const UID_GUEST = 1002
 const GID_GUEST = 1002
-const MAX_STDOUT_BYTES = 4 * 1024 // 4 KB
-const MAX_STDERR_BYTES = 4 * 1024 // 4 KB
+const DEFAULT_OUTPUT_LIMIT_BYTES = 4 * 1024    // 4 KB, used when the job does not specify the output limit
+const MAX_OUTPUT_LIMIT_BYTES = 8 * 1024 * 1024 // 8 MB, must be smaller than the file size limit of the sandbox
 
-const CPU_SET = "0"                       // only 1 CPU core can be used.
 const TIMEOUT_BEFORE_CONTAINER_STOP = 120 // timeout in seconds for stopping container
 const PID_LIMIT = 64                      // limit max number of processes available to spawn
 const MAX_MEMORY_LIMIT_MB = 1024          // 1 GB
+const MAX_REPEAT_COUNT = 10               // max number of runs of a repeated judge task
+
+// Directory in sandbox containers that holds stdin files and output files of tasks.
+// It is a separate volume only accessible by root, so that user programs cannot read
+// stdin of other tasks nor tamper with outputs.
+const IO_DIR_IN_CONTAINER = "/judge"
+const STDIN_DIR_IN_CONTAINER = IO_DIR_IN_CONTAINER + "/stdin"
+const OUTPUT_DIR_IN_CONTAINER = IO_DIR_IN_CONTAINER + "/output"
+
+// Ownership and permissions of files copied into sandbox containers, set in the tar headers.
+// Files in /home/guest and working directories belong to guest,
+// while stdin files are only readable by root, as well as the I/O directory.
+var GUEST_TAR_OPTIONS = util.TarOptions{UID: UID_GUEST, GID: GID_GUEST, DirMode: 0755}
+var STDIN_TAR_OPTIONS = util.TarOptions{UID: 0, GID: 0, DirMode: 0700, FileMode: 0600}
+var TRUSTED_TAR_OPTIONS = util.TarOptions{UID: 0, GID: 0, DirMode: 0700}
+
+// Directory in sandbox containers where commands of the problem author (e.g., checkers) run as root.
+// It is a copy of the test files in the I/O directory, made from the host, so that the submitted
+// program, which runs as guest in the same container, can neither read nor replace them.
+const TRUSTED_DIR_IN_CONTAINER = IO_DIR_IN_CONTAINER + "/trusted"
+
+// Environment variables of commands run by the watchdog, which the variables of tasks are added to.
+// The watchdog passes only these to the command, and does not run with them itself,
+// since it runs as root and variables like LD_PRELOAD are set by problem authors.
+var WATCHDOG_BASE_ENV = map[string]string{
+	"PATH": "/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin",
+	"HOME": "/home/guest",
+}
+
+// Valgrind memcheck slows down the program and uses extra memory,
+// so the limits of memcheck tasks are relaxed by these.
+const MEMCHECK_TIME_SCALE = 10
+const MEMCHECK_MEMORY_OVERHEAD_MB = 128
 
-func NewJobExecutor() (*JobExecutor, error) {
+func NewJobExecutor(cpuPool *cpuset.Pool) (*JobExecutor, error) {
 	// Create API Client
 	apiClient, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
 	if err != nil {
@@ -43,15 +86,24 @@ func NewJobExecutor() (*JobExecutor, error) {
 	}
 
 	return &JobExecutor{
-		client: apiClient,
+		client:  apiClient,
+		cpuPool: cpuPool,
 	}, nil
 }
 
 func (executor *JobExecutor) ExecuteJob(ctx context.Context, job *model.JobDetail) (*model.RequestLog, error) {
+	// Wait for the cores of the job, so that jobs do not affect the speed of each other
+	cores, err := executor.cpuPool.Acquire(ctx, int(job.CPUCores))
+	if err != nil {
+		return nil, fmt.Errorf("failed to allocate %d cores: %w", job.CPUCores, err)
+	}
+	defer executor.cpuPool.Release(cores)
+	cpuSet := cpuset.Format(cores)
+
 	// Create Docker Volume to store user program files and compilation results
 	volume_name := fmt.Sprintf("job-%s", uuid.New().String())
 
-	volume, err := executor.client.VolumeCreate(ctx, volume.CreateOptions{
+	jobVolume, err := executor.client.VolumeCreate(ctx, volume.CreateOptions{
 		Name: volume_name,
 	})
 
@@ -59,57 +111,103 @@ func (executor *JobExecutor) ExecuteJob(ctx context.Context, job *model.JobDetai
 		return nil, err
 	}
 
-	defer executor.RemoveVolume(ctx, volume.Name)
+	defer executor.RemoveVolume(ctx, jobVolume.Name)
+
+	// Create Docker Volume to store stdin and outputs of tasks
+	ioVolume, err := executor.client.VolumeCreate(ctx, volume.CreateOptions{
+		Name: fmt.Sprintf("jobio-%s", uuid.New().String()),
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer executor.RemoveVolume(ctx, ioVolume.Name)
+
+	if err = os.MkdirAll(job.ResultDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create result directory %s: %w", job.ResultDir, err)
+	}
 
 	requestLog := model.RequestLog{}
 
-	buildLog, err := executor.executeBuildTasks(ctx, job, volume.Name)
+	buildLog, err := executor.executeBuildTasks(ctx, job, jobVolume.Name, ioVolume.Name, cpuSet)
 	if err != nil {
 		requestLog.ConstructFromTaskLogs(buildLog, nil)
 		return &requestLog, err
 	}
 
-	judgeLog, err := executor.executeJudgeTasks(ctx, job, volume.Name)
+	judgeLog, err := executor.executeJudgeTasks(ctx, job, jobVolume.Name, ioVolume.Name, cpuSet)
 
 	requestLog.ConstructFromTaskLogs(buildLog, judgeLog)
-	return &requestLog, err
-}
+	if err != nil {
+		return &requestLog, err
+	}
 
-func (executor *JobExecutor) executeBuildTasks(ctx context.Context, job *model.JobDetail, volumeName string) ([]model.TaskLog, error) {
-	// Launch Sandbox Container to compile user codes
-	build_container_name := fmt.Sprintf("build-%s", uuid.New().String())
+	// Benchmarks and mutation testing make sense only if the program has been built
+	buildFailed := slices.ContainsFunc(buildLog, func(log model.TaskLog) bool {
+		return log.ResultID != requeststatus.AC
+	})
+	if buildFailed {
+		return &requestLog, nil
+	}
 
-	cpuSet := CPU_SET
+	if len(job.BenchmarkTasks) > 0 {
+		benchmarkLog, err := executor.executeBenchmarkTasks(ctx, job, jobVolume.Name, ioVolume.Name, cpuSet)
+		requestLog.SetBenchmarkResults(benchmarkLog)
+		if err != nil {
+			return &requestLog, err
+		}
+	}
+
+	if len(job.MutationTasks) > 0 {
+		mutationLog, err := executor.executeMutationTasks(ctx, job, jobVolume.Name, ioVolume.Name, cpuSet)
+		requestLog.SetMutationResults(mutationLog)
+		if err != nil {
+			return &requestLog, err
+		}
+	}
+
+	return &requestLog, nil
+}
+
+// Creates and starts a sandbox container, which mounts the job volume on /home/guest
+// and the I/O volume on IO_DIR_IN_CONTAINER, and runs only on the cores in cpuSet.
+// Returns the ID of the container.
+// The caller has responsibility to call stopAndRemoveContainer.
+func (executor *JobExecutor) startSandboxContainer(ctx context.Context, name, image string, job *model.JobDetail, volumeName, ioVolumeName, cpuSet string, pidLimit, nofileLimit int64) (string, error) {
 	timeout := TIMEOUT_BEFORE_CONTAINER_STOP
-	pidLimit := int64(256) // allow more processes for build tasks
 	// add 32MB for overhead
 	totalMemoryInBytes := min(
 		(job.MemoryMB+32)*1024*1024, MAX_MEMORY_LIMIT_MB*1024*1024)
 
-	buildContainer_createResponse, err := executor.client.ContainerCreate(ctx,
+	createResponse, err := executor.client.ContainerCreate(ctx,
 		&container.Config{
 			User:  "root",
 			Cmd:   []string{"/bin/sh", "-c", "sleep 3600"},
-			Image: "checker-lang-gcc",
+			Image: image,
 			Volumes: map[string]struct{}{
-				"/home/guest": {},
+				"/home/guest":       {},
+				IO_DIR_IN_CONTAINER: {},
 			},
 			WorkingDir:      "/home/guest",
-			NetworkDisabled: true,
+			NetworkDisabled: true, // only loopback is available, e.g., for servers and clients of multi-process tasks
 			StopTimeout:     &timeout,
 		},
 		&container.HostConfig{
-			Binds: []string{fmt.Sprintf("%s:/home/guest", volumeName)},
+			Binds: []string{
+				fmt.Sprintf("%s:/home/guest", volumeName),
+				fmt.Sprintf("%s:%s", ioVolumeName, IO_DIR_IN_CONTAINER),
+			},
 			Resources: container.Resources{
-				CpusetCpus: cpuSet, // only 1 CPU core can be used.
+				CpusetCpus: cpuSet, // only the cores allocated to the job can be used
 				Memory:     totalMemoryInBytes,
 				MemorySwap: totalMemoryInBytes, // disable swap
 				PidsLimit:  &pidLimit,          // limit max number of processes available to spawn
 				Ulimits: []*container.Ulimit{
 					{
 						Name: "nofile", // limit max number of open files
-						Hard: 768,
-						Soft: 768,
+						Hard: nofileLimit,
+						Soft: nofileLimit,
 					},
 					{
 						Name: "nproc", // limit max number of processes
@@ -135,41 +233,240 @@ func (executor *JobExecutor) executeBuildTasks(ctx context.Context, job *model.J
 		},
 		nil,
 		nil,
-		build_container_name,
+		name,
 	)
 
-	if buildContainer_createResponse.Warnings != nil {
-		for _, warning := range buildContainer_createResponse.Warnings {
+	if createResponse.Warnings != nil {
+		for _, warning := range createResponse.Warnings {
 			fmt.Printf("Docker Warning: %s\n", warning)
 		}
 	}
 
 	if err != nil {
-		return nil, err
+		return "", err
 	}
 
-	defer executor.RemoveContainer(ctx, buildContainer_createResponse.ID)
-
-	// Start the build container
-	err = executor.client.ContainerStart(ctx, buildContainer_createResponse.ID, container.StartOptions{})
+	// Start the container
+	err = executor.client.ContainerStart(ctx, createResponse.ID, container.StartOptions{})
 	if err != nil {
-		return nil, err
+		executor.RemoveContainer(ctx, createResponse.ID)
+		return "", err
 	}
 
+	return createResponse.ID, nil
+}
+
+// Kills and removes the sandbox container.
+func (executor *JobExecutor) stopAndRemoveContainer(ctx context.Context, containerID string) {
 	timeoutBeforeStop := 0
-	defer executor.client.ContainerStop(ctx, buildContainer_createResponse.ID, container.StopOptions{
+	executor.client.ContainerStop(ctx, containerID, container.StopOptions{
 		Signal:  "SIGKILL",
 		Timeout: &timeoutBeforeStop, // do not wait before killing the container
 	})
+	executor.RemoveContainer(ctx, containerID)
+}
+
+// Creates the I/O directory only accessible by root, and copies stdin files of the tasks into it.
+func (executor *JobExecutor) prepareIODirectory(ctx context.Context, containerID string, job *model.JobDetail, tasks []model.TestCase) error {
+	res, err := executor.ExecuteSimpleCommand(ctx, containerID, []string{
+		"sh", "-c", fmt.Sprintf("mkdir -p %s %s && chmod -R 700 %s", STDIN_DIR_IN_CONTAINER, OUTPUT_DIR_IN_CONTAINER, IO_DIR_IN_CONTAINER),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create I/O directory: %s, stderr: %s", err.Error(), res.Stderr)
+	}
+
+	stdinPaths := []string{}
+	for _, task := range tasks {
+		if task.StdinPath != "" && !slices.Contains(stdinPaths, task.StdinPath) {
+			stdinPaths = append(stdinPaths, task.StdinPath)
+		}
+		for _, process := range task.Processes {
+			if process.StdinPath != "" && !slices.Contains(stdinPaths, process.StdinPath) {
+				stdinPaths = append(stdinPaths, process.StdinPath)
+			}
+		}
+	}
+	if len(stdinPaths) == 0 {
+		return nil
+	}
+
+	tarReader := util.CreateTarArchiveFromFiles(job.ResourceDir, stdinPaths, STDIN_TAR_OPTIONS)
+	defer tarReader.Close()
+
+	err = executor.client.CopyToContainer(ctx, containerID, STDIN_DIR_IN_CONTAINER, tarReader, container.CopyToContainerOptions{
+		AllowOverwriteDirWithFile: false,
+		CopyUIDGID:                true,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to copy stdin files to container: %w", err)
+	}
+
+	return nil
+}
+
+// Result of a task executed by the watchdog.
+// Stdout and stderr of the task are copied back into the result directory of the job.
+type watchdogResult struct {
+	Output       WatchdogOutput
+	StdoutPath   string // path in host
+	StderrPath   string // path in host
+	MemcheckPath string // path in host, only for memcheck tasks
+}
+
+// Runs the task as guest with the watchdog in the container, then copies its outputs into job.ResultDir
+// as {outputName}_stdout.txt and {outputName}_stderr.txt.
+// Memcheck tasks are run under Valgrind, and its report is copied as {outputName}_memcheck.xml.
+// The report is created by the watchdog in the I/O directory, so the program cannot tamper with it.
+// An error is returned only if the watchdog itself fails, i.e., the task should be marked as IE.
+func (executor *JobExecutor) runWatchdog(ctx context.Context, containerID string, job *model.JobDetail, task model.TestCase, outputName, workingDir string) (watchdogResult, error) {
+	return executor.runWatchdogAs(ctx, containerID, job, task, outputName, workingDir, UID_GUEST, GID_GUEST)
+}
+
+// Same as runWatchdog, but runs the task as the given user.
+// Only commands of the problem author may run as root, from TRUSTED_DIR_IN_CONTAINER.
+func (executor *JobExecutor) runWatchdogAs(ctx context.Context, containerID string, job *model.JobDetail, task model.TestCase, outputName, workingDir string, uid, gid int64) (watchdogResult, error) {
+	result := watchdogResult{}
+
+	stdoutFileName := outputName + "_stdout.txt"
+	stderrFileName := outputName + "_stderr.txt"
+
+	stdinPath := ""
+	if task.StdinPath != "" {
+		stdinPath = path.Join(STDIN_DIR_IN_CONTAINER, filepath.ToSlash(task.StdinPath))
+	}
+
+	command := taskCommand(task)
+	timeoutMS := job.TimeMS
+	cpuTimeoutMS := job.CPUTimeMS
+	memoryMB := job.MemoryMB
+	memcheckFileName := outputName + "_memcheck.xml"
+	if task.Memcheck {
+		command = memcheck.Command(command, WATCHDOG_REPORT_FD)
+		timeoutMS *= MEMCHECK_TIME_SCALE
+		cpuTimeoutMS *= MEMCHECK_TIME_SCALE
+		memoryMB += MEMCHECK_MEMORY_OVERHEAD_MB
+	}
+
+	watchdogInput := WatchdogInput{
+		Command:        command,
+		StdinPath:      stdinPath,
+		StdoutPath:     path.Join(OUTPUT_DIR_IN_CONTAINER, stdoutFileName),
+		StderrPath:     path.Join(OUTPUT_DIR_IN_CONTAINER, stderrFileName),
+		TimeoutMS:      timeoutMS,
+		CPUTimeoutMS:   cpuTimeoutMS,
+		MemoryMB:       memoryMB,
+		UID:            uid,
+		GID:            gid,
+		StdoutMaxBytes: outputLimitBytes(job),
+		StderrMaxBytes: outputLimitBytes(job),
+		Env:            task.Env,
+	}
+	if task.Memcheck {
+		watchdogInput.ReportPath = path.Join(OUTPUT_DIR_IN_CONTAINER, memcheckFileName)
+	}
+
+	output, err := executor.execWatchdog(ctx, containerID, watchdogInput, workingDir)
+	if err != nil {
+		return result, err
+	}
+	result.Output = output
+
+	// Copy stdout and stderr files back to the result directory
+	for _, fileName := range []string{stdoutFileName, stderrFileName} {
+		if err := executor.CopyFileFromContainer(ctx, containerID, path.Join(OUTPUT_DIR_IN_CONTAINER, fileName), job.ResultDir); err != nil {
+			return result, err
+		}
+	}
+
+	result.StdoutPath = filepath.Join(job.ResultDir, stdoutFileName)
+	result.StderrPath = filepath.Join(job.ResultDir, stderrFileName)
+
+	if task.Memcheck {
+		if err := executor.CopyFileFromContainer(ctx, containerID, watchdogInput.ReportPath, job.ResultDir); err != nil {
+			return result, err
+		}
+		result.MemcheckPath = filepath.Join(job.ResultDir, memcheckFileName)
+	}
+
+	return result, nil
+}
+
+// Runs the watchdog in the container with the given input, and returns its output.
+// Outputs of the command stay in the container.
+// The command gets WATCHDOG_BASE_ENV with watchdogInput.Env added, while the watchdog runs with the environment of the container.
+// An error is returned only if the watchdog itself fails, i.e., the task should be marked as IE.
+func (executor *JobExecutor) execWatchdog(ctx context.Context, containerID string, watchdogInput WatchdogInput, workingDir string) (WatchdogOutput, error) {
+	output := WatchdogOutput{}
+
+	env := maps.Clone(WATCHDOG_BASE_ENV)
+	maps.Copy(env, watchdogInput.Env)
+	watchdogInput.Env = env
+
+	TotalTimeoutInSeconds := watchdogInput.TimeoutMS/1000 + 5 // add 5 seconds for overhead
+
+	// Convert watchdogInput to JSON string
+	watchdogInputJSON, err := json.Marshal(watchdogInput)
+	if err != nil {
+		return output, fmt.Errorf("failed to marshal watchdog input: %w", err)
+	}
+
+	execConfig := ExecConfig{
+		Cmd:              []string{"/home/watchdog"},
+		Stdin:            string(watchdogInputJSON),
+		WorkingDir:       workingDir,
+		TimeoutInSeconds: TotalTimeoutInSeconds,
+		User:             "root", // need root to run watchdog
+	}
+
+	execResult, err := executor.ExecuteCommand(ctx, containerID, execConfig)
+	if err != nil {
+		return output, fmt.Errorf("failed to execute command: %w", err)
+	}
+
+	if execResult.ExitCode != 0 {
+		// If the watchdog itself fails (e.g., due to OOM), return IE(Internal Error) status.
+		return output, fmt.Errorf("watchdog failed with exit code %d, stderr: %s", execResult.ExitCode, execResult.Stderr)
+	}
+
+	if execResult.Stderr != "" {
+		// If watchdog writes something to stderr, return IE(Internal Error) status.
+		return output, fmt.Errorf("watchdog wrote to stderr: %s", execResult.Stderr)
+	}
+
+	// parse execResult.Stdout as WatchdogOutput
+	if err = json.Unmarshal([]byte(execResult.Stdout), &output); err != nil {
+		return output, fmt.Errorf("failed to unmarshal watchdog output: %w", err)
+	}
+
+	if output.ExitCode == nil {
+		// If ExitCode is nil, it means the watchdog was terminated abnormally.
+		// In this case, there is a log message in watchdogOutput.Error,
+		return output, fmt.Errorf("watchdog terminated abnormally: %s", output.Error)
+	}
+
+	return output, nil
+}
+
+func (executor *JobExecutor) executeBuildTasks(ctx context.Context, job *model.JobDetail, volumeName, ioVolumeName, cpuSet string) ([]model.TaskLog, error) {
+	// Launch Sandbox Container to compile user codes
+	build_container_name := fmt.Sprintf("build-%s", uuid.New().String())
+
+	pidLimit := int64(256) // allow more processes for build tasks
+	buildContainerID, err := executor.startSandboxContainer(ctx, build_container_name, "checker-lang-gcc", job, volumeName, ioVolumeName, cpuSet, pidLimit, 768)
+	if err != nil {
+		return nil, err
+	}
+
+	defer executor.stopAndRemoveContainer(ctx, buildContainerID)
 
 	// ---------------------------------------------------------------------------
 	// Copy test files and user submitted files to the build container
-	// to /home/guest/, with guest:guest ownership
+	// to /home/guest/, with guest:guest ownership set in the tar archive
 	// ---------------------------------------------------------------------------
 
 	// Copy user submitted files
 	userSubmittedFolderPath := job.FileDir
-	err = executor.CopyContentsToContainer(ctx, userSubmittedFolderPath, buildContainer_createResponse.ID, "/home/guest/")
+	err = executor.CopyContentsToContainer(ctx, userSubmittedFolderPath, buildContainerID, "/home/guest/", GUEST_TAR_OPTIONS)
 	if err != nil {
 		return nil, err
 	}
@@ -177,21 +474,15 @@ func (executor *JobExecutor) executeBuildTasks(ctx context.Context, job *model.J
 	// Copy test files
 	for _, testFile := range job.TestFiles {
 		testFilePath := filepath.Join(job.ResourceDir, testFile)
-		err = executor.CopyContentsToContainer(ctx, testFilePath, buildContainer_createResponse.ID, "/home/guest/")
+		err = executor.CopyContentsToContainer(ctx, testFilePath, buildContainerID, "/home/guest/", GUEST_TAR_OPTIONS)
 		if err != nil {
 			return nil, err
 		}
 	}
 
-	// modify ownership of all files under /home/guest to guest:guest
-	res, err := executor.ExecuteSimpleCommand(ctx, buildContainer_createResponse.ID, []string{
-		"chown", "-R", "guest:guest", "/home/guest/",
-	})
-	if err != nil {
-		return nil, fmt.Errorf("failed to change ownership of /home/guest: %s, stderr: %s", err.Error(), res.Stderr)
-	}
-	if res.ExitCode != 0 {
-		return nil, fmt.Errorf("failed to change ownership of /home/guest, exit code: %d, stderr: %s", res.ExitCode, res.Stderr)
+	// Copy stdin files of build tasks
+	if err := executor.prepareIODirectory(ctx, buildContainerID, job, job.BuildTasks); err != nil {
+		return nil, err
 	}
 
 	buildLog := []model.TaskLog{}
@@ -206,99 +497,14 @@ func (executor *JobExecutor) executeBuildTasks(ctx context.Context, job *model.J
 			ExitCode:   -1,
 		}
 
-		// Read stdin from buildTask.StdinPath
-		stdinContent := []byte{}
-		if buildTask.StdinPath != "" {
-			stdinPath := filepath.Join(job.ResourceDir, buildTask.StdinPath)
-			stdinContent, err = os.ReadFile(stdinPath)
-			if err != nil {
-				return buildLog, fmt.Errorf("failed to read stdin file %s: %w", stdinPath, err)
-			}
-		}
-
-		TotalTimeoutInSeconds := job.TimeMS/1000 + 5 // add 5 seconds for overhead
-
-		watchdogInput := WatchdogInput{
-			Command:        buildTask.Command,
-			Stdin:          string(stdinContent),
-			TimeoutMS:      job.TimeMS,
-			MemoryMB:       job.MemoryMB,
-			UID:            UID_GUEST,
-			GID:            GID_GUEST,
-			StdoutMaxBytes: MAX_STDOUT_BYTES,
-			StderrMaxBytes: MAX_STDERR_BYTES,
-		}
-
-		// Convert watchdogInput to JSON string
-		watchdogInputJSON, err := json.Marshal(watchdogInput)
-		if err != nil {
-			return buildLog, fmt.Errorf("failed to marshal watchdog input: %w", err)
-		}
-
-		execConfig := ExecConfig{
-			Cmd:              []string{"/home/watchdog"},
-			Stdin:            string(watchdogInputJSON),
-			WorkingDir:       "/home/guest",
-			Env:              []string{},
-			TimeoutInSeconds: TotalTimeoutInSeconds,
-			User:             "root", // need root to run watchdog
-		}
-
-		execResult, err := executor.ExecuteCommand(ctx, buildContainer_createResponse.ID, execConfig)
+		execResult, err := executor.runWatchdog(ctx, buildContainerID, job, buildTask, fmt.Sprintf("build_%d", buildTask.ID), "/home/guest")
 		if err != nil {
 			// If some internal error occurs (not the command execution error),
 			// return ResultDetail with IE(Internal Error) status.
 			buildLog = append(buildLog, result)
-			return buildLog, fmt.Errorf("failed to execute command: %w", err)
-		}
-
-		if execResult.ExitCode != 0 {
-			buildLog = append(buildLog, result)
-			// If the watchdog itself fails (e.g., due to OOM), return IE(Internal Error) status.
-			return buildLog, fmt.Errorf("watchdog failed with exit code %d, stderr: %s", execResult.ExitCode, execResult.Stderr)
-		}
-
-		if execResult.Stderr != "" {
-			buildLog = append(buildLog, result)
-			// If watchdog writes something to stderr, return IE(Internal Error) status.
-			return buildLog, fmt.Errorf("watchdog wrote to stderr: %s", execResult.Stderr)
-		}
-
-		// parse execResult.Stdout as WatchdogOutput
-		var watchdogOutput WatchdogOutput
-		err = json.Unmarshal([]byte(execResult.Stdout), &watchdogOutput)
-		if err != nil {
-			buildLog = append(buildLog, result)
-			// If parsing watchdog output fails, return IE(Internal Error) status.
-			return buildLog, fmt.Errorf("failed to unmarshal watchdog output: %w", err)
-		}
-
-		// Save stdout and stderr to files
-		if err = os.MkdirAll(job.ResultDir, 0755); err != nil {
-			buildLog = append(buildLog, result)
-			return buildLog, fmt.Errorf("failed to create result directory %s: %w", job.ResultDir, err)
-		}
-
-		stdoutFilePath := filepath.Join(job.ResultDir, fmt.Sprintf("build_%d_stdout.txt", buildTask.ID))
-		err = os.WriteFile(stdoutFilePath, []byte(watchdogOutput.Stdout), 0644)
-		if err != nil {
-			buildLog = append(buildLog, result)
-			return buildLog, fmt.Errorf("failed to write stdout file %s: %w", stdoutFilePath, err)
-		}
-
-		stderrFilePath := filepath.Join(job.ResultDir, fmt.Sprintf("build_%d_stderr.txt", buildTask.ID))
-		err = os.WriteFile(stderrFilePath, []byte(watchdogOutput.Stderr), 0644)
-		if err != nil {
-			buildLog = append(buildLog, result)
-			return buildLog, fmt.Errorf("failed to write stderr file %s: %w", stderrFilePath, err)
-		}
-
-		if watchdogOutput.ExitCode == nil {
-			// If ExitCode is nil, it means the watchdog was terminated abnormally.
-			// In this case, there is a log message in watchdogOutput.stderr,
-			buildLog = append(buildLog, result)
-			return buildLog, fmt.Errorf("watchdog terminated abnormally, stderr: %s", watchdogOutput.Stderr)
+			return buildLog, fmt.Errorf("failed to execute build task %s: %w", buildTask.Title, err)
 		}
+		watchdogOutput := execResult.Output
 
 		// Determine result status
 		var resultStatus requeststatus.State = requeststatus.AC
@@ -327,10 +533,13 @@ func (executor *JobExecutor) executeBuildTasks(ctx context.Context, job *model.J
 			TestCaseID: buildTask.ID,
 			ResultID:   resultStatus,
 			TimeMS:     watchdogOutput.TimeMS,
+			CPUTimeMS:  watchdogOutput.CPUTimeMS,
 			MemoryKB:   watchdogOutput.MemoryKB,
 			ExitCode:   *watchdogOutput.ExitCode,
-			StdoutPath: stdoutFilePath,
-			StderrPath: stderrFilePath,
+			StdoutPath: execResult.StdoutPath,
+			StderrPath: execResult.StderrPath,
+			Truncated:  watchdogOutput.OLE,
+			Threads:    makeThreadLogs(watchdogOutput.Threads),
 		}
 		buildLog = append(buildLog, result)
 	}
@@ -338,91 +547,27 @@ func (executor *JobExecutor) executeBuildTasks(ctx context.Context, job *model.J
 	return buildLog, nil
 }
 
-func (executor *JobExecutor) executeJudgeTasks(ctx context.Context, job *model.JobDetail, volumeName string) ([]model.TaskLog, error) {
+func (executor *JobExecutor) executeJudgeTasks(ctx context.Context, job *model.JobDetail, volumeName, ioVolumeName, cpuSet string) ([]model.TaskLog, error) {
 	// Start Judge Container to run user program against test cases
 	judge_container_name := fmt.Sprintf("judge-%s", uuid.New().String())
 
-	cpuSet := CPU_SET
-	timeout := TIMEOUT_BEFORE_CONTAINER_STOP
-	pidLimit := int64(PID_LIMIT)
-	// add 32MB for overhead
-	totalMemoryInBytes := min(
-		(job.MemoryMB+32)*1024*1024, MAX_MEMORY_LIMIT_MB*1024*1024)
-
-	judgeContainer_createResponse, err := executor.client.ContainerCreate(ctx,
-		&container.Config{
-			User:  "root",
-			Cmd:   []string{"/bin/sh", "-c", "sleep 3600"},
-			Image: "binary-runner",
-			Volumes: map[string]struct{}{
-				"/home/guest": {},
-			},
-			WorkingDir:      "/home/guest",
-			NetworkDisabled: true,
-			StopTimeout:     &timeout,
-		},
-		&container.HostConfig{
-			Binds: []string{fmt.Sprintf("%s:/home/guest", volumeName)},
-			Resources: container.Resources{
-				CpusetCpus: cpuSet, // only 1 CPU core can be used.
-				Memory:     totalMemoryInBytes,
-				MemorySwap: totalMemoryInBytes, // disable swap
-				PidsLimit:  &pidLimit,          // limit max number of processes available to spawn
-				Ulimits: []*container.Ulimit{
-					{
-						Name: "nofile", // limit max number of open files
-						Hard: 128,
-						Soft: 128,
-					},
-					{
-						Name: "nproc", // limit max number of processes
-						Hard: pidLimit,
-						Soft: pidLimit,
-					},
-					{
-						Name: "fsize",          // limit max size of files that can be created, the unit is bytes
-						Hard: 10 * 1024 * 1024, // 10 MB
-						Soft: 10 * 1024 * 1024, // 10 MB
-					},
-					{
-						Name: "stack",           // limit max stack size, the unit is bytes
-						Hard: (8 * 1024 * 1024), // 8 MB
-						Soft: (8 * 1024 * 1024), // 8 MB
-					},
-				},
-			},
-			// TODO: try this to check whether this works or not.
-			// StorageOpt: map[string]string{
-			// 	"size": "256m", // limit container writable layer size
-			// },
-		},
-		nil,
-		nil,
-		judge_container_name,
-	)
-
-	if judgeContainer_createResponse.Warnings != nil {
-		for _, warning := range judgeContainer_createResponse.Warnings {
-			fmt.Printf("Docker Warning: %s\n", warning)
-		}
+	// Leave room in the container for Valgrind of memcheck tasks
+	containerJob := *job
+	if hasMemcheckTasks(job) {
+		containerJob.MemoryMB += MEMCHECK_MEMORY_OVERHEAD_MB
 	}
 
+	judgeContainerID, err := executor.startSandboxContainer(ctx, judge_container_name, "binary-runner", &containerJob, volumeName, ioVolumeName, cpuSet, PID_LIMIT, 128)
 	if err != nil {
 		return nil, err
 	}
 
-	defer executor.RemoveContainer(ctx, judgeContainer_createResponse.ID)
+	defer executor.stopAndRemoveContainer(ctx, judgeContainerID)
 
-	// Start the judge container
-	err = executor.client.ContainerStart(ctx, judgeContainer_createResponse.ID, container.StartOptions{})
-	if err != nil {
+	// Copy stdin files of judge tasks
+	if err := executor.prepareIODirectory(ctx, judgeContainerID, job, job.JudgeTasks); err != nil {
 		return nil, err
 	}
-	timeoutBeforeStop := 0
-	defer executor.client.ContainerStop(ctx, judgeContainer_createResponse.ID, container.StopOptions{
-		Signal:  "SIGKILL",
-		Timeout: &timeoutBeforeStop, // do not wait before killing the container
-	})
 
 	judgeLog := []model.TaskLog{}
 
@@ -436,176 +581,346 @@ func (executor *JobExecutor) executeJudgeTasks(ctx context.Context, job *model.J
 			ExitCode:   -1,
 		}
 
-		// Read stdin from judgeTask.StdinPath
-		stdinContent := []byte{}
-		if judgeTask.StdinPath != "" {
-			stdinPath := filepath.Join(job.ResourceDir, judgeTask.StdinPath)
-			stdinContent, err = os.ReadFile(stdinPath)
-			if err != nil {
-				return judgeLog, fmt.Errorf("failed to read stdin file %s: %w", stdinPath, err)
-			}
-		}
-
-		// Read expected stdout and stderr if specified
+		// Read expected stdout and stderr if specified.
+		// If the job generates expected outputs, missing ones are generated from the first successful run.
 		expectedStdoutContent := []byte{}
 		expectedStderrContent := []byte{}
+		generateStdout := false
+		generateStderr := false
 		if judgeTask.StdoutPath != "" {
 			expectedStdoutPath := filepath.Join(job.ResourceDir, judgeTask.StdoutPath)
 			expectedStdoutContent, err = os.ReadFile(expectedStdoutPath)
-			if err != nil {
+			if job.GenerateOutputs && os.IsNotExist(err) {
+				generateStdout = true
+			} else if err != nil {
 				return judgeLog, fmt.Errorf("failed to read expected stdout file %s: %w", expectedStdoutPath, err)
 			}
 		}
 		if judgeTask.StderrPath != "" {
 			expectedStderrPath := filepath.Join(job.ResourceDir, judgeTask.StderrPath)
 			expectedStderrContent, err = os.ReadFile(expectedStderrPath)
-			if err != nil {
+			if job.GenerateOutputs && os.IsNotExist(err) {
+				generateStderr = true
+			} else if err != nil {
 				return judgeLog, fmt.Errorf("failed to read expected stderr file %s: %w", expectedStderrPath, err)
 			}
 		}
 
-		TotalTimeoutInSeconds := job.TimeMS/1000 + 5 // add 5 seconds for overhead
+		// Run the task repeatedly if requested, to detect flaky verdicts
+		repeat := repeatCount(job, judgeTask)
+		runs := []model.RunLog{}
+		for run := int64(1); run <= repeat; run++ {
+			outputName := fmt.Sprintf("judge_%d", judgeTask.ID)
+			if repeat > 1 {
+				outputName = fmt.Sprintf("judge_%d_run%d", judgeTask.ID, run)
+			}
 
-		watchdogInput := WatchdogInput{
-			Command:        judgeTask.Command,
-			Stdin:          string(stdinContent),
-			TimeoutMS:      job.TimeMS,
-			MemoryMB:       job.MemoryMB,
-			UID:            UID_GUEST,
-			GID:            GID_GUEST,
-			StdoutMaxBytes: MAX_STDOUT_BYTES,
-			StderrMaxBytes: MAX_STDERR_BYTES,
-		}
+			// Outputs to be generated are not compared
+			runTask := judgeTask
+			if generateStdout {
+				runTask.StdoutPath = ""
+			}
+			if generateStderr {
+				runTask.StderrPath = ""
+			}
 
-		// Convert watchdogInput to JSON string
-		watchdogInputJSON, err := json.Marshal(watchdogInput)
-		if err != nil {
-			return judgeLog, fmt.Errorf("failed to marshal watchdog input: %w", err)
+			runLog, err := executor.runJudgeTask(ctx, judgeContainerID, job, runTask, outputName, expectedStdoutContent, expectedStderrContent)
+			if err != nil {
+				judgeLog = append(judgeLog, result)
+				return judgeLog, err
+			}
+			runs = append(runs, runLog)
+
+			// Only outputs of a successful run are trusted as expected outputs.
+			// Later runs are compared against them, which also checks that the outputs are deterministic.
+			if runLog.ResultID == requeststatus.AC && generateStdout {
+				expectedStdoutContent, err = generateExpectedOutput(runLog.StdoutPath, filepath.Join(job.ResourceDir, judgeTask.StdoutPath))
+				if err != nil {
+					judgeLog = append(judgeLog, result)
+					return judgeLog, err
+				}
+				generateStdout = false
+				result.Generated = append(result.Generated, judgeTask.StdoutPath)
+			}
+			if runLog.ResultID == requeststatus.AC && generateStderr {
+				expectedStderrContent, err = generateExpectedOutput(runLog.StderrPath, filepath.Join(job.ResourceDir, judgeTask.StderrPath))
+				if err != nil {
+					judgeLog = append(judgeLog, result)
+					return judgeLog, err
+				}
+				generateStderr = false
+				result.Generated = append(result.Generated, judgeTask.StderrPath)
+			}
 		}
 
-		execConfig := ExecConfig{
-			Cmd:              []string{"/home/watchdog"},
-			Stdin:            string(watchdogInputJSON),
-			WorkingDir:       "/home/guest",
-			Env:              []string{},
-			TimeoutInSeconds: TotalTimeoutInSeconds,
-			User:             "root", // need root to run watchdog
-		}
+		// Append to judgeLog
+		result.ConstructFromRuns(runs)
+		judgeLog = append(judgeLog, result)
+	}
 
-		execResult, err := executor.ExecuteCommand(ctx, judgeContainer_createResponse.ID, execConfig)
-		if err != nil {
-			// If some internal error occurs (not the command execution error),
-			// return ResultDetail with IE(Internal Error) status.
-			judgeLog = append(judgeLog, result)
-			return judgeLog, fmt.Errorf("failed to execute judge task %s: %w", judgeTask.Title, err)
-		}
+	return judgeLog, nil
+}
 
-		if execResult.Stderr != "" {
-			// If watchdog writes something to stderr, return IE(Internal Error) status.
-			judgeLog = append(judgeLog, result)
-			return judgeLog, fmt.Errorf("watchdog wrote to stderr: %s", execResult.Stderr)
-		}
+// Runs a judge task once, and returns the verdict of the run.
+// outputName is used to name the stdout/stderr files stored in the result directory.
+func (executor *JobExecutor) runJudgeTask(ctx context.Context, containerID string, job *model.JobDetail, judgeTask model.TestCase, outputName string, expectedStdoutContent, expectedStderrContent []byte) (model.RunLog, error) {
+	var err error
 
-		// parse execResult.Stdout as WatchdogOutput
-		var watchdogOutput WatchdogOutput
-		err = json.Unmarshal([]byte(execResult.Stdout), &watchdogOutput)
+	// Copy fixtures into a fresh working directory for this run only
+	workingDir := "/home/guest"
+	if judgeTask.FixturesPath != "" {
+		workingDir = fmt.Sprintf("/tmp/task-%d", judgeTask.ID)
+		err = executor.prepareWorkingDirectory(ctx, containerID, workingDir, filepath.Join(job.ResourceDir, judgeTask.FixturesPath))
 		if err != nil {
-			judgeLog = append(judgeLog, result)
-			return judgeLog, fmt.Errorf("failed to unmarshal watchdog output: %w", err)
+			return model.RunLog{}, fmt.Errorf("failed to prepare fixtures of judge task %s: %w", judgeTask.Title, err)
 		}
+	}
 
-		// Save stdout and stderr to files
-		if err = os.MkdirAll(job.ResultDir, 0755); err != nil {
-			judgeLog = append(judgeLog, result)
-			return judgeLog, fmt.Errorf("failed to create result directory %s: %w", job.ResultDir, err)
-		}
+	// Multi-process tasks run their processes first, then the command checks their results
+	var processLogs []model.ProcessLog
+	checkerTask := judgeTask
+	if len(judgeTask.Processes) > 0 {
+		processLogs, checkerTask, err = executor.runProcesses(ctx, containerID, job, judgeTask, outputName, workingDir)
+	}
 
-		stdoutFilePath := filepath.Join(job.ResultDir, fmt.Sprintf("judge_%d_stdout.txt", judgeTask.ID))
-		err = os.WriteFile(stdoutFilePath, []byte(watchdogOutput.Stdout), 0644)
-		if err != nil {
-			judgeLog = append(judgeLog, result)
-			return judgeLog, fmt.Errorf("failed to write stdout file %s: %w", stdoutFilePath, err)
+	var execResult watchdogResult
+	if err == nil && len(judgeTask.Processes) > 0 {
+		// The checker is a part of the problem, so it runs as root from a copy the processes cannot touch
+		err = executor.prepareTrustedDirectory(ctx, containerID, job, judgeTask.FixturesPath)
+		if err == nil {
+			execResult, err = executor.runWatchdogAs(ctx, containerID, job, checkerTask, outputName, TRUSTED_DIR_IN_CONTAINER, UID_ROOT, GID_ROOT)
 		}
+	} else if err == nil {
+		execResult, err = executor.runWatchdog(ctx, containerID, job, checkerTask, outputName, workingDir)
+	}
 
-		stderrFilePath := filepath.Join(job.ResultDir, fmt.Sprintf("judge_%d_stderr.txt", judgeTask.ID))
-		err = os.WriteFile(stderrFilePath, []byte(watchdogOutput.Stderr), 0644)
-		if err != nil {
-			judgeLog = append(judgeLog, result)
-			return judgeLog, fmt.Errorf("failed to write stderr file %s: %w", stderrFilePath, err)
+	if workingDir != "/home/guest" {
+		// Remove the working directory so that files created in it do not leak into other tasks
+		if res, rmErr := executor.ExecuteSimpleCommand(ctx, containerID, []string{
+			"rm", "-rf", workingDir,
+		}); rmErr != nil {
+			return model.RunLog{}, fmt.Errorf("failed to remove working directory %s: %s, stderr: %s", workingDir, rmErr.Error(), res.Stderr)
 		}
-
-		if watchdogOutput.ExitCode == nil {
-			// If ExitCode is nil, it means the watchdog was terminated abnormally.
-			// In this case, there is a log message in watchdogOutput.stderr,
-			judgeLog = append(judgeLog, result)
-			return judgeLog, fmt.Errorf("watchdog terminated abnormally: %s", watchdogOutput.Stderr)
+	}
+	if len(judgeTask.Processes) > 0 {
+		if res, rmErr := executor.ExecuteSimpleCommand(ctx, containerID, []string{
+			"rm", "-rf", processDir(judgeTask), TRUSTED_DIR_IN_CONTAINER,
+		}); rmErr != nil {
+			return model.RunLog{}, fmt.Errorf("failed to remove results of processes: %s, stderr: %s", rmErr.Error(), res.Stderr)
 		}
+	}
 
-		// Determine result status
-		var resultStatus requeststatus.State = requeststatus.AC
+	if err != nil {
+		// If some internal error occurs (not the command execution error),
+		// the caller marks the task as IE(Internal Error).
+		return model.RunLog{}, fmt.Errorf("failed to execute judge task %s: %w", judgeTask.Title, err)
+	}
+	watchdogOutput := execResult.Output
 
-		if watchdogOutput.OLE {
-			resultStatus = resultStatus.Max(requeststatus.OLE)
-		}
-		if watchdogOutput.MLE {
-			resultStatus = resultStatus.Max(requeststatus.MLE)
+	// Determine result status
+	var resultStatus requeststatus.State = requeststatus.AC
+
+	if watchdogOutput.OLE {
+		resultStatus = resultStatus.Max(requeststatus.OLE)
+	}
+	if watchdogOutput.MLE {
+		resultStatus = resultStatus.Max(requeststatus.MLE)
+	}
+	if watchdogOutput.TLE {
+		resultStatus = resultStatus.Max(requeststatus.TLE)
+	}
+
+	if !judgeTask.IgnoreExit && judgeTask.ExitCode == 0 && *watchdogOutput.ExitCode != 0 {
+		// If the expected exit code is 0 (successful execution), but the actual exit code is not 0, mark it as RE (Runtime Error)
+		resultStatus = resultStatus.Max(requeststatus.RE)
+	}
+	if !judgeTask.IgnoreExit && judgeTask.ExitCode != 0 && *watchdogOutput.ExitCode == 0 {
+		// Expected non-zero exit code (expected failure), but the actual exit code is 0, mark it as WA (Wrong Answer)
+		resultStatus = resultStatus.Max(requeststatus.WA)
+	}
+
+	// Check stdout and stderr if expected files are provided
+
+	if judgeTask.StdoutPath != "" {
+		stdoutContent, err := os.ReadFile(execResult.StdoutPath)
+		if err != nil {
+			return model.RunLog{}, fmt.Errorf("failed to read stdout file %s: %w", execResult.StdoutPath, err)
 		}
-		if watchdogOutput.TLE {
-			resultStatus = resultStatus.Max(requeststatus.TLE)
+		if !match.Match(string(expectedStdoutContent), string(stdoutContent)) {
+			resultStatus = resultStatus.Max(requeststatus.WA)
 		}
+	}
 
-		if !judgeTask.IgnoreExit && judgeTask.ExitCode == 0 && *watchdogOutput.ExitCode != 0 {
-			// If the expected exit code is 0 (successful execution), but the actual exit code is not 0, mark it as RE (Runtime Error)
-			resultStatus = resultStatus.Max(requeststatus.RE)
+	if judgeTask.StderrPath != "" {
+		stderrContent, err := os.ReadFile(execResult.StderrPath)
+		if err != nil {
+			return model.RunLog{}, fmt.Errorf("failed to read stderr file %s: %w", execResult.StderrPath, err)
 		}
-		if !judgeTask.IgnoreExit && judgeTask.ExitCode != 0 && *watchdogOutput.ExitCode == 0 {
-			// Expected non-zero exit code (expected failure), but the actual exit code is 0, mark it as WA (Wrong Answer)
+		if !match.Match(string(expectedStderrContent), string(stderrContent)) {
 			resultStatus = resultStatus.Max(requeststatus.WA)
 		}
+	}
 
-		// Check stdout and stderr if expected files are provided
-
-		if judgeTask.StdoutPath != "" {
-			if !match.Match(string(expectedStdoutContent), watchdogOutput.Stdout) {
-				resultStatus = resultStatus.Max(requeststatus.WA)
-			}
+	// Check errors found by Valgrind.
+	// Invalid memory accesses always fail the task, and leaks fail it depending on the leak policy.
+	var findings []model.MemcheckFinding
+	if judgeTask.Memcheck {
+		findings, err = memcheck.ParseFile(execResult.MemcheckPath)
+		if err != nil {
+			// The report is cut off if the program was killed, which already fails the task.
+			// Otherwise the program broke Valgrind, e.g., by corrupting its memory.
+			resultStatus = resultStatus.Max(requeststatus.RE)
 		}
-
-		if judgeTask.StderrPath != "" {
-			if !match.Match(string(expectedStderrContent), watchdogOutput.Stderr) {
-				resultStatus = resultStatus.Max(requeststatus.WA)
+		for _, finding := range findings {
+			if !finding.Leak || job.LeakPolicy.FailsOnLeak() {
+				resultStatus = resultStatus.Max(requeststatus.RE)
 			}
 		}
+	}
 
-		// Append to judgeLog
-		result = model.TaskLog{
-			TestCaseID: judgeTask.ID,
-			ResultID:   resultStatus,
-			TimeMS:     watchdogOutput.TimeMS,
-			MemoryKB:   watchdogOutput.MemoryKB,
-			ExitCode:   *watchdogOutput.ExitCode,
-			StdoutPath: stdoutFilePath,
-			StderrPath: stderrFilePath,
-		}
-		judgeLog = append(judgeLog, result)
+	// The task also fails if some of its processes failed,
+	// and takes as long as the slowest of them and the checker
+	timeMS := watchdogOutput.TimeMS
+	cpuTimeMS := watchdogOutput.CPUTimeMS
+	memoryKB := watchdogOutput.MemoryKB
+	truncated := watchdogOutput.OLE
+	for _, processLog := range processLogs {
+		resultStatus = resultStatus.Max(processLog.ResultID)
+		timeMS = max(timeMS, processLog.TimeMS)
+		cpuTimeMS = max(cpuTimeMS, processLog.CPUTimeMS)
+		memoryKB = max(memoryKB, processLog.MemoryKB)
+		truncated = truncated || processLog.Truncated
 	}
 
-	return judgeLog, nil
+	return model.RunLog{
+		ResultID:   resultStatus,
+		TimeMS:     timeMS,
+		CPUTimeMS:  cpuTimeMS,
+		MemoryKB:   memoryKB,
+		ExitCode:   *watchdogOutput.ExitCode,
+		StdoutPath: execResult.StdoutPath,
+		StderrPath: execResult.StderrPath,
+		Truncated:  truncated,
+		Memcheck:   findings,
+		Processes:  processLogs,
+		Threads:    makeThreadLogs(watchdogOutput.Threads),
+	}, nil
+}
+
+// Reports whether some judge task of the job is run under Valgrind memcheck.
+func hasMemcheckTasks(job *model.JobDetail) bool {
+	return slices.ContainsFunc(job.JudgeTasks, func(task model.TestCase) bool {
+		return task.Memcheck
+	})
+}
+
+// Returns how many times the judge task is run in the job.
+// The repeat count of the job takes precedence over that of the task.
+func repeatCount(job *model.JobDetail, task model.TestCase) int64 {
+	repeat := task.Repeat
+	if job.Repeat > 0 {
+		repeat = job.Repeat
+	}
+	return min(max(repeat, 1), MAX_REPEAT_COUNT)
 }
 
-// Copy file (or directory) from host to container
-func (executor *JobExecutor) CopyContentsToContainer(ctx context.Context, srcInHost, containerID, dstInContainer string) error {
-	// Create tar archive from source path
-	tarReader, err := util.CreateTarArchive(srcInHost)
+// Copies the actual output of a task in srcPath to dstPath as an expected output,
+// and returns its content.
+func generateExpectedOutput(srcPath, dstPath string) ([]byte, error) {
+	content, err := os.ReadFile(srcPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read output file %s: %w", srcPath, err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dstPath), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directory of expected output %s: %w", dstPath, err)
+	}
+
+	if err := os.WriteFile(dstPath, content, 0644); err != nil {
+		return nil, fmt.Errorf("failed to write expected output %s: %w", dstPath, err)
+	}
+
+	return content, nil
+}
+
+// Returns the max size of stdout/stderr kept for each task of the job.
+func outputLimitBytes(job *model.JobDetail) int64 {
+	if job.OutputLimitKB <= 0 {
+		return DEFAULT_OUTPUT_LIMIT_BYTES
+	}
+	return min(job.OutputLimitKB*1024, MAX_OUTPUT_LIMIT_BYTES)
+}
+
+// Returns the command line of the task, with its arguments quoted and appended.
+func taskCommand(task model.TestCase) string {
+	command := task.Command
+	for _, arg := range task.Args {
+		command += " " + util.ShellQuote(arg)
+	}
+	return command
+}
+
+// Returns the environment variables of the task in "KEY=VALUE" form, sorted by key,
+// for commands run directly as guest, e.g., readiness checks.
+func taskEnv(task model.TestCase) []string {
+	env := []string{}
+	for _, key := range slices.Sorted(maps.Keys(task.Env)) {
+		env = append(env, key+"="+task.Env[key])
+	}
+	return env
+}
+
+// Creates a fresh working directory in the container, which contains a copy of /home/guest
+// and the contents of srcInHost (e.g., fixtures of a task), owned by guest:guest.
+// Files in srcInHost overwrite those of the same name in /home/guest.
+func (executor *JobExecutor) prepareWorkingDirectory(ctx context.Context, containerID, workingDir, srcInHost string) error {
+	res, err := executor.ExecuteSimpleCommand(ctx, containerID, []string{
+		"sh", "-c", fmt.Sprintf("rm -rf %[1]s && mkdir -p %[1]s && chown guest:guest %[1]s && cp -a /home/guest/. %[1]s/", workingDir),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create working directory: %s, stderr: %s", err.Error(), res.Stderr)
+	}
+
+	return executor.CopyContentsToContainer(ctx, srcInHost, containerID, workingDir, GUEST_TAR_OPTIONS)
+}
+
+// Creates TRUSTED_DIR_IN_CONTAINER from the test files and the fixtures (if any) in the host, owned by root.
+// Files in the job volume are not used, since guest may have replaced them.
+func (executor *JobExecutor) prepareTrustedDirectory(ctx context.Context, containerID string, job *model.JobDetail, fixturesPath string) error {
+	res, err := executor.ExecuteSimpleCommand(ctx, containerID, []string{
+		"sh", "-c", fmt.Sprintf("rm -rf %[1]s && mkdir -p %[1]s && chmod 700 %[1]s", TRUSTED_DIR_IN_CONTAINER),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create trusted directory: %s, stderr: %s", err.Error(), res.Stderr)
+	}
+
+	for _, testFile := range job.TestFiles {
+		if err := executor.CopyContentsToContainer(ctx, filepath.Join(job.ResourceDir, testFile), containerID, TRUSTED_DIR_IN_CONTAINER, TRUSTED_TAR_OPTIONS); err != nil {
+			return err
+		}
+	}
+	if fixturesPath != "" {
+		if err := executor.CopyContentsToContainer(ctx, filepath.Join(job.ResourceDir, fixturesPath), containerID, TRUSTED_DIR_IN_CONTAINER, TRUSTED_TAR_OPTIONS); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Copy file (or directory) from host to container, with the ownership and permissions given by opts
+func (executor *JobExecutor) CopyContentsToContainer(ctx context.Context, srcInHost, containerID, dstInContainer string, opts util.TarOptions) error {
+	// Create tar archive from source path, which is streamed while copying
+	tarReader, err := util.CreateTarArchive(srcInHost, opts)
 	if err != nil {
 		return fmt.Errorf("failed to create tar archive: %w", err)
 	}
+	defer tarReader.Close()
 
 	// Copy tar archive to container
 	err = executor.client.CopyToContainer(ctx, containerID, dstInContainer, tarReader, container.CopyToContainerOptions{
 		// it will be an error if unpacking the given content would cause an existing directory to be replaced with a non-directory and vice versa.
 		AllowOverwriteDirWithFile: false,
-		CopyUIDGID:                false,
+		CopyUIDGID:                true, // keep the ownership set in the tar headers
 	})
 
 	if err != nil {
@@ -615,6 +930,21 @@ func (executor *JobExecutor) CopyContentsToContainer(ctx context.Context, srcInH
 	return nil
 }
 
+// Copy a file from container into the directory in host
+func (executor *JobExecutor) CopyFileFromContainer(ctx context.Context, containerID, srcInContainer, dstDirInHost string) error {
+	tarReader, _, err := executor.client.CopyFromContainer(ctx, containerID, srcInContainer)
+	if err != nil {
+		return fmt.Errorf("failed to copy %s from container: %w", srcInContainer, err)
+	}
+	defer tarReader.Close()
+
+	if err := util.ExtractTarArchive(tarReader, dstDirInHost); err != nil {
+		return fmt.Errorf("failed to extract %s: %w", srcInContainer, err)
+	}
+
+	return nil
+}
+
 type ExecConfig struct {
 	Cmd              []string
 	Stdin            string
@@ -749,6 +1079,25 @@ func (executor *JobExecutor) CheckImageExists(ctx context.Context, imageName str
 	return true, nil
 }
 
+// LoadImageDigest computes a digest of the given sandbox images from their IDs,
+// which changes whenever some image is rebuilt. Results of jobs are cached only with the same digest.
+func (executor *JobExecutor) LoadImageDigest(ctx context.Context, imageNames ...string) error {
+	hash := sha256.New()
+	for _, imageName := range imageNames {
+		inspect, err := executor.client.ImageInspect(ctx, imageName)
+		if err != nil {
+			return fmt.Errorf("failed to inspect image %s: %w", imageName, err)
+		}
+		fmt.Fprintf(hash, "%s=%s\n", imageName, inspect.ID)
+	}
+	executor.imageDigest = hex.EncodeToString(hash.Sum(nil))
+	return nil
+}
+
+func (executor *JobExecutor) ImageDigest() string {
+	return executor.imageDigest
+}
+
 func (executor *JobExecutor) Close() error {
 	return executor.client.Close()
 }
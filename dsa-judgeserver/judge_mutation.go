@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/dsa-uts/dsa-project/database/model"
+	"github.com/dsa-uts/dsa-project/database/model/requeststatus"
+	"github.com/google/uuid"
+)
+
+// Runs mutation tasks of the job in a new build container, since the test suite is
+// built together with each implementation.
+// The test suite must pass on the reference implementation, and should fail on every mutant.
+func (executor *JobExecutor) executeMutationTasks(ctx context.Context, job *model.JobDetail, volumeName, ioVolumeName, cpuSet string) ([]model.MutationLog, error) {
+	mutation_container_name := fmt.Sprintf("mutation-%s", uuid.New().String())
+
+	pidLimit := int64(256) // allow more processes to build the test suite
+	containerID, err := executor.startSandboxContainer(ctx, mutation_container_name, "checker-lang-gcc", job, volumeName, ioVolumeName, cpuSet, pidLimit, 768)
+	if err != nil {
+		return nil, err
+	}
+
+	defer executor.stopAndRemoveContainer(ctx, containerID)
+
+	if err := executor.prepareIODirectory(ctx, containerID, job, nil); err != nil {
+		return nil, err
+	}
+
+	mutationLogs := []model.MutationLog{}
+
+	for _, task := range job.MutationTasks {
+		mutationLog := model.MutationLog{
+			TaskID:   task.ID,
+			ResultID: requeststatus.IE,
+			Mutants:  []model.MutationRun{},
+			Survived: []string{},
+		}
+
+		reference, err := executor.runMutation(ctx, containerID, job, task, task.ReferencePath, fmt.Sprintf("mutation_%d_reference", task.ID))
+		if err != nil {
+			mutationLogs = append(mutationLogs, mutationLog)
+			return mutationLogs, fmt.Errorf("failed to run mutation task %s on the reference implementation: %w", task.Title, err)
+		}
+		mutationLog.Reference = reference
+		mutationLog.ResultID = reference.ResultID
+
+		if reference.ResultID != requeststatus.AC {
+			// The test suite rejects a correct implementation, so killing mutants means nothing
+			mutationLogs = append(mutationLogs, mutationLog)
+			continue
+		}
+
+		killed := 0
+		for i, mutantPath := range task.MutantPaths {
+			mutant, err := executor.runMutation(ctx, containerID, job, task, mutantPath, fmt.Sprintf("mutation_%d_mutant%d", task.ID, i+1))
+			if err != nil {
+				mutationLog.ResultID = requeststatus.IE
+				mutationLogs = append(mutationLogs, mutationLog)
+				return mutationLogs, fmt.Errorf("failed to run mutation task %s on mutant %s: %w", task.Title, mutantPath, err)
+			}
+
+			mutant.Killed = mutant.ResultID != requeststatus.AC
+			if mutant.Killed {
+				killed++
+			} else {
+				mutationLog.Survived = append(mutationLog.Survived, mutantPath)
+			}
+			mutationLog.Mutants = append(mutationLog.Mutants, mutant)
+		}
+
+		if len(task.MutantPaths) > 0 {
+			mutationLog.Score = float64(killed) / float64(len(task.MutantPaths))
+		}
+		if mutationLog.Score < task.MinScore {
+			mutationLog.ResultID = requeststatus.WA
+		}
+
+		mutationLogs = append(mutationLogs, mutationLog)
+	}
+
+	return mutationLogs, nil
+}
+
+// Runs the test suite against the implementation in implPath of resource files.
+// The implementation is copied over the submitted files in a fresh working directory,
+// so that the test suite is built with it.
+func (executor *JobExecutor) runMutation(ctx context.Context, containerID string, job *model.JobDetail, task model.MutationTask, implPath, outputName string) (model.MutationRun, error) {
+	workingDir := fmt.Sprintf("/tmp/mutation-%d", task.ID)
+	if err := executor.prepareWorkingDirectory(ctx, containerID, workingDir, filepath.Join(job.ResourceDir, implPath)); err != nil {
+		return model.MutationRun{}, err
+	}
+
+	// The time limit of the mutation task takes precedence over those of the problem
+	taskJob := *job
+	taskJob.TimeMS = task.TimeMS
+	taskJob.CPUTimeMS = 0
+
+	execResult, err := executor.runWatchdog(ctx, containerID, &taskJob, model.TestCase{
+		ID:      task.ID,
+		Title:   task.Title,
+		Command: task.Command,
+	}, outputName, workingDir)
+
+	// Remove the working directory so that builds of an implementation do not leak into the next one
+	if res, rmErr := executor.ExecuteSimpleCommand(ctx, containerID, []string{
+		"rm", "-rf", workingDir,
+	}); rmErr != nil {
+		return model.MutationRun{}, fmt.Errorf("failed to remove working directory %s: %s, stderr: %s", workingDir, rmErr.Error(), res.Stderr)
+	}
+
+	if err != nil {
+		return model.MutationRun{}, err
+	}
+	watchdogOutput := execResult.Output
+
+	var resultStatus requeststatus.State = requeststatus.AC
+
+	if watchdogOutput.OLE {
+		resultStatus = resultStatus.Max(requeststatus.OLE)
+	}
+	if watchdogOutput.MLE {
+		resultStatus = resultStatus.Max(requeststatus.MLE)
+	}
+	if watchdogOutput.TLE {
+		resultStatus = resultStatus.Max(requeststatus.TLE)
+	}
+	if *watchdogOutput.ExitCode != 0 {
+		// Some test of the test suite failed
+		resultStatus = resultStatus.Max(requeststatus.WA)
+	}
+
+	return model.MutationRun{
+		Name:       implPath,
+		ResultID:   resultStatus,
+		TimeMS:     watchdogOutput.TimeMS,
+		MemoryKB:   watchdogOutput.MemoryKB,
+		ExitCode:   *watchdogOutput.ExitCode,
+		StdoutPath: execResult.StdoutPath,
+		StderrPath: execResult.StderrPath,
+	}, nil
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"path"
+	"strings"
+	"time"
+
+	"dsa-judgeserver/util"
+
+	"github.com/dsa-uts/dsa-project/database/model"
+	"github.com/dsa-uts/dsa-project/database/model/requeststatus"
+)
+
+// Interval between readiness checks of a process
+const READY_CHECK_INTERVAL = 50 * time.Millisecond
+
+// Result of a process run by the watchdog in a goroutine
+type processResult struct {
+	index  int
+	result watchdogResult
+	err    error
+}
+
+// Returns the directory in the container where the results of the processes of the task
+// are passed to its checker. It is in the I/O directory, so that only root can access it.
+func processDir(task model.TestCase) string {
+	return path.Join(IO_DIR_IN_CONTAINER, fmt.Sprintf("processes-%d", task.ID))
+}
+
+// Runs the processes of a multi-process judge task at the same time, starting them in order.
+// Each process is started once the readiness check of the previous one passes,
+// and background processes are killed once all other processes have exited.
+// The results of the processes are put into processDir(task), and the returned task runs
+// the checker with its path in PROCESS_DIR, and the working directory of the processes in WORK_DIR.
+// The checker is run as root after all processes of guest are killed.
+// An error is returned only if the judge itself fails, i.e., the task should be marked as IE.
+func (executor *JobExecutor) runProcesses(ctx context.Context, containerID string, job *model.JobDetail, task model.TestCase, outputName, workingDir string) ([]model.ProcessLog, model.TestCase, error) {
+	processLogs := make([]model.ProcessLog, len(task.Processes))
+	for i, process := range task.Processes {
+		processLogs[i] = model.ProcessLog{
+			Name:     process.Name,
+			ResultID: requeststatus.IE,
+			ExitCode: -1,
+		}
+	}
+
+	done := make(chan processResult, len(task.Processes))
+	started := 0
+	foreground := 0
+	deadline := time.Now().Add(time.Duration(job.TimeMS) * time.Millisecond)
+
+	for i, process := range task.Processes {
+		processTask := model.TestCase{
+			ID:        task.ID,
+			Title:     task.Title,
+			Command:   process.Command,
+			StdinPath: process.StdinPath,
+			Env:       task.Env,
+		}
+		go func() {
+			result, err := executor.runWatchdog(ctx, containerID, job, processTask, outputName+"_"+process.Name, workingDir)
+			done <- processResult{index: i, result: result, err: err}
+		}()
+		started++
+		if !process.Background {
+			foreground++
+		}
+
+		if process.Ready == "" {
+			processLogs[i].Ready = true
+			continue
+		}
+
+		ready, err := executor.waitReady(ctx, containerID, process.Ready, taskEnv(task), workingDir, deadline)
+		if err != nil {
+			return processLogs, task, err
+		}
+		processLogs[i].Ready = ready
+		if !ready {
+			// Later processes would fail without this one, so they are not started
+			break
+		}
+	}
+
+	// Stop everything if some process did not get ready, since the others may wait for it forever
+	killed := false
+	if foreground == 0 || started < len(task.Processes) || !processLogs[started-1].Ready {
+		if err := executor.killGuestProcesses(ctx, containerID); err != nil {
+			return processLogs, task, err
+		}
+		killed = true
+	}
+
+	var firstErr error
+	for range started {
+		res := <-done
+		process := task.Processes[res.index]
+		if res.err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("failed to run process %s: %w", process.Name, res.err)
+			}
+		} else {
+			processLogs[res.index] = makeProcessLog(task, process, processLogs[res.index].Ready, res.result)
+		}
+
+		if !process.Background {
+			foreground--
+			if foreground == 0 && !killed {
+				if err := executor.killGuestProcesses(ctx, containerID); err != nil && firstErr == nil {
+					firstErr = err
+				}
+				killed = true
+			}
+		}
+	}
+	if firstErr != nil {
+		return processLogs, task, firstErr
+	}
+
+	// Pass the outputs and exit codes of the processes to the checker
+	dir := processDir(task)
+	commands := []string{
+		fmt.Sprintf("rm -rf %[1]s && mkdir -p %[1]s", util.ShellQuote(dir)),
+	}
+	for i, process := range task.Processes[:started] {
+		prefix := util.ShellQuote(dir + "/" + process.Name)
+		commands = append(commands,
+			fmt.Sprintf("cp %s %s.stdout", util.ShellQuote(OUTPUT_DIR_IN_CONTAINER+"/"+outputName+"_"+process.Name+"_stdout.txt"), prefix),
+			fmt.Sprintf("cp %s %s.stderr", util.ShellQuote(OUTPUT_DIR_IN_CONTAINER+"/"+outputName+"_"+process.Name+"_stderr.txt"), prefix),
+			fmt.Sprintf("echo %d > %s.exit", processLogs[i].ExitCode, prefix),
+		)
+	}
+	if res, err := executor.ExecuteSimpleCommand(ctx, containerID, []string{
+		"sh", "-c", strings.Join(commands, " && "),
+	}); err != nil {
+		return processLogs, task, fmt.Errorf("failed to collect results of processes: %s, stderr: %s", err.Error(), res.Stderr)
+	}
+
+	checkerTask := task
+	checkerTask.Env = maps.Clone(task.Env)
+	if checkerTask.Env == nil {
+		checkerTask.Env = map[string]string{}
+	}
+	checkerTask.Env["PROCESS_DIR"] = dir
+	checkerTask.Env["WORK_DIR"] = workingDir
+
+	// Processes not started have no results
+	return processLogs[:started], checkerTask, nil
+}
+
+// Returns the result of a process from the output of the watchdog.
+func makeProcessLog(task model.TestCase, process model.Process, ready bool, execResult watchdogResult) model.ProcessLog {
+	watchdogOutput := execResult.Output
+
+	var resultStatus requeststatus.State = requeststatus.AC
+	if watchdogOutput.OLE {
+		resultStatus = resultStatus.Max(requeststatus.OLE)
+	}
+	if watchdogOutput.MLE {
+		resultStatus = resultStatus.Max(requeststatus.MLE)
+	}
+	if watchdogOutput.TLE || !ready {
+		// A process that never gets ready is treated as too slow to start
+		resultStatus = resultStatus.Max(requeststatus.TLE)
+	}
+	if !process.Background && !task.IgnoreExit && *watchdogOutput.ExitCode != 0 {
+		// Background processes are killed, so their exit codes mean nothing
+		resultStatus = resultStatus.Max(requeststatus.RE)
+	}
+
+	return model.ProcessLog{
+		Name:       process.Name,
+		ResultID:   resultStatus,
+		Ready:      ready,
+		TimeMS:     watchdogOutput.TimeMS,
+		CPUTimeMS:  watchdogOutput.CPUTimeMS,
+		MemoryKB:   watchdogOutput.MemoryKB,
+		ExitCode:   *watchdogOutput.ExitCode,
+		StdoutPath: execResult.StdoutPath,
+		StderrPath: execResult.StderrPath,
+		Truncated:  watchdogOutput.OLE,
+		Threads:    makeThreadLogs(watchdogOutput.Threads),
+	}
+}
+
+// Runs the readiness check as guest until it exits with 0, or the deadline passes.
+// Returns whether the check has passed.
+func (executor *JobExecutor) waitReady(ctx context.Context, containerID, command string, env []string, workingDir string, deadline time.Time) (bool, error) {
+	for time.Now().Before(deadline) {
+		res, err := executor.ExecuteCommand(ctx, containerID, ExecConfig{
+			Cmd:              []string{"/bin/sh", "-c", command},
+			WorkingDir:       workingDir,
+			Env:              env,
+			TimeoutInSeconds: 1,
+			User:             fmt.Sprintf("%d:%d", UID_GUEST, GID_GUEST),
+		})
+		if err != nil && !res.TimeOut {
+			return false, fmt.Errorf("failed to run readiness check: %w", err)
+		}
+		if err == nil && res.ExitCode == 0 {
+			return true, nil
+		}
+		time.Sleep(READY_CHECK_INTERVAL)
+	}
+	return false, nil
+}
+
+// Kills all processes of guest in the container.
+// The watchdogs running them are not affected, since they run as root.
+func (executor *JobExecutor) killGuestProcesses(ctx context.Context, containerID string) error {
+	// kill -1 signals every process that guest can signal, except the shell itself.
+	// It fails if there is no such process, which is not an error here.
+	_, err := executor.ExecuteCommand(ctx, containerID, ExecConfig{
+		Cmd:              []string{"/bin/sh", "-c", "kill -KILL -1 2>/dev/null || true"},
+		TimeoutInSeconds: 30,
+		User:             fmt.Sprintf("%d:%d", UID_GUEST, GID_GUEST),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to kill processes of guest: %w", err)
+	}
+	return nil
+}
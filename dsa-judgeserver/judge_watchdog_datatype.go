@@ -1,36 +1,58 @@
 package main
 
+import "github.com/dsa-uts/dsa-project/database/model"
+
 type WatchdogInput struct {
 	Command        string `json:"command"`
-	Stdin          string `json:"stdin"`
-	TimeoutMS      int64  `json:"timeout_ms"`
+	StdinPath      string `json:"stdin_path,omitempty"`     // path in container, stdin is empty if omitted
+	StdoutPath     string `json:"stdout_path"`              // path in container
+	StderrPath     string `json:"stderr_path"`              // path in container
+	TimeoutMS      int64  `json:"timeout_ms"`               // limit of wall-clock time
+	CPUTimeoutMS   int64  `json:"cpu_timeout_ms,omitempty"` // limit of CPU time, not limited if omitted
 	MemoryMB       int64  `json:"memory_limit_mb"`
 	UID            int64  `json:"uid"`
 	GID            int64  `json:"gid"`
 	StdoutMaxBytes int64  `json:"stdout_max_bytes"`
 	StderrMaxBytes int64  `json:"stderr_max_bytes"`
-}
+	ReportPath     string `json:"report_path,omitempty"` // path in container, created by the watchdog and open as WATCHDOG_REPORT_FD in the command
 
-func NewWatchdogInput(command string, stdin string, timeoutMS int64, memoryMB int64, uid int64, gid int64, stdoutMaxBytes int64, stderrMaxBytes int64) WatchdogInput {
-	return WatchdogInput{
-		Command:        command,
-		Stdin:          stdin,
-		TimeoutMS:      timeoutMS,
-		MemoryMB:       memoryMB,
-		UID:            uid,
-		GID:            gid,
-		StdoutMaxBytes: stdoutMaxBytes,
-		StderrMaxBytes: stderrMaxBytes,
-	}
+	// Environment variables of the command, which does not inherit the ones of the watchdog
+	Env map[string]string `json:"env,omitempty"`
 }
 
+// File descriptor of WatchdogInput.ReportPath in the command.
+const WATCHDOG_REPORT_FD = 3
+
 type WatchdogOutput struct {
-	ExitCode *int64 `json:"exit_code"`
-	Stdout   string `json:"stdout"`
-	Stderr   string `json:"stderr"`
-	TimeMS   int64  `json:"time_ms"`
-	MemoryKB int64  `json:"memory_kb"`
-	TLE      bool   `json:"TLE"`
-	MLE      bool   `json:"MLE"`
-	OLE      bool   `json:"OLE"`
+	ExitCode  *int64           `json:"exit_code"`
+	Error     string           `json:"error"`       // error message if ExitCode is nil
+	TimeMS    int64            `json:"time_ms"`     // wall-clock time
+	CPUTimeMS int64            `json:"cpu_time_ms"` // CPU time summed over threads and processes
+	MemoryKB  int64            `json:"memory_kb"`
+	Threads   []WatchdogThread `json:"threads"` // threads of the command that used the CPU
+	TLE       bool             `json:"TLE"`     // either wall-clock time or CPU time exceeded the limit
+	MLE       bool             `json:"MLE"`
+	OLE       bool             `json:"OLE"`
+}
+
+type WatchdogThread struct {
+	TID       int64  `json:"tid"`
+	Name      string `json:"name"`
+	CPUTimeMS int64  `json:"cpu_time_ms"`
+}
+
+// Converts the threads reported by the watchdog into the log.
+func makeThreadLogs(threads []WatchdogThread) []model.ThreadLog {
+	if len(threads) == 0 {
+		return nil
+	}
+	logs := make([]model.ThreadLog, len(threads))
+	for i, thread := range threads {
+		logs[i] = model.ThreadLog{
+			TID:       thread.TID,
+			Name:      thread.Name,
+			CPUTimeMS: thread.CPUTimeMS,
+		}
+	}
+	return logs
 }
@@ -73,6 +73,9 @@ func (w *JobWorker) processJob(ctx context.Context, job *model.JobQueue) {
 		return
 	}
 
+	// Record the sandbox images, so that the result is cached only while they are in use
+	result.ImageDigest = w.executor.ImageDigest()
+
 	// Update job status to Done and insert result in a transaction
 	resultEntry := &model.ResultQueue{
 		JobID:     job.ID,
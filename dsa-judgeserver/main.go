@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"database/sql"
+	"flag"
 	"fmt"
 	"log"
 	"log/slog"
@@ -12,6 +13,8 @@ import (
 	"syscall"
 	"time"
 
+	"dsa-judgeserver/cpuset"
+
 	"github.com/dsa-uts/dsa-project/database"
 	"github.com/dsa-uts/dsa-project/database/model"
 	"github.com/uptrace/bun"
@@ -22,6 +25,11 @@ import (
 const NUM_WORKERS = 3
 
 func main() {
+	selfTestOnly := flag.Bool("selftest", false, "run the sandbox self-test suite and exit")
+	skipSelfTest := flag.Bool("skip-selftest", false, "accept jobs without running the sandbox self-test suite")
+	cpus := flag.String("cpus", "", "cores available to sandbox containers, e.g. \"0-3,6\" (default: the CPU affinity of this process)")
+	flag.Parse()
+
 	db_user := "dsa_app"
 	db_password := read_db_password()
 	// TODO: modify this for production
@@ -50,23 +58,21 @@ func main() {
 	textHandler := slog.NewTextHandler(os.Stdout, nil)
 	logger := slog.New(textHandler)
 
-	// Start background worker to reset stale jobs
-	go func() {
-		if err := ResetStaleJobs(ctx, jobQueueStore, logger); err != nil {
-			logger.Error("Error resetting stale jobs on startup", slog.String("error", err.Error()))
-		}
-
-		ticker := time.NewTicker(5 * time.Minute)
-		defer ticker.Stop()
-		for range ticker.C {
-			if err := ResetStaleJobs(ctx, jobQueueStore, logger); err != nil {
-				logger.Error("Error resetting stale jobs", slog.String("error", err.Error()))
-			}
-		}
-	}()
+	// Each job runs on cores of this pool, which are not shared with other jobs
+	cores, err := cpuset.Parse(*cpus)
+	if err != nil {
+		logger.Error("Failed to parse cores", slog.String("error", err.Error()))
+		return
+	}
+	cpuPool, err := cpuset.NewPool(cores)
+	if err != nil {
+		logger.Error("Failed to create cpu pool", slog.String("error", err.Error()))
+		return
+	}
+	logger.Info("Sandbox containers run on cores", slog.String("cpus", cpuset.Format(cores)))
 
 	// Create Docker Client
-	jobExecutor, err := NewJobExecutor()
+	jobExecutor, err := NewJobExecutor(cpuPool)
 	if err != nil {
 		logger.Error("Failed to create job executor", slog.String("error", err.Error()))
 		return
@@ -95,6 +101,49 @@ func main() {
 	}
 	logger.Info("Docker image 'binary-runners' exists.")
 
+	// Run hostile programs through the sandbox before accepting any job
+	if *selfTestOnly || !*skipSelfTest {
+		logger.Info("Running sandbox self-test...")
+		if err := RunSelfTest(ctx, jobExecutor, logger); err != nil {
+			// Exit with failure so that supervisors do not regard it as a clean stop
+			logger.Error("Sandbox self-test failed", slog.String("error", err.Error()))
+			os.Exit(1)
+		}
+		logger.Info("Sandbox self-test passed.")
+	}
+
+	if *selfTestOnly {
+		return
+	}
+
+	// Results cached with other sandbox images are not reused, but they are kept,
+	// since other judge servers may still run with those images
+	if err := jobExecutor.LoadImageDigest(ctx, "checker-lang-gcc", "binary-runner"); err != nil {
+		logger.Error("Failed to compute image digest", slog.String("error", err.Error()))
+		return
+	}
+	resultCacheStore := database.NewResultCacheStore(db)
+	if err := resultCacheStore.RegisterSandboxImage(ctx, jobExecutor.ImageDigest()); err != nil {
+		logger.Error("Failed to register sandbox image digest", slog.String("error", err.Error()))
+		return
+	}
+	logger.Info("Registered sandbox image digest", slog.String("digest", jobExecutor.ImageDigest()))
+
+	// Start background worker to reset stale jobs
+	go func() {
+		if err := ResetStaleJobs(ctx, jobQueueStore, logger); err != nil {
+			logger.Error("Error resetting stale jobs on startup", slog.String("error", err.Error()))
+		}
+
+		ticker := time.NewTicker(5 * time.Minute)
+		defer ticker.Stop()
+		for range ticker.C {
+			if err := ResetStaleJobs(ctx, jobQueueStore, logger); err != nil {
+				logger.Error("Error resetting stale jobs", slog.String("error", err.Error()))
+			}
+		}
+	}()
+
 	jobChan := make(chan *model.JobQueue, NUM_WORKERS*4)
 
 	// Start Job Workers
@@ -0,0 +1,137 @@
+package memcheck
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/dsa-uts/dsa-project/database/model"
+)
+
+// Error kinds of memory leaks start with this prefix, e.g., "Leak_DefinitelyLost".
+const LEAK_KIND_PREFIX = "Leak_"
+
+// Objects under these directories are Valgrind itself or system libraries,
+// so their frames are skipped when locating an error in user code.
+var systemObjPrefixes = []string{"/usr/", "/lib"}
+
+// error element of the Valgrind XML output (protocol version 4)
+type xmlError struct {
+	Kind   string     `xml:"kind"`
+	What   string     `xml:"what"`
+	XWhat  *xmlXWhat  `xml:"xwhat"`
+	Stacks []xmlStack `xml:"stack"`
+}
+
+type xmlXWhat struct {
+	Text        string `xml:"text"`
+	LeakedBytes int64  `xml:"leakedbytes"`
+}
+
+type xmlStack struct {
+	Frames []xmlFrame `xml:"frame"`
+}
+
+type xmlFrame struct {
+	Obj  string `xml:"obj"`
+	Fn   string `xml:"fn"`
+	File string `xml:"file"`
+	Line int64  `xml:"line"`
+}
+
+// Command returns the command line running the given command under Valgrind memcheck,
+// which writes its report in XML to the already open file descriptor xmlFD.
+// Valgrind moves the descriptor out of reach of the program, so the program cannot tamper with the report.
+// Only the first program of the command is checked, e.g., "./a.out < in.txt" or "./a.out arg".
+func Command(command string, xmlFD int) string {
+	return fmt.Sprintf("valgrind --tool=memcheck --leak-check=full --show-leak-kinds=definite,indirect,possible "+
+		"--xml=yes --xml-fd=%d --log-file=/dev/null %s", xmlFD, command)
+}
+
+// ParseFile reads the XML report of Valgrind memcheck, and returns the errors in it.
+func ParseFile(filePath string) ([]model.MemcheckFinding, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open memcheck report %s: %w", filePath, err)
+	}
+	defer file.Close()
+
+	return Parse(file)
+}
+
+// Parse reads the XML report of Valgrind memcheck, and returns the errors in it.
+// If the report is cut off (e.g., the program was killed), the errors before the cut
+// are returned together with an error.
+func Parse(r io.Reader) ([]model.MemcheckFinding, error) {
+	findings := []model.MemcheckFinding{}
+	decoder := xml.NewDecoder(r)
+
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			return findings, nil
+		}
+		if err != nil {
+			return findings, fmt.Errorf("failed to parse memcheck report: %w", err)
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "error" {
+			continue
+		}
+
+		var e xmlError
+		if err := decoder.DecodeElement(&e, &start); err != nil {
+			return findings, fmt.Errorf("failed to parse memcheck report: %w", err)
+		}
+		findings = append(findings, makeFinding(e))
+	}
+}
+
+func makeFinding(e xmlError) model.MemcheckFinding {
+	finding := model.MemcheckFinding{
+		Kind:    e.Kind,
+		Message: strings.TrimSpace(e.What),
+		Leak:    strings.HasPrefix(e.Kind, LEAK_KIND_PREFIX),
+	}
+
+	if e.XWhat != nil {
+		finding.Message = strings.TrimSpace(e.XWhat.Text)
+		finding.LeakedBytes = e.XWhat.LeakedBytes
+	}
+
+	// The first stack is where the error occurred, the others are auxiliary (e.g., where the block was freed)
+	if len(e.Stacks) > 0 {
+		finding.Location = location(e.Stacks[0].Frames)
+	}
+
+	return finding
+}
+
+// Returns the innermost frame in user code as "file:line (function)".
+// Falls back to the function name of the innermost frame if no frame has debug information.
+func location(frames []xmlFrame) string {
+	for _, frame := range frames {
+		if frame.File == "" || isSystemObj(frame.Obj) {
+			continue
+		}
+		return fmt.Sprintf("%s:%d (%s)", frame.File, frame.Line, frame.Fn)
+	}
+
+	if len(frames) > 0 {
+		return frames[0].Fn
+	}
+	return ""
+}
+
+func isSystemObj(obj string) bool {
+	for _, prefix := range systemObjPrefixes {
+		if strings.HasPrefix(obj, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/dsa-uts/dsa-project/database/model"
+	"github.com/dsa-uts/dsa-project/database/model/requeststatus"
+)
+
+//go:embed sources
+var hostileSources embed.FS
+
+// SelfTestCase is a hostile program that is submitted through the real
+// JobExecutor path to check that the sandbox keeps it under control.
+type SelfTestCase struct {
+	Title    string
+	Source   string // file name under sources/
+	Build    string // build command, empty if the source needs no compilation
+	Command  string // command to run the program
+	Stdout   string // expected stdout, not checked if empty
+	TimeMS   int64
+	MemoryMB int64
+	Expected []requeststatus.State // acceptable verdicts of the judge task
+}
+
+var selfTestCases = []SelfTestCase{
+	{
+		Title:    "fork bomb",
+		Source:   "fork_bomb.sh",
+		Command:  "bash fork_bomb.sh",
+		TimeMS:   2000,
+		MemoryMB: 256,
+		Expected: []requeststatus.State{requeststatus.TLE},
+	},
+	{
+		Title:    "stack overflow",
+		Source:   "use_many_stack.c",
+		Build:    "gcc -O0 -o use_many_stack use_many_stack.c",
+		Command:  "./use_many_stack",
+		TimeMS:   2000,
+		MemoryMB: 256,
+		Expected: []requeststatus.State{requeststatus.RE},
+	},
+	{
+		Title:    "memory hog",
+		Source:   "memory_hog.c",
+		Build:    "gcc -O0 -o memory_hog memory_hog.c",
+		Command:  "./memory_hog",
+		TimeMS:   2000,
+		MemoryMB: 64,
+		// killed by the watchdog (MLE) or by the OOM killer of the container (RE)
+		Expected: []requeststatus.State{requeststatus.MLE, requeststatus.RE},
+	},
+	{
+		Title:    "disk filler",
+		Source:   "disk_filler.c",
+		Build:    "gcc -O0 -o disk_filler disk_filler.c",
+		Command:  "./disk_filler",
+		TimeMS:   2000,
+		MemoryMB: 256,
+		Expected: []requeststatus.State{requeststatus.RE},
+	},
+	{
+		Title:    "network access",
+		Source:   "network_access.c",
+		Build:    "gcc -O0 -o network_access network_access.c",
+		Command:  "./network_access",
+		Stdout:   "BLOCKED\n",
+		TimeMS:   2000,
+		MemoryMB: 256,
+		Expected: []requeststatus.State{requeststatus.AC},
+	},
+	{
+		Title:    "reading other submissions",
+		Source:   "read_other_files.c",
+		Build:    "gcc -O0 -o read_other_files read_other_files.c",
+		Command:  "./read_other_files",
+		Stdout:   "BLOCKED\n",
+		TimeMS:   2000,
+		MemoryMB: 256,
+		Expected: []requeststatus.State{requeststatus.AC},
+	},
+	{
+		Title:    "huge stdout",
+		Source:   "huge_stdout.c",
+		Build:    "gcc -O0 -o huge_stdout huge_stdout.c",
+		Command:  "./huge_stdout",
+		TimeMS:   2000,
+		MemoryMB: 256,
+		Expected: []requeststatus.State{requeststatus.OLE},
+	},
+}
+
+// RunSelfTest submits every hostile program in selfTestCases to the executor
+// and checks that each of them gets one of the expected verdicts.
+// It returns an error describing all failed cases.
+func RunSelfTest(ctx context.Context, executor *JobExecutor, logger *slog.Logger) error {
+	failed := []string{}
+
+	for _, testCase := range selfTestCases {
+		verdict, err := runSelfTestCase(ctx, executor, testCase)
+		if err != nil {
+			logger.Error("Self-test case failed to run", slog.String("case", testCase.Title), slog.String("error", err.Error()))
+			failed = append(failed, fmt.Sprintf("%s: %s", testCase.Title, err.Error()))
+			continue
+		}
+
+		if !slices.Contains(testCase.Expected, verdict) {
+			logger.Error("Self-test case got unexpected verdict",
+				slog.String("case", testCase.Title),
+				slog.String("verdict", verdict.String()),
+				slog.Any("expected", testCase.Expected))
+			failed = append(failed, fmt.Sprintf("%s: got %s", testCase.Title, verdict.String()))
+			continue
+		}
+
+		logger.Info("Self-test case passed", slog.String("case", testCase.Title), slog.String("verdict", verdict.String()))
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("%d of %d self-test cases failed: %s", len(failed), len(selfTestCases), strings.Join(failed, "; "))
+	}
+	return nil
+}
+
+// runSelfTestCase builds and runs a single hostile program,
+// and returns the verdict of its judge task.
+func runSelfTestCase(ctx context.Context, executor *JobExecutor, testCase SelfTestCase) (requeststatus.State, error) {
+	workDir, err := os.MkdirTemp("", "selftest-")
+	if err != nil {
+		return requeststatus.IE, fmt.Errorf("failed to create work directory: %w", err)
+	}
+	defer os.RemoveAll(workDir)
+
+	fileDir := filepath.Join(workDir, "file")
+	resourceDir := filepath.Join(workDir, "resource")
+	resultDir := filepath.Join(workDir, "result")
+	for _, dir := range []string{fileDir, resourceDir, resultDir} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return requeststatus.IE, fmt.Errorf("failed to create directory %s: %w", dir, err)
+		}
+	}
+
+	// Place the hostile program as a user submitted file
+	source, err := hostileSources.ReadFile("sources/" + testCase.Source)
+	if err != nil {
+		return requeststatus.IE, fmt.Errorf("failed to read source %s: %w", testCase.Source, err)
+	}
+	if err := os.WriteFile(filepath.Join(fileDir, testCase.Source), source, 0644); err != nil {
+		return requeststatus.IE, fmt.Errorf("failed to write source %s: %w", testCase.Source, err)
+	}
+
+	buildTasks := []model.TestCase{}
+	if testCase.Build != "" {
+		buildTasks = append(buildTasks, model.MakeTestCase(0, "build", "", testCase.Build, false, "", "", "", 0, false))
+	}
+
+	stdoutPath := ""
+	if testCase.Stdout != "" {
+		stdoutPath = "expected_stdout.txt"
+		if err := os.WriteFile(filepath.Join(resourceDir, stdoutPath), []byte(testCase.Stdout), 0644); err != nil {
+			return requeststatus.IE, fmt.Errorf("failed to write expected stdout: %w", err)
+		}
+	}
+
+	job := &model.JobDetail{
+		TimeMS:      testCase.TimeMS,
+		MemoryMB:    testCase.MemoryMB,
+		TestFiles:   []string{},
+		ResourceDir: resourceDir,
+		FileDir:     fileDir,
+		ResultDir:   resultDir,
+		BuildTasks:  buildTasks,
+		JudgeTasks: []model.TestCase{
+			model.MakeTestCase(1, testCase.Title, "", testCase.Command, false, "", stdoutPath, "", 0, false),
+		},
+	}
+
+	requestLog, err := executor.ExecuteJob(ctx, job)
+	if err != nil {
+		return requeststatus.IE, err
+	}
+
+	for _, buildLog := range requestLog.BuildResults {
+		if buildLog.ResultID != requeststatus.AC {
+			return requeststatus.IE, fmt.Errorf("failed to build %s: %s", testCase.Source, buildLog.ResultID.String())
+		}
+	}
+
+	if len(requestLog.JudgeResults) != 1 {
+		return requeststatus.IE, fmt.Errorf("expected 1 judge result, got %d", len(requestLog.JudgeResults))
+	}
+
+	return requestLog.JudgeResults[0].ResultID, nil
+}
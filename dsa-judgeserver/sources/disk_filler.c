@@ -0,0 +1,15 @@
+#include <stdio.h>
+#include <string.h>
+
+// Write to a file until the disk (or the file size limit) is exhausted.
+int main() {
+    static char buf[1024 * 1024];
+    memset(buf, 'A', sizeof(buf));
+
+    FILE *fp = fopen("fill.dat", "w");
+    if (fp == NULL) return 1;
+    for (;;) {
+        if (fwrite(buf, 1, sizeof(buf), fp) != sizeof(buf)) return 1;
+        if (fflush(fp) != 0) return 1;
+    }
+}
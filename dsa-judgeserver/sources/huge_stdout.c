@@ -0,0 +1,8 @@
+#include <stdio.h>
+
+// Print to stdout forever.
+int main() {
+    for (;;) {
+        puts("AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA");
+    }
+}
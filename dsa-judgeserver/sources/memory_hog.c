@@ -0,0 +1,12 @@
+#include <stdlib.h>
+#include <string.h>
+
+// Keep allocating and touching memory so that it becomes resident.
+int main() {
+    const size_t chunk = 16 * 1024 * 1024;
+    for (;;) {
+        char *p = malloc(chunk);
+        if (p == NULL) return 1;
+        memset(p, 1, chunk);
+    }
+}
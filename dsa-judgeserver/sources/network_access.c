@@ -0,0 +1,42 @@
+#include <arpa/inet.h>
+#include <netdb.h>
+#include <netinet/in.h>
+#include <stdio.h>
+#include <string.h>
+#include <sys/socket.h>
+#include <unistd.h>
+
+static int try_connect(const char *ip, int port) {
+    int fd = socket(AF_INET, SOCK_STREAM, 0);
+    if (fd < 0) return 0;
+
+    struct sockaddr_in addr;
+    memset(&addr, 0, sizeof(addr));
+    addr.sin_family = AF_INET;
+    addr.sin_port = htons(port);
+    inet_pton(AF_INET, ip, &addr.sin_addr);
+
+    int ok = connect(fd, (struct sockaddr *)&addr, sizeof(addr)) == 0;
+    close(fd);
+    return ok;
+}
+
+// Prints "BLOCKED" only if the program cannot reach outside of the sandbox.
+int main() {
+    struct addrinfo *res = NULL;
+    if (getaddrinfo("example.com", "80", NULL, &res) == 0) {
+        freeaddrinfo(res);
+        puts("RESOLVED example.com");
+        return 0;
+    }
+    if (try_connect("1.1.1.1", 53)) {
+        puts("CONNECTED 1.1.1.1:53");
+        return 0;
+    }
+    if (try_connect("172.17.0.1", 2375)) {
+        puts("CONNECTED 172.17.0.1:2375");
+        return 0;
+    }
+    puts("BLOCKED");
+    return 0;
+}
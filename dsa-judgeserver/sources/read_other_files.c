@@ -0,0 +1,42 @@
+#include <dirent.h>
+#include <fcntl.h>
+#include <stdio.h>
+#include <unistd.h>
+
+// Paths that must not be readable from the sandbox: other submissions,
+// the docker socket, secrets of the judge, and the watchdog binary.
+static const char *paths[] = {
+    "/upload",
+    "/app",
+    "/app/upload",
+    "/var/run/docker.sock",
+    "/run/docker.sock",
+    "/run/secrets",
+    "/var/lib/docker",
+    "/home/watchdog",
+    "/proc/1/environ",
+    "/proc/1/root/home",
+    NULL,
+};
+
+// Prints "BLOCKED" only if none of the paths can be read.
+int main() {
+    int leaked = 0;
+    for (int i = 0; paths[i] != NULL; i++) {
+        DIR *dir = opendir(paths[i]);
+        if (dir != NULL) {
+            closedir(dir);
+            printf("READABLE %s\n", paths[i]);
+            leaked = 1;
+            continue;
+        }
+        int fd = open(paths[i], O_RDONLY);
+        if (fd >= 0) {
+            close(fd);
+            printf("READABLE %s\n", paths[i]);
+            leaked = 1;
+        }
+    }
+    if (!leaked) puts("BLOCKED");
+    return 0;
+}
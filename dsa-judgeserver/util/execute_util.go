@@ -2,15 +2,28 @@ package util
 
 import (
 	"archive/tar"
-	"bytes"
 	"fmt"
 	"io"
 	"os"
+	"path"
 	"path/filepath"
+	"strings"
 )
 
+// TarOptions sets the ownership and permissions recorded in the tar headers,
+// so that the files have them as soon as they are extracted in the container.
+// Copy the archive with CopyUIDGID enabled, otherwise the ownership is ignored.
+type TarOptions struct {
+	UID      int
+	GID      int
+	DirMode  int64 // permission of directories, 0 means 0755
+	FileMode int64 // permission of regular files, 0 keeps the permission in host
+}
+
 // Creates a tar archive from the given source path.
-func CreateTarArchive(srcPath string) (io.Reader, error) {
+// The archive is written by a goroutine while it is read, so that large directories are not
+// held in memory. Errors while writing are returned from Read, and the reader must be closed.
+func CreateTarArchive(srcPath string, opts TarOptions) (io.ReadCloser, error) {
 	// Clean the source path
 	srcPath = filepath.Clean(srcPath)
 
@@ -20,18 +33,14 @@ func CreateTarArchive(srcPath string) (io.Reader, error) {
 		return nil, fmt.Errorf("failed to get source path: %w", err)
 	}
 
-	var buf bytes.Buffer
-	tw := tar.NewWriter(&buf)
-	defer tw.Close()
-
-	// If it's a file, add just the file
-	if !info.IsDir() {
-		if err := addFileToTar(tw, srcPath, filepath.Base(srcPath)); err != nil {
-			return nil, err
+	return streamTarArchive(func(tw *tar.Writer) error {
+		// If it's a file, add just the file
+		if !info.IsDir() {
+			return addFileToTar(tw, srcPath, filepath.Base(srcPath), opts)
 		}
-	} else {
+
 		// If it's a directory, walk through all files
-		err = filepath.Walk(srcPath, func(path string, info os.FileInfo, err error) error {
+		err := filepath.Walk(srcPath, func(path string, info os.FileInfo, err error) error {
 			if err != nil {
 				return err
 			}
@@ -52,21 +61,68 @@ func CreateTarArchive(srcPath string) (io.Reader, error) {
 
 			// Add to tar archive
 			if info.IsDir() {
-				return addDirToTar(tw, tarPath)
+				return addDirToTar(tw, tarPath, opts)
 			}
-			return addFileToTar(tw, path, tarPath)
+			return addFileToTar(tw, path, tarPath, opts)
 		})
-
 		if err != nil {
-			return nil, fmt.Errorf("failed to walk directory: %w", err)
+			return fmt.Errorf("failed to walk directory: %w", err)
 		}
-	}
+		return nil
+	}), nil
+}
+
+// Creates a tar archive that contains the given files, keeping their paths relative to baseDir.
+// Parent directories of the files are also added to the archive.
+// The archive is streamed in the same way as CreateTarArchive.
+func CreateTarArchiveFromFiles(baseDir string, relPaths []string, opts TarOptions) io.ReadCloser {
+	return streamTarArchive(func(tw *tar.Writer) error {
+		addedDirs := map[string]bool{}
+		for _, relPath := range relPaths {
+			tarPath := filepath.ToSlash(filepath.Clean(relPath))
+
+			// Add parent directories first
+			dir := path.Dir(tarPath)
+			parents := []string{}
+			for dir != "." && dir != "/" && !addedDirs[dir] {
+				parents = append(parents, dir)
+				dir = path.Dir(dir)
+			}
+			for i := len(parents) - 1; i >= 0; i-- {
+				if err := addDirToTar(tw, parents[i], opts); err != nil {
+					return err
+				}
+				addedDirs[parents[i]] = true
+			}
 
-	return &buf, nil
+			if err := addFileToTar(tw, filepath.Join(baseDir, relPath), tarPath, opts); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Runs write in a new goroutine, and returns the reader of the tar archive it writes.
+// Closing the reader stops the goroutine at the next write.
+func streamTarArchive(write func(tw *tar.Writer) error) io.ReadCloser {
+	pr, pw := io.Pipe()
+
+	go func() {
+		tw := tar.NewWriter(pw)
+		err := write(tw)
+		if closeErr := tw.Close(); err == nil && closeErr != nil {
+			err = fmt.Errorf("failed to close tar archive: %w", closeErr)
+		}
+		// a nil error is reported as EOF to the reader
+		pw.CloseWithError(err)
+	}()
+
+	return pr
 }
 
 // Adds a file to the tar archive
-func addFileToTar(tw *tar.Writer, filePath, tarPath string) error {
+func addFileToTar(tw *tar.Writer, filePath, tarPath string, opts TarOptions) error {
 	file, err := os.Open(filePath)
 	if err != nil {
 		return fmt.Errorf("failed to open file %s: %w", filePath, err)
@@ -86,6 +142,15 @@ func addFileToTar(tw *tar.Writer, filePath, tarPath string) error {
 	// Set the name in the archive
 	header.Name = tarPath
 
+	// Set the ownership and permission in the container, instead of those in host
+	header.Uid = opts.UID
+	header.Gid = opts.GID
+	header.Uname = ""
+	header.Gname = ""
+	if opts.FileMode != 0 {
+		header.Mode = opts.FileMode
+	}
+
 	// Write header
 	if err := tw.WriteHeader(header); err != nil {
 		return fmt.Errorf("failed to write tar header for file %s: %w", filePath, err)
@@ -100,10 +165,17 @@ func addFileToTar(tw *tar.Writer, filePath, tarPath string) error {
 }
 
 // adds a directory to the tar archive
-func addDirToTar(tw *tar.Writer, dirPath string) error {
+func addDirToTar(tw *tar.Writer, dirPath string, opts TarOptions) error {
+	mode := opts.DirMode
+	if mode == 0 {
+		mode = 0755
+	}
+
 	header := &tar.Header{
 		Name:     dirPath + "/",
-		Mode:     0755,
+		Mode:     mode,
+		Uid:      opts.UID,
+		Gid:      opts.GID,
 		Typeflag: tar.TypeDir,
 	}
 
@@ -113,3 +185,46 @@ func addDirToTar(tw *tar.Writer, dirPath string) error {
 
 	return nil
 }
+
+// Extracts regular files and directories in the tar archive into dstDir.
+func ExtractTarArchive(reader io.Reader, dstDir string) error {
+	tr := tar.NewReader(reader)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read tar archive: %w", err)
+		}
+
+		// Reject entries escaping from dstDir
+		name := filepath.Clean(filepath.FromSlash(header.Name))
+		if name == ".." || strings.HasPrefix(name, ".."+string(filepath.Separator)) || filepath.IsAbs(name) {
+			return fmt.Errorf("invalid path in tar archive: %s", header.Name)
+		}
+		dstPath := filepath.Join(dstDir, name)
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(dstPath, 0755); err != nil {
+				return fmt.Errorf("failed to create directory %s: %w", dstPath, err)
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(dstPath), 0755); err != nil {
+				return fmt.Errorf("failed to create directory %s: %w", filepath.Dir(dstPath), err)
+			}
+			file, err := os.OpenFile(dstPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+			if err != nil {
+				return fmt.Errorf("failed to create file %s: %w", dstPath, err)
+			}
+			_, err = io.Copy(file, tr)
+			file.Close()
+			if err != nil {
+				return fmt.Errorf("failed to write file %s: %w", dstPath, err)
+			}
+		default:
+			// Skip symlinks and other special files
+		}
+	}
+}
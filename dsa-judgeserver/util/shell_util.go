@@ -0,0 +1,8 @@
+package util
+
+import "strings"
+
+// Quotes the given string so that sh treats it as a single word.
+func ShellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
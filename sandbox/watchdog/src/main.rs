@@ -1,5 +1,8 @@
 use std::{
+    collections::BTreeMap,
+    fs::File,
     io::{self, Read, Write},
+    os::fd::AsRawFd,
     os::unix::process::{CommandExt, ExitStatusExt},
     process::{Command, Stdio},
     sync::{Arc, Mutex},
@@ -8,6 +11,7 @@ use std::{
 };
 
 use nix::{
+    libc,
     sys::signal::{Signal, kill},
     unistd::{Pid, setpgid},
 };
@@ -16,22 +20,35 @@ use serde::{Deserialize, Serialize};
 #[derive(Debug, Deserialize)]
 struct TaskInput {
     command: String,
-    stdin: String,
+    #[serde(default)]
+    stdin_path: Option<String>,
+    stdout_path: String,
+    stderr_path: String,
     timeout_ms: u64,
+    #[serde(default)]
+    cpu_timeout_ms: Option<u64>,
     memory_limit_mb: u64,
     uid: u32,
     gid: u32,
     stdout_max_bytes: usize,
     stderr_max_bytes: usize,
+    #[serde(default)]
+    report_path: Option<String>,
+    #[serde(default)]
+    env: BTreeMap<String, String>,
 }
 
-#[derive(Debug, Serialize)]
+// File descriptor of the report file in the command, see `report_path`.
+const REPORT_FD: i32 = 3;
+
+#[derive(Debug, Serialize, Default)]
 struct TaskOutput {
     exit_code: Option<i32>,
-    stdout: String,
-    stderr: String,
+    error: String,
     time_ms: u64,
+    cpu_time_ms: u64,
     memory_kb: u64,
+    threads: Vec<ThreadUsage>,
     #[serde(rename = "TLE")]
     tle: bool,
     #[serde(rename = "MLE")]
@@ -40,6 +57,24 @@ struct TaskOutput {
     ole: bool,
 }
 
+// CPU time consumed by a thread of the task.
+#[derive(Debug, Serialize, Default, Clone)]
+struct ThreadUsage {
+    tid: u32,
+    name: String,
+    cpu_time_ms: u64,
+}
+
+impl TaskOutput {
+    fn from_error(message: String) -> TaskOutput {
+        TaskOutput {
+            exit_code: None,
+            error: message,
+            ..Default::default()
+        }
+    }
+}
+
 fn get_memory_usage_by_kb() -> io::Result<u64> {
     let path = "/sys/fs/cgroup/memory.current";
     let contents = std::fs::read_to_string(path)?;
@@ -50,6 +85,112 @@ fn get_memory_usage_by_kb() -> io::Result<u64> {
         .map_err(|e| io::Error::new(io::ErrorKind::InvalidData, e))
 }
 
+// Returns the fields of /proc/[pid]/stat after the command name, or None if the process has already gone.
+// The first one is the state (the 3rd field in proc(5)).
+fn get_process_stat(pid: u32) -> Option<Vec<u64>> {
+    let stat = std::fs::read_to_string(format!("/proc/{}/stat", pid)).ok()?;
+    // The command name may contain spaces and parentheses, so fields are counted from the last ')'
+    // The state is not a number, and is left as 0
+    Some(
+        stat[stat.rfind(')')? + 1..]
+            .split_whitespace()
+            .map(|field| field.parse::<u64>().unwrap_or(0))
+            .collect(),
+    )
+}
+
+// Returns the processes in the process group with their stat fields, see get_process_stat.
+fn get_process_group_members(pgid: u32) -> Vec<(u32, Vec<u64>)> {
+    let Ok(processes) = std::fs::read_dir("/proc") else {
+        return Vec::new();
+    };
+    processes
+        .flatten()
+        .filter_map(|process| {
+            let pid = process.file_name().to_str()?.parse::<u32>().ok()?;
+            let stat = get_process_stat(pid)?;
+            (*stat.get(2)? == pgid as u64).then_some((pid, stat))
+        })
+        .collect()
+}
+
+// CPU time consumed by the processes in the process group, summed over threads and cores.
+// Exited threads and children reaped by the processes are included, but processes that have
+// already been reaped by others are not, so the caller should keep the largest value seen.
+fn get_cpu_usage_by_usec(pgid: u32) -> u64 {
+    let ticks_per_sec = match unsafe { libc::sysconf(libc::_SC_CLK_TCK) } {
+        ticks if ticks > 0 => ticks as u64,
+        _ => 100,
+    };
+    get_process_group_members(pgid)
+        .iter()
+        // utime, stime, cutime and cstime (the 14th to 17th fields in proc(5)), in clock ticks
+        .map(|(_, stat)| {
+            stat.get(11..15)
+                .map_or(0, |times| times.iter().sum::<u64>())
+        })
+        .sum::<u64>()
+        * 1_000_000
+        / ticks_per_sec
+}
+
+// CPU time consumed by the reaped child of the watchdog and the descendants reaped by it.
+fn get_children_cpu_usage_by_usec() -> u64 {
+    let mut usage: libc::rusage = unsafe { std::mem::zeroed() };
+    if unsafe { libc::getrusage(libc::RUSAGE_CHILDREN, &mut usage) } != 0 {
+        return 0;
+    }
+    let usec = |time: libc::timeval| time.tv_sec as u64 * 1_000_000 + time.tv_usec as u64;
+    usec(usage.ru_utime) + usec(usage.ru_stime)
+}
+
+// Records the CPU time of every thread in the process group, keeping the largest value seen.
+// Threads are sampled periodically, so those living shorter than the interval may be missed.
+fn sample_thread_usage(pgid: u32, threads: &mut BTreeMap<u32, ThreadUsage>) {
+    for (pid, _) in get_process_group_members(pgid) {
+        let Ok(tasks) = std::fs::read_dir(format!("/proc/{}/task", pid)) else {
+            continue;
+        };
+        for task in tasks.flatten() {
+            let Some(tid) = task
+                .file_name()
+                .to_str()
+                .and_then(|s| s.parse::<u32>().ok())
+            else {
+                continue;
+            };
+            // The first field of schedstat is the time spent on the CPU in nanoseconds
+            let Some(cpu_time_ns) =
+                std::fs::read_to_string(format!("/proc/{}/task/{}/schedstat", pid, tid))
+                    .ok()
+                    .and_then(|s| s.split_whitespace().next()?.parse::<u64>().ok())
+            else {
+                continue;
+            };
+            let name = std::fs::read_to_string(format!("/proc/{}/task/{}/comm", pid, tid))
+                .map(|s| s.trim().to_string())
+                .unwrap_or_default();
+
+            let usage = threads.entry(tid).or_insert_with(|| ThreadUsage {
+                tid,
+                ..Default::default()
+            });
+            // The name changes when the thread execs another program, e.g., from the shell
+            usage.name = name;
+            usage.cpu_time_ms = usage.cpu_time_ms.max(cpu_time_ns / 1_000_000);
+        }
+    }
+}
+
+// Returns the threads that actually used the CPU, dropping idle ones such as the shells.
+fn busy_threads(threads: &BTreeMap<u32, ThreadUsage>) -> Vec<ThreadUsage> {
+    threads
+        .values()
+        .filter(|usage| usage.cpu_time_ms > 0)
+        .cloned()
+        .collect()
+}
+
 // Alternative method using /proc/[pid]/status
 // Uncomment if needed
 // fn get_memory_usage(pid: u32) -> io::Result<u64> {
@@ -71,32 +212,39 @@ fn get_memory_usage_by_kb() -> io::Result<u64> {
 
 fn monitor_output<R: Read + Send + 'static>(
     mut reader: R,
-    buffer: Arc<Mutex<Vec<u8>>>,
+    mut file: File,
     max_bytes: usize,
     ole_flag: Arc<Mutex<bool>>,
 ) -> thread::JoinHandle<()> {
     thread::spawn(move || {
-        let mut local_buffer = vec![0u8; 1024];
+        let mut local_buffer = vec![0u8; 64 * 1024];
+        let mut written = 0usize;
         loop {
             match reader.read(&mut local_buffer) {
                 Ok(0) => break, // EOF
                 Ok(n) => {
-                    let mut buf = buffer.lock().unwrap();
-                    let new_size = buf.len() + n;
+                    let new_size = written + n;
 
-                    if new_size > max_bytes {
+                    let to_copy = if new_size > max_bytes {
                         *ole_flag.lock().unwrap() = true;
-                        let allowed = max_bytes.saturating_sub(buf.len());
-                        let to_copy = n.min(allowed);
-                        buf.extend_from_slice(&local_buffer[..to_copy]);
+                        max_bytes.saturating_sub(written).min(n)
                         // Don't break here - continue reading to prevent blocking
                     } else {
-                        buf.extend_from_slice(&local_buffer[..n]);
+                        n
+                    };
+
+                    if to_copy > 0 {
+                        if file.write_all(&local_buffer[..to_copy]).is_err() {
+                            // Cannot keep the output anymore, treat it as exceeding the limit
+                            *ole_flag.lock().unwrap() = true;
+                        }
+                        written += to_copy;
                     }
                 }
                 Err(_) => break,
             }
         }
+        let _ = file.flush();
     })
 }
 
@@ -124,18 +272,54 @@ fn execute_task(task: TaskInput) -> TaskOutput {
     // Parse command and arguments
     let parts: Vec<&str> = task.command.split_whitespace().collect();
     if parts.is_empty() {
-        return TaskOutput {
-            exit_code: None,
-            stdout: String::new(),
-            stderr: "Invalid command".to_string(),
-            time_ms: 0,
-            memory_kb: 0,
-            tle: false,
-            mle: false,
-            ole: false,
-        };
+        return TaskOutput::from_error("Invalid command".to_string());
     }
 
+    // Open stdin file, and create stdout/stderr files before spawning the child,
+    // so that the child (running as uid/gid) does not need to access them by path.
+    let stdin = match &task.stdin_path {
+        Some(path) => match File::open(path) {
+            Ok(file) => Stdio::from(file),
+            Err(e) => {
+                return TaskOutput::from_error(format!("Failed to open stdin file {}: {}", path, e));
+            }
+        },
+        None => Stdio::null(),
+    };
+    let stdout_file = match File::create(&task.stdout_path) {
+        Ok(file) => file,
+        Err(e) => {
+            return TaskOutput::from_error(format!(
+                "Failed to create stdout file {}: {}",
+                task.stdout_path, e
+            ));
+        }
+    };
+    let stderr_file = match File::create(&task.stderr_path) {
+        Ok(file) => file,
+        Err(e) => {
+            return TaskOutput::from_error(format!(
+                "Failed to create stderr file {}: {}",
+                task.stderr_path, e
+            ));
+        }
+    };
+
+    // The report file is passed to the command as REPORT_FD, so that the command cannot reach it by path
+    let report_file = match &task.report_path {
+        Some(path) => match File::create(path) {
+            Ok(file) => Some(file),
+            Err(e) => {
+                return TaskOutput::from_error(format!(
+                    "Failed to create report file {}: {}",
+                    path, e
+                ));
+            }
+        },
+        None => None,
+    };
+    let report_fd = report_file.as_ref().map(|file| file.as_raw_fd());
+
     let final_command = format!(
         "stdbuf -oL -eL sh -c '{}'",
         task.command.replace("'", "'\\''")
@@ -143,119 +327,110 @@ fn execute_task(task: TaskInput) -> TaskOutput {
 
     // Spawn child process with specified uid/gid
     // Use process_group(0) to create a new process group with the child as leader
+    // The child gets only the given environment, not the one of the watchdog
     let mut child = match unsafe {
         Command::new("/bin/sh")
             .arg("-c")
             .arg(&final_command)
-            .stdin(Stdio::piped())
+            .env_clear()
+            .envs(&task.env)
+            .stdin(stdin)
             .stdout(Stdio::piped())
             .stderr(Stdio::piped())
             .uid(task.uid)
             .gid(task.gid)
-            .pre_exec(|| {
+            .pre_exec(move || {
                 // Create a new process group with this process as the leader
                 // This ensures all child processes are in the same group
                 setpgid(Pid::from_raw(0), Pid::from_raw(0))?;
+
+                // dup2 clears close-on-exec of the new descriptor, but does nothing if both are the same
+                if let Some(fd) = report_fd {
+                    let result = if fd == REPORT_FD {
+                        libc::fcntl(fd, libc::F_SETFD, 0)
+                    } else {
+                        libc::dup2(fd, REPORT_FD)
+                    };
+                    if result == -1 {
+                        return Err(io::Error::last_os_error());
+                    }
+                }
                 Ok(())
             })
             .spawn()
     } {
         Ok(child) => child,
         Err(e) => {
-            return TaskOutput {
-                exit_code: None,
-                stdout: String::new(),
-                stderr: format!("Failed to spawn process: {}", e),
-                time_ms: 0,
-                memory_kb: 0,
-                tle: false,
-                mle: false,
-                ole: false,
-            };
+            return TaskOutput::from_error(format!("Failed to spawn process: {}", e));
         }
     };
 
     let pid = child.id();
+    // The child has its own descriptor of the report file
+    drop(report_file);
 
-    // Write stdin
-    if let Some(mut stdin) = child.stdin.take() {
-        let _ = stdin.write_all(task.stdin.as_bytes());
-        let _ = stdin.flush();
-        // Close stdin to signal EOF
-        drop(stdin);
-    }
+    // CPU time is measured for the process group of the child, which is the leader of it,
+    // so that other processes in the container (e.g., other tasks) are not counted
+    let cpu_time_ms = || get_cpu_usage_by_usec(pid) / 1000;
 
-    // Set up output monitoring
-    let stdout_buffer = Arc::new(Mutex::new(Vec::new()));
-    let stderr_buffer = Arc::new(Mutex::new(Vec::new()));
+    // Set up output monitoring, outputs are written to the files directly
     let ole_flag = ole.clone();
 
     let stdout_handle = match child.stdout.take() {
         Some(stdout) => monitor_output(
             stdout,
-            stdout_buffer.clone(),
+            stdout_file,
             task.stdout_max_bytes,
             ole_flag.clone(),
         ),
         None => {
-            return TaskOutput {
-                exit_code: None,
-                stdout: String::new(),
-                stderr: "Failed to capture stdout".to_string(),
-                time_ms: 0,
-                memory_kb: 0,
-                tle: false,
-                mle: false,
-                ole: false,
-            };
+            return TaskOutput::from_error("Failed to capture stdout".to_string());
         }
     };
 
     let stderr_handle = match child.stderr.take() {
         Some(stderr) => monitor_output(
             stderr,
-            stderr_buffer.clone(),
+            stderr_file,
             task.stderr_max_bytes,
             ole_flag.clone(),
         ),
         None => {
-            return TaskOutput {
-                exit_code: None,
-                stdout: String::new(),
-                stderr: "Failed to capture stderr".to_string(),
-                time_ms: 0,
-                memory_kb: 0,
-                tle: false,
-                mle: false,
-                ole: false,
-            };
+            return TaskOutput::from_error("Failed to capture stderr".to_string());
         }
     };
 
     // Monitor process
     let timeout = Duration::from_millis(task.timeout_ms);
+    let cpu_timeout_ms = task.cpu_timeout_ms.filter(|&ms| ms > 0);
     let memory_limit_kb = task.memory_limit_mb * 1024;
     let check_interval = Duration::from_millis(10);
     let mut max_memory_kb = 0u64;
+    let mut max_cpu_time_ms = 0u64;
+    let mut threads = BTreeMap::new();
 
     let monitoring_start = Instant::now();
     let mut process_killed = false;
     let mut kill_reason = None;
 
     loop {
+        // Sample threads before reaping the child, so that the last values of its threads are kept
+        if !process_killed {
+            sample_thread_usage(pid, &mut threads);
+        }
+
         // Check if process has exited
         match child.try_wait() {
             Ok(Some(status)) => {
                 // Process has exited
                 let elapsed = start_time.elapsed();
+                // The child has been reaped, so its CPU time is now in the usage of the children
+                max_cpu_time_ms = max_cpu_time_ms.max(get_children_cpu_usage_by_usec() / 1000);
 
                 // Wait for output threads to finish
                 let _ = stdout_handle.join();
                 let _ = stderr_handle.join();
 
-                let stdout = String::from_utf8_lossy(&stdout_buffer.lock().unwrap()).to_string();
-                let stderr = String::from_utf8_lossy(&stderr_buffer.lock().unwrap()).to_string();
-
                 // Determine exit code
                 let exit_code = if let Some(code) = status.code() {
                     // Normal exit
@@ -289,10 +464,11 @@ fn execute_task(task: TaskInput) -> TaskOutput {
 
                 return TaskOutput {
                     exit_code,
-                    stdout,
-                    stderr,
+                    error: String::new(),
                     time_ms: elapsed.as_millis() as u64,
+                    cpu_time_ms: max_cpu_time_ms,
                     memory_kb: max_memory_kb,
+                    threads: busy_threads(&threads),
                     tle,
                     mle,
                     ole: *ole.lock().unwrap(),
@@ -304,10 +480,11 @@ fn execute_task(task: TaskInput) -> TaskOutput {
             Err(e) => {
                 return TaskOutput {
                     exit_code: None,
-                    stdout: String::new(),
-                    stderr: format!("Error waiting for process: {}", e),
+                    error: format!("Error waiting for process: {}", e),
                     time_ms: start_time.elapsed().as_millis() as u64,
+                    cpu_time_ms: max_cpu_time_ms,
                     memory_kb: max_memory_kb,
+                    threads: busy_threads(&threads),
                     tle,
                     mle,
                     ole: *ole.lock().unwrap(),
@@ -317,7 +494,7 @@ fn execute_task(task: TaskInput) -> TaskOutput {
 
         // Only check limits if we haven't already killed the process
         if !process_killed {
-            // Check timeout
+            // Check timeout of wall-clock time
             if monitoring_start.elapsed() > timeout {
                 tle = true;
                 kill_reason = Some("TLE");
@@ -326,6 +503,18 @@ fn execute_task(task: TaskInput) -> TaskOutput {
                 continue;
             }
 
+            // Check timeout of CPU time, which grows faster than wall-clock time with multiple threads
+            max_cpu_time_ms = max_cpu_time_ms.max(cpu_time_ms());
+            if let Some(limit) = cpu_timeout_ms {
+                if max_cpu_time_ms > limit {
+                    tle = true;
+                    kill_reason = Some("TLE");
+                    kill_process_group(pid);
+                    process_killed = true;
+                    continue;
+                }
+            }
+
             // Check memory usage
             if let Ok(memory_kb) = get_memory_usage_by_kb() {
                 max_memory_kb = max_memory_kb.max(memory_kb);
@@ -351,7 +540,7 @@ fn execute_task(task: TaskInput) -> TaskOutput {
     }
 }
 
-/// Executes a task with resource limits and captures output.
+/// Executes a task with resource limits, and writes its outputs to files.
 /// Returns JSON log with execution details.
 ///
 /// Input are given on stdin as JSON, output is printed to stdout as JSON.
@@ -359,28 +548,49 @@ fn execute_task(task: TaskInput) -> TaskOutput {
 /// ```json
 /// {
 ///    "command": "cmd [args...]",
-///    "stdin": "stdin data",
+///    "stdin_path": "/judge/stdin/input.txt",   // optional, stdin is empty if omitted
+///    "stdout_path": "/judge/output/stdout.txt",
+///    "stderr_path": "/judge/output/stderr.txt",
 ///    "timeout_ms": 3000,
+///    "cpu_timeout_ms": 1000,                  // optional, CPU time is not limited if omitted
 ///    "memory_limit_mb": 1024,
 ///    "uid": 1000,
 ///    "gid": 1000,
 ///    "stdout_max_bytes": 1024,
 ///    "stderr_max_bytes": 1024,
+///    "report_path": "/judge/output/report.xml",  // optional
+///    "env": { "PATH": "/usr/bin:/bin" },         // optional
 /// }
 /// ```
 ///
 /// The command is executed as `/bin/sh -c "command"`, so shell features like
 ///  pipe forwarding are available.
+/// The files are opened by the watchdog itself, so the command does not need
+/// permissions to access them. At most `stdout_max_bytes` (`stderr_max_bytes`)
+/// bytes are written to the stdout (stderr) file.
+/// If `report_path` is given, the file is created by the watchdog and is open as
+/// file descriptor 3 in the command, e.g., for `valgrind --xml-fd=3`.
+/// The command runs only with the variables in `env`, and does not inherit the
+/// environment of the watchdog, so that they never affect the watchdog running as root.
+/// `timeout_ms` limits the wall-clock time, and `cpu_timeout_ms` limits the CPU time
+/// summed over all threads and child processes. Exceeding either of them results in TLE.
+/// Memory is measured for the whole container (cgroup), and CPU time for the process group
+/// of the command, sampled periodically, so processes leaving the group are not counted.
+/// `threads` lists the threads in the process group of the command that used the CPU,
+/// sampled periodically, so very short-lived threads may be missing.
 ///
 /// Output JSON format:
 ///
 /// ```json
 /// {
 ///    "exit_code": 0,   // None if error occurs on setup/monitoring
-///    "stdout": "",
-///    "stderr": "",     // Contains error message if exit_code is None
-///    "time_ms": 123,
+///    "error": "",      // Contains error message if exit_code is None
+///    "time_ms": 123,      // wall-clock time
+///    "cpu_time_ms": 100,  // CPU time
 ///    "memory_kb": 456,
+///    "threads": [      // CPU time of each thread of the command
+///       { "tid": 42, "name": "a.out", "cpu_time_ms": 50 },
+///    ],
 ///    "TLE": false,     // Time Limit Exceeded If true
 ///    "MLE": false,     // Memory Limit Exceeded If true
 ///    "OLE": false,     // Output Limit Exceeded If true
@@ -388,19 +598,24 @@ fn execute_task(task: TaskInput) -> TaskOutput {
 /// ```
 ///
 /// If there are some errors when setting up or monitoring the process,
-/// exit_code will be None and stderr will contain the error message.
+/// exit_code will be None and error will contain the error message.
 fn main() {
     // Read JSON from stdin
     // Expected JSON format:
     //    {
     //       "command": "cmd [args...]",
-    //       "stdin": "stdin data",
+    //       "stdin_path": "/judge/stdin/input.txt",
+    //       "stdout_path": "/judge/output/stdout.txt",
+    //       "stderr_path": "/judge/output/stderr.txt",
     //       "timeout_ms": 3000,
+    //       "cpu_timeout_ms": 1000,
     //       "memory_limit_mb": 1024,
     //       "uid": 1000,
     //       "gid": 1000,
     //       "stdout_max_bytes": 1024,
     //       "stderr_max_bytes": 1024,
+    //       "report_path": "/judge/output/report.xml",
+    //       "env": { "PATH": "/usr/bin:/bin" },
     //    }
     let mut input = String::new();
     if let Err(e) = io::stdin().read_to_string(&mut input) {